@@ -11,9 +11,12 @@ type Authentication struct {
 }
 
 type (
-	AppPortalAccessIn  openapi.AppPortalAccessIn
-	AppPortalAccessOut openapi.AppPortalAccessOut
-	DashboardAccessOut openapi.DashboardAccessOut
+	AppPortalAccessIn        openapi.AppPortalAccessIn
+	AppPortalAccessOut       openapi.AppPortalAccessOut
+	DashboardAccessOut       openapi.DashboardAccessOut
+	OneTimeTokenIn           openapi.OneTimeTokenIn
+	OneTimeTokenOut          openapi.OneTimeTokenOut
+	ApplicationTokenExpireIn openapi.ApplicationTokenExpireIn
 )
 
 func (a *Authentication) AppPortalAccess(ctx context.Context, appId string, appPortalAccessIn *AppPortalAccessIn) (*AppPortalAccessOut, error) {
@@ -55,6 +58,42 @@ func (a *Authentication) DashboardAccessWithOptions(ctx context.Context, appId s
 	return &ret, nil
 }
 
+func (a *Authentication) ExchangeOneTimeToken(ctx context.Context, oneTimeTokenIn *OneTimeTokenIn) (*OneTimeTokenOut, error) {
+	return a.ExchangeOneTimeTokenWithOptions(ctx, oneTimeTokenIn, nil)
+}
+
+func (a *Authentication) ExchangeOneTimeTokenWithOptions(ctx context.Context, oneTimeTokenIn *OneTimeTokenIn, options *PostOptions) (*OneTimeTokenOut, error) {
+	req := a.api.AuthenticationApi.V1AuthenticationExchangeOneTimeToken(ctx)
+	req = req.OneTimeTokenIn(openapi.OneTimeTokenIn(*oneTimeTokenIn))
+	if options != nil {
+		if options.IdempotencyKey != nil {
+			req = req.IdempotencyKey(*options.IdempotencyKey)
+		}
+	}
+	out, res, err := req.Execute()
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	ret := OneTimeTokenOut(out)
+	return &ret, nil
+}
+
+func (a *Authentication) ExpireAll(ctx context.Context, appId string, applicationTokenExpireIn *ApplicationTokenExpireIn) error {
+	return a.ExpireAllWithOptions(ctx, appId, applicationTokenExpireIn, nil)
+}
+
+func (a *Authentication) ExpireAllWithOptions(ctx context.Context, appId string, applicationTokenExpireIn *ApplicationTokenExpireIn, options *PostOptions) error {
+	req := a.api.AuthenticationApi.V1AuthenticationExpireAll(ctx, appId)
+	req = req.ApplicationTokenExpireIn(openapi.ApplicationTokenExpireIn(*applicationTokenExpireIn))
+	if options != nil {
+		if options.IdempotencyKey != nil {
+			req = req.IdempotencyKey(*options.IdempotencyKey)
+		}
+	}
+	res, err := req.Execute()
+	return wrapError(err, res)
+}
+
 func (a *Authentication) Logout(ctx context.Context) error {
 	return a.LogoutWithOptions(ctx, nil)
 }
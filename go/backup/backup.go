@@ -0,0 +1,314 @@
+// Package backup takes snapshots of an environment's configuration and
+// restores them, possibly into another environment.
+//
+// An Archive holds the event types, and the applications with their
+// endpoints (including secrets, headers and transformations) and
+// integrations. Restore recreates them, keeping their uids and mapping the
+// ids of the archive to the newly generated ones.
+//
+// Both operations can be resumed: Backup skips the applications of a
+// partial archive, and Restore the objects recorded in a RestoreState. The
+// Checkpoint options are called as the work progresses so that callers can
+// persist them.
+//
+// Some data can't be read back from the API, and is therefore missing from
+// archives: the values of sensitive endpoint headers (such as
+// Authorization). Integration keys are backed up, but can't be set when
+// restoring, so restored integrations have new keys.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/internal/ptr"
+)
+
+// FormatVersion is the version of the archive format written by this
+// package.
+const FormatVersion = 1
+
+// Archive is a snapshot of an environment. It contains secrets, so it should
+// be stored accordingly.
+type Archive struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Whether all applications were backed up. Only complete archives can
+	// be restored.
+	Complete     bool           `json:"complete"`
+	EventTypes   []*EventType   `json:"eventTypes"`
+	Applications []*Application `json:"applications"`
+}
+
+type EventType struct {
+	Name        string                            `json:"name"`
+	Description string                            `json:"description"`
+	FeatureFlag string                            `json:"featureFlag,omitempty"`
+	Archived    bool                              `json:"archived,omitempty"`
+	Schemas     map[string]map[string]interface{} `json:"schemas,omitempty"`
+}
+
+type Application struct {
+	Id           string            `json:"id"`
+	Uid          string            `json:"uid,omitempty"`
+	Name         string            `json:"name"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	RateLimit    *int32            `json:"rateLimit,omitempty"`
+	Endpoints    []*Endpoint       `json:"endpoints"`
+	Integrations []*Integration    `json:"integrations"`
+}
+
+type Endpoint struct {
+	Id          string            `json:"id"`
+	Uid         string            `json:"uid,omitempty"`
+	Url         string            `json:"url"`
+	Description string            `json:"description,omitempty"`
+	Disabled    bool              `json:"disabled,omitempty"`
+	FilterTypes []string          `json:"filterTypes,omitempty"`
+	Channels    []string          `json:"channels,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	RateLimit   *int32            `json:"rateLimit,omitempty"`
+	Version     int32             `json:"version,omitempty"`
+	Secret      string            `json:"secret"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	// The names of the sensitive headers, whose values can't be backed up.
+	SensitiveHeaders []string        `json:"sensitiveHeaders,omitempty"`
+	Transformation   *Transformation `json:"transformation,omitempty"`
+}
+
+type Transformation struct {
+	Code    string `json:"code,omitempty"`
+	Enabled bool   `json:"enabled,omitempty"`
+}
+
+type Integration struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+type Kind string
+
+const (
+	KindEventType   Kind = "event-type"
+	KindApplication Kind = "application"
+	KindEndpoint    Kind = "endpoint"
+	KindIntegration Kind = "integration"
+)
+
+// Progress reports an object that was backed up or restored.
+type Progress struct {
+	Kind Kind
+	// The id of the object in the archive, or the name of an event type.
+	Id  string
+	Uid string
+	// The id of the restored object.
+	NewId string
+	// Set when the object was skipped because a previous run handled it.
+	Resumed bool
+	// What couldn't be backed up or restored.
+	Warnings []string
+}
+
+// Read parses an archive, checking that its format is supported.
+func Read(r io.Reader) (*Archive, error) {
+	var archive Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	if archive.Version < 1 || archive.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+	return &archive, nil
+}
+
+// Write writes the archive as JSON.
+func (a *Archive) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+type BackupOptions struct {
+	// A partial archive from an interrupted backup. Its applications are
+	// kept as they are instead of being fetched again.
+	Resume *Archive
+	// Called after each object is backed up.
+	OnProgress func(Progress)
+	// Called with the partial archive after each application.
+	Checkpoint func(*Archive) error
+}
+
+// Backup takes a snapshot of the environment of client.
+func Backup(ctx context.Context, client *svix.Svix, options *BackupOptions) (*Archive, error) {
+	var opts BackupOptions
+	if options != nil {
+		opts = *options
+	}
+	progress := func(p Progress) {
+		if opts.OnProgress != nil {
+			opts.OnProgress(p)
+		}
+	}
+	archive := &Archive{Version: FormatVersion, CreatedAt: time.Now().UTC()}
+	done := map[string]*Application{}
+	if opts.Resume != nil {
+		archive.CreatedAt = opts.Resume.CreatedAt
+		for _, app := range opts.Resume.Applications {
+			done[app.Id] = app
+		}
+	}
+
+	yes := true
+	eventTypes, err := paginate.All(func(iterator *string) ([]openapi.EventTypeOut, *string, bool, error) {
+		out, err := client.EventType.List(ctx, &svix.EventTypeListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize), WithContent: &yes, IncludeArchived: &yes})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return out.Data, out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing event types: %w", err)
+	}
+	for _, et := range eventTypes {
+		archive.EventTypes = append(archive.EventTypes, &EventType{
+			Name:        et.Name,
+			Description: et.Description,
+			FeatureFlag: ptr.Value(et.FeatureFlag.Get()),
+			Archived:    et.Archived != nil && *et.Archived,
+			Schemas:     et.Schemas,
+		})
+		progress(Progress{Kind: KindEventType, Id: et.Name})
+	}
+
+	apps, err := paginate.All(func(iterator *string) ([]openapi.ApplicationOut, *string, bool, error) {
+		out, err := client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return out.Data, out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing applications: %w", err)
+	}
+	for _, app := range apps {
+		if prev, ok := done[app.Id]; ok {
+			archive.Applications = append(archive.Applications, prev)
+			progress(Progress{Kind: KindApplication, Id: app.Id, Uid: prev.Uid, Resumed: true})
+			continue
+		}
+		backup, err := backupApplication(ctx, client, app, progress)
+		if err != nil {
+			return nil, fmt.Errorf("application %s: %w", app.Id, err)
+		}
+		archive.Applications = append(archive.Applications, backup)
+		progress(Progress{Kind: KindApplication, Id: app.Id, Uid: backup.Uid})
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint(archive); err != nil {
+				return nil, err
+			}
+		}
+	}
+	archive.Complete = true
+	return archive, nil
+}
+
+func backupApplication(ctx context.Context, client *svix.Svix, app openapi.ApplicationOut, progress func(Progress)) (*Application, error) {
+	backup := &Application{
+		Id:        app.Id,
+		Uid:       ptr.Value(app.Uid.Get()),
+		Name:      app.Name,
+		Metadata:  app.Metadata,
+		RateLimit: app.RateLimit.Get(),
+	}
+	endpoints, err := paginate.All(func(iterator *string) ([]openapi.EndpointOut, *string, bool, error) {
+		out, err := client.Endpoint.List(ctx, app.Id, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return out.Data, out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range endpoints {
+		endpoint, err := backupEndpoint(ctx, client, app.Id, ep)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", ep.Id, err)
+		}
+		backup.Endpoints = append(backup.Endpoints, endpoint)
+		var warnings []string
+		if len(endpoint.SensitiveHeaders) != 0 {
+			warnings = append(warnings, fmt.Sprintf("the values of the sensitive headers %v can't be backed up", endpoint.SensitiveHeaders))
+		}
+		progress(Progress{Kind: KindEndpoint, Id: ep.Id, Uid: endpoint.Uid, Warnings: warnings})
+	}
+
+	integrations, err := paginate.All(func(iterator *string) ([]openapi.IntegrationOut, *string, bool, error) {
+		out, err := client.Integration.List(ctx, app.Id, &svix.IntegrationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return out.Data, out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, integ := range integrations {
+		key, err := client.Integration.GetKey(ctx, app.Id, integ.Id)
+		if err != nil {
+			return nil, fmt.Errorf("integration %s: %w", integ.Id, err)
+		}
+		backup.Integrations = append(backup.Integrations, &Integration{Id: integ.Id, Name: integ.Name, Key: key.Key})
+		progress(Progress{Kind: KindIntegration, Id: integ.Id})
+	}
+	return backup, nil
+}
+
+func backupEndpoint(ctx context.Context, client *svix.Svix, appId string, ep openapi.EndpointOut) (*Endpoint, error) {
+	backup := &Endpoint{
+		Id:          ep.Id,
+		Uid:         ptr.Value(ep.Uid.Get()),
+		Url:         ep.Url,
+		Description: ep.Description,
+		Disabled:    ep.Disabled != nil && *ep.Disabled,
+		FilterTypes: ep.FilterTypes,
+		Channels:    ep.Channels,
+		Metadata:    ep.Metadata,
+		RateLimit:   ep.RateLimit.Get(),
+		Version:     ep.Version,
+	}
+	secret, err := client.Endpoint.GetSecret(ctx, appId, ep.Id)
+	if err != nil {
+		return nil, err
+	}
+	backup.Secret = secret.Key
+	headers, err := client.Endpoint.GetHeaders(ctx, appId, ep.Id)
+	if err != nil {
+		return nil, err
+	}
+	backup.Headers = headers.Headers
+	backup.SensitiveHeaders = headers.Sensitive
+	transformation, err := client.Endpoint.TransformationGet(ctx, appId, ep.Id)
+	var svixErr *svix.Error
+	switch {
+	case errors.As(err, &svixErr) && svixErr.Status() == http.StatusNotFound:
+		// Transformations aren't enabled for the environment.
+	case err != nil:
+		return nil, err
+	case transformation.Code.Get() != nil || (transformation.Enabled != nil && *transformation.Enabled):
+		backup.Transformation = &Transformation{
+			Code:    ptr.Value(transformation.Code.Get()),
+			Enabled: transformation.Enabled != nil && *transformation.Enabled,
+		}
+	}
+	return backup, nil
+}
@@ -0,0 +1,229 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/backup"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+const secret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+func populate(t *testing.T, client *svix.Svix) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := client.EventType.Create(ctx, &svix.EventTypeIn{
+		Name:        "invoice.paid",
+		Description: "An invoice was paid",
+		Schemas:     map[string]map[string]interface{}{"1": {"type": "object"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uid := range []string{"acme", ""} {
+		in := &svix.ApplicationIn{Name: "App " + uid}
+		if uid != "" {
+			in.Uid = *svix.NullableString(svix.String(uid))
+		}
+		app, err := client.Application.Create(ctx, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{
+			Url:         "https://example.com/" + uid,
+			Uid:         *svix.NullableString(svix.String("main")),
+			FilterTypes: []string{"invoice.paid"},
+			Secret:      *svix.NullableString(svix.String(secret)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Endpoint.UpdateHeaders(ctx, app.Id, ep.Id, &svix.EndpointHeadersIn{
+			Headers: map[string]string{"X-Tenant": uid, "Authorization": "Bearer secret"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Endpoint.TransformatioPartialUpdate(ctx, app.Id, ep.Id, &svix.EndpointTransformationIn{
+			Code:    *svix.NullableString(svix.String("function handler(webhook) { return webhook }")),
+			Enabled: &[]bool{true}[0],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Integration.Create(ctx, app.Id, &svix.IntegrationIn{Name: "zapier"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	source := svixtest.NewServer(nil)
+	defer source.Close()
+	target := svixtest.NewServer(nil)
+	defer target.Close()
+	ctx := context.Background()
+	populate(t, source.Client())
+
+	var warnings []string
+	archive, err := backup.Backup(ctx, source.Client(), &backup.BackupOptions{
+		OnProgress: func(p backup.Progress) { warnings = append(warnings, p.Warnings...) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !archive.Complete || len(archive.EventTypes) != 1 || len(archive.Applications) != 2 {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("expected a warning about the Authorization header of each endpoint, got %q", warnings)
+	}
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	archive, err = backup.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupt the restore after a few objects, then resume it from the
+	// last saved state.
+	errInterrupted := errors.New("interrupted")
+	var saved []byte
+	checkpoints := 0
+	_, err = backup.Restore(ctx, target.Client(), archive, &backup.RestoreOptions{
+		Checkpoint: func(s *backup.RestoreState) error {
+			if checkpoints++; checkpoints == 5 {
+				return errInterrupted
+			}
+			data, err := json.Marshal(s)
+			saved = data
+			return err
+		},
+	})
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("expected the restore to be interrupted, got %v", err)
+	}
+	var state backup.RestoreState
+	if err := json.Unmarshal(saved, &state); err != nil {
+		t.Fatal(err)
+	}
+	resumed := 0
+	_, err = backup.Restore(ctx, target.Client(), archive, &backup.RestoreOptions{
+		State: &state,
+		OnProgress: func(p backup.Progress) {
+			if p.Resumed {
+				resumed++
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed == 0 {
+		t.Error("expected objects restored by the first run to be skipped")
+	}
+
+	client := target.Client()
+	apps, err := client.Application.List(ctx, nil)
+	if err != nil || len(apps.Data) != 2 {
+		t.Fatalf("expected 2 applications, got %+v (%v)", apps, err)
+	}
+	app, err := client.Application.Get(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source := archive.Applications[0]; source.Uid != "acme" || state.Ids[source.Id] != app.Id {
+		t.Errorf("expected %s to be mapped to %s, got %q", source.Id, app.Id, state.Ids[source.Id])
+	}
+	endpointSecret, err := client.Endpoint.GetSecret(ctx, "acme", "main")
+	if err != nil || endpointSecret.Key != secret {
+		t.Errorf("expected the secret to be restored, got %+v (%v)", endpointSecret, err)
+	}
+	headers, err := client.Endpoint.GetHeaders(ctx, "acme", "main")
+	if err != nil || headers.Headers["X-Tenant"] != "acme" || len(headers.Sensitive) != 0 {
+		t.Errorf("unexpected headers %+v (%v)", headers, err)
+	}
+	transformation, err := client.Endpoint.TransformationGet(ctx, "acme", "main")
+	if err != nil || transformation.Enabled == nil || !*transformation.Enabled {
+		t.Errorf("expected the transformation to be restored, got %+v (%v)", transformation, err)
+	}
+	integrations, err := client.Integration.List(ctx, "acme", nil)
+	if err != nil || len(integrations.Data) != 1 {
+		t.Errorf("expected 1 integration, got %+v (%v)", integrations, err)
+	}
+}
+
+func TestRestoreIncomplete(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	archive := &backup.Archive{Version: backup.FormatVersion}
+	if _, err := backup.Restore(context.Background(), srv.Client(), archive, nil); err == nil {
+		t.Fatal("expected an incomplete archive to be refused")
+	}
+}
+
+func TestRestoreOverExistingConfiguration(t *testing.T) {
+	source := svixtest.NewServer(nil)
+	defer source.Close()
+	target := svixtest.NewServer(nil)
+	defer target.Close()
+	ctx := context.Background()
+	populate(t, source.Client())
+	archive, err := backup.Backup(ctx, source.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupt the restore right after the first integration is created.
+	errInterrupted := errors.New("interrupted")
+	integrationId := archive.Applications[0].Integrations[0].Id
+	var saved []byte
+	_, err = backup.Restore(ctx, target.Client(), archive, &backup.RestoreOptions{
+		Checkpoint: func(s *backup.RestoreState) error {
+			data, err := json.Marshal(s)
+			saved = data
+			if _, ok := s.Ids[integrationId]; ok {
+				return errInterrupted
+			}
+			return err
+		},
+	})
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("expected the restore to be interrupted, got %v", err)
+	}
+	var state backup.RestoreState
+	if err := json.Unmarshal(saved, &state); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backup.Restore(ctx, target.Client(), archive, &backup.RestoreOptions{State: &state}); err != nil {
+		t.Fatal(err)
+	}
+	client := target.Client()
+	integrations, err := client.Integration.List(ctx, "acme", nil)
+	if err != nil || len(integrations.Data) != 1 {
+		t.Errorf("expected the integration not to be duplicated, got %+v (%v)", integrations, err)
+	}
+
+	// Restoring again keeps the sensitive headers set on the target.
+	err = client.Endpoint.PatchHeaders(ctx, "acme", "main", &svix.EndpointHeadersPatchIn{
+		Headers: map[string]string{"Authorization": "Bearer restored"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backup.Restore(ctx, client, archive, nil); err != nil {
+		t.Fatal(err)
+	}
+	headers, err := client.Endpoint.GetHeaders(ctx, "acme", "main")
+	if err != nil || headers.Headers["X-Tenant"] != "acme" || len(headers.Sensitive) != 1 || headers.Sensitive[0] != "Authorization" {
+		t.Errorf("expected the sensitive header to be kept, got %+v (%v)", headers, err)
+	}
+}
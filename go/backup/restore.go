@@ -0,0 +1,298 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/ptr"
+)
+
+// RestoreState records the progress of a restore, so that an interrupted
+// one can be resumed.
+type RestoreState struct {
+	// Ids maps the ids of the archive to those of the restored objects.
+	Ids map[string]string `json:"ids"`
+	// Done holds the objects that were restored completely, by id (or
+	// "event-type:<name>" for event types).
+	Done map[string]bool `json:"done"`
+}
+
+type RestoreOptions struct {
+	// The state of an interrupted restore, to resume it.
+	State *RestoreState
+	// Called after each object is restored.
+	OnProgress func(Progress)
+	// Called with the state after each object is created.
+	Checkpoint func(*RestoreState) error
+}
+
+type restorer struct {
+	client *svix.Svix
+	opts   RestoreOptions
+	state  *RestoreState
+}
+
+// Restore recreates the contents of a complete archive in the environment
+// of client. Objects with a uid that already exist there are updated
+// instead, so that restoring into a partially populated environment works.
+// The returned state maps the ids of the archive to the new ones.
+func Restore(ctx context.Context, client *svix.Svix, archive *Archive, options *RestoreOptions) (*RestoreState, error) {
+	if !archive.Complete {
+		return nil, errors.New("the archive is incomplete: resume the backup first")
+	}
+	r := &restorer{client: client}
+	if options != nil {
+		r.opts = *options
+	}
+	r.state = r.opts.State
+	if r.state == nil {
+		r.state = &RestoreState{}
+	}
+	if r.state.Ids == nil {
+		r.state.Ids = map[string]string{}
+	}
+	if r.state.Done == nil {
+		r.state.Done = map[string]bool{}
+	}
+
+	for _, et := range archive.EventTypes {
+		if err := r.eventType(ctx, et); err != nil {
+			return r.state, fmt.Errorf("event type %s: %w", et.Name, err)
+		}
+	}
+	for _, app := range archive.Applications {
+		if err := r.application(ctx, app); err != nil {
+			return r.state, fmt.Errorf("application %s: %w", app.Id, err)
+		}
+	}
+	return r.state, nil
+}
+
+func (r *restorer) progress(p Progress) {
+	if r.opts.OnProgress != nil {
+		r.opts.OnProgress(p)
+	}
+}
+
+func (r *restorer) checkpoint() error {
+	if r.opts.Checkpoint != nil {
+		return r.opts.Checkpoint(r.state)
+	}
+	return nil
+}
+
+// created records the id of a new object, before its restoration is
+// complete.
+func (r *restorer) created(id string, newId string) error {
+	r.state.Ids[id] = newId
+	return r.checkpoint()
+}
+
+func (r *restorer) done(key string, p Progress) error {
+	r.state.Done[key] = true
+	r.progress(p)
+	return r.checkpoint()
+}
+
+func isConflict(err error) bool {
+	var svixErr *svix.Error
+	return errors.As(err, &svixErr) && svixErr.Status() == http.StatusConflict
+}
+
+func (r *restorer) eventType(ctx context.Context, et *EventType) error {
+	key := "event-type:" + et.Name
+	if r.state.Done[key] {
+		r.progress(Progress{Kind: KindEventType, Id: et.Name, Resumed: true})
+		return nil
+	}
+	in := &svix.EventTypeIn{
+		Name:        et.Name,
+		Description: et.Description,
+		Archived:    &et.Archived,
+		FeatureFlag: *ptr.NullableString(et.FeatureFlag),
+		Schemas:     et.Schemas,
+	}
+	_, err := r.client.EventType.Create(ctx, in)
+	if isConflict(err) {
+		_, err = r.client.EventType.Update(ctx, et.Name, &svix.EventTypeUpdate{
+			Description: in.Description,
+			Archived:    in.Archived,
+			FeatureFlag: in.FeatureFlag,
+			Schemas:     in.Schemas,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return r.done(key, Progress{Kind: KindEventType, Id: et.Name})
+}
+
+func (r *restorer) application(ctx context.Context, app *Application) error {
+	appId, ok := r.state.Ids[app.Id]
+	if !ok {
+		in := &svix.ApplicationIn{
+			Name:      app.Name,
+			Uid:       *ptr.NullableString(app.Uid),
+			RateLimit: *svix.NullableInt32(app.RateLimit),
+		}
+		if app.Metadata != nil {
+			in.Metadata = &app.Metadata
+		}
+		out, err := r.client.Application.Create(ctx, in)
+		if isConflict(err) && app.Uid != "" {
+			out, err = r.client.Application.Update(ctx, app.Uid, in)
+		}
+		if err != nil {
+			return err
+		}
+		appId = out.Id
+		if err := r.created(app.Id, appId); err != nil {
+			return err
+		}
+	}
+	if !r.state.Done[app.Id] {
+		if err := r.done(app.Id, Progress{Kind: KindApplication, Id: app.Id, Uid: app.Uid, NewId: appId}); err != nil {
+			return err
+		}
+	} else {
+		r.progress(Progress{Kind: KindApplication, Id: app.Id, Uid: app.Uid, NewId: appId, Resumed: true})
+	}
+
+	for _, ep := range app.Endpoints {
+		if err := r.endpoint(ctx, appId, ep); err != nil {
+			return fmt.Errorf("endpoint %s: %w", ep.Id, err)
+		}
+	}
+	for _, integ := range app.Integrations {
+		if err := r.integration(ctx, appId, integ); err != nil {
+			return fmt.Errorf("integration %s: %w", integ.Id, err)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) endpoint(ctx context.Context, appId string, ep *Endpoint) error {
+	if r.state.Done[ep.Id] {
+		r.progress(Progress{Kind: KindEndpoint, Id: ep.Id, Uid: ep.Uid, NewId: r.state.Ids[ep.Id], Resumed: true})
+		return nil
+	}
+	endpointId, ok := r.state.Ids[ep.Id]
+	if !ok {
+		in := &svix.EndpointIn{
+			Url:         ep.Url,
+			Uid:         *ptr.NullableString(ep.Uid),
+			Description: &ep.Description,
+			Disabled:    &ep.Disabled,
+			FilterTypes: ep.FilterTypes,
+			Channels:    ep.Channels,
+			RateLimit:   *svix.NullableInt32(ep.RateLimit),
+			Version:     *svix.NullableInt32(nonZero(ep.Version)),
+			Secret:      *ptr.NullableString(ep.Secret),
+		}
+		if ep.Metadata != nil {
+			in.Metadata = &ep.Metadata
+		}
+		out, err := r.client.Endpoint.Create(ctx, appId, in)
+		if isConflict(err) && ep.Uid != "" {
+			out, err = r.updateEndpoint(ctx, appId, ep)
+		}
+		if err != nil {
+			return err
+		}
+		endpointId = out.Id
+		if err := r.created(ep.Id, endpointId); err != nil {
+			return err
+		}
+	}
+
+	var warnings []string
+	// Patch rather than replace the headers, so that the sensitive headers
+	// already set on an existing endpoint are kept.
+	if len(ep.Headers) != 0 {
+		if err := r.client.Endpoint.PatchHeaders(ctx, appId, endpointId, &svix.EndpointHeadersPatchIn{Headers: ep.Headers}); err != nil {
+			return err
+		}
+	}
+	if len(ep.SensitiveHeaders) != 0 {
+		warnings = append(warnings, fmt.Sprintf("the sensitive headers %v weren't backed up and must be set again", ep.SensitiveHeaders))
+	}
+	if ep.Transformation != nil {
+		err := r.client.Endpoint.TransformatioPartialUpdate(ctx, appId, endpointId, &svix.EndpointTransformationIn{
+			Code:    *ptr.NullableString(ep.Transformation.Code),
+			Enabled: &ep.Transformation.Enabled,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return r.done(ep.Id, Progress{Kind: KindEndpoint, Id: ep.Id, Uid: ep.Uid, NewId: endpointId, Warnings: warnings})
+}
+
+// updateEndpoint updates an existing endpoint with the same uid to match the
+// archive, rotating its secret if it differs.
+func (r *restorer) updateEndpoint(ctx context.Context, appId string, ep *Endpoint) (*svix.EndpointOut, error) {
+	update := &svix.EndpointUpdate{
+		Url:         ep.Url,
+		Uid:         *ptr.NullableString(ep.Uid),
+		Description: &ep.Description,
+		Disabled:    &ep.Disabled,
+		FilterTypes: ep.FilterTypes,
+		Channels:    ep.Channels,
+		RateLimit:   *svix.NullableInt32(ep.RateLimit),
+		Version:     *svix.NullableInt32(nonZero(ep.Version)),
+	}
+	if ep.Metadata != nil {
+		update.Metadata = &ep.Metadata
+	}
+	out, err := r.client.Endpoint.Update(ctx, appId, ep.Uid, update)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := r.client.Endpoint.GetSecret(ctx, appId, out.Id)
+	if err != nil {
+		return nil, err
+	}
+	if ep.Secret != "" && secret.Key != ep.Secret {
+		err := r.client.Endpoint.RotateSecret(ctx, appId, out.Id, &svix.EndpointSecretRotateIn{Key: *ptr.NullableString(ep.Secret)})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (r *restorer) integration(ctx context.Context, appId string, integ *Integration) error {
+	if r.state.Done[integ.Id] {
+		r.progress(Progress{Kind: KindIntegration, Id: integ.Id, NewId: r.state.Ids[integ.Id], Resumed: true})
+		return nil
+	}
+	// Integrations have no uid to find them by, so the new one must be
+	// recorded before anything else can fail.
+	integrationId, ok := r.state.Ids[integ.Id]
+	if !ok {
+		out, err := r.client.Integration.Create(ctx, appId, &svix.IntegrationIn{Name: integ.Name})
+		if err != nil {
+			return err
+		}
+		integrationId = out.Id
+		if err := r.created(integ.Id, integrationId); err != nil {
+			return err
+		}
+	}
+	return r.done(integ.Id, Progress{
+		Kind:     KindIntegration,
+		Id:       integ.Id,
+		NewId:    integrationId,
+		Warnings: []string{"integration keys can't be restored: a new key was generated"},
+	})
+}
+
+func nonZero(n int32) *int32 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,160 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+// ErrNoFilterTypesLeft is returned by RemoveFilterTypes for endpoints
+// subscribed to none of the other event types: removing their filter types
+// would subscribe them to every event type instead.
+var ErrNoFilterTypesLeft = errors.New("removing the filter types would leave none, subscribing the endpoint to every event type")
+
+// Action is an operation on an endpoint. Custom actions can be built by
+// setting its fields.
+type Action struct {
+	Name string
+	// Reports whether the endpoint is already in the desired state, in
+	// which case Do isn't called. Optional.
+	Skip func(t *Target) bool
+	Do   func(ctx context.Context, client *svix.Svix, t *Target) error
+}
+
+func (a Action) apply(ctx context.Context, client *svix.Svix, t *Target, dryRun bool) (skipped bool, err error) {
+	if a.Skip != nil && a.Skip(t) {
+		return true, nil
+	}
+	if dryRun {
+		return false, nil
+	}
+	return false, a.Do(ctx, client, t)
+}
+
+func patch(ctx context.Context, client *svix.Svix, t *Target, p *svix.EndpointPatch) error {
+	out, err := client.Endpoint.Patch(ctx, t.AppId, t.Endpoint.Id, p)
+	if err != nil {
+		return err
+	}
+	t.Endpoint = out
+	return nil
+}
+
+// Patch applies the same patch to every endpoint.
+func Patch(p *svix.EndpointPatch) Action {
+	return Action{
+		Name: "patch",
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			return patch(ctx, client, t, p)
+		},
+	}
+}
+
+// AddFilterTypes subscribes the endpoints to more event types. Endpoints
+// without filter types already receive every event type, and are skipped.
+func AddFilterTypes(names ...string) Action {
+	missing := func(t *Target) []string {
+		var out []string
+		for _, name := range names {
+			if !contains(t.Endpoint.FilterTypes, name) {
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+	return Action{
+		Name: "add-filter-types",
+		Skip: func(t *Target) bool {
+			return len(t.Endpoint.FilterTypes) == 0 || len(missing(t)) == 0
+		},
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			filterTypes := append(append([]string(nil), t.Endpoint.FilterTypes...), missing(t)...)
+			return patch(ctx, client, t, &svix.EndpointPatch{FilterTypes: filterTypes})
+		},
+	}
+}
+
+// RemoveFilterTypes unsubscribes the endpoints from event types. It fails
+// with ErrNoFilterTypesLeft rather than leave an endpoint without filter
+// types.
+func RemoveFilterTypes(names ...string) Action {
+	return Action{
+		Name: "remove-filter-types",
+		Skip: func(t *Target) bool {
+			for _, name := range names {
+				if contains(t.Endpoint.FilterTypes, name) {
+					return false
+				}
+			}
+			return true
+		},
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			var filterTypes []string
+			for _, name := range t.Endpoint.FilterTypes {
+				if !contains(names, name) {
+					filterTypes = append(filterTypes, name)
+				}
+			}
+			if len(filterTypes) == 0 {
+				return ErrNoFilterTypesLeft
+			}
+			return patch(ctx, client, t, &svix.EndpointPatch{FilterTypes: filterTypes})
+		},
+	}
+}
+
+func setDisabled(name string, disabled bool) Action {
+	return Action{
+		Name: name,
+		Skip: func(t *Target) bool {
+			return (t.Endpoint.Disabled != nil && *t.Endpoint.Disabled) == disabled
+		},
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			return patch(ctx, client, t, &svix.EndpointPatch{Disabled: &disabled})
+		},
+	}
+}
+
+// Disable disables the endpoints, skipping those already disabled.
+func Disable() Action {
+	return setDisabled("disable", true)
+}
+
+// Enable enables the endpoints, skipping those already enabled.
+func Enable() Action {
+	return setDisabled("enable", false)
+}
+
+// RotateSecret gives the endpoints new random secrets. The previous secrets
+// remain valid for 24 hours.
+func RotateSecret() Action {
+	return Action{
+		Name: "rotate-secret",
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			return client.Endpoint.RotateSecret(ctx, t.AppId, t.Endpoint.Id, &svix.EndpointSecretRotateIn{})
+		},
+	}
+}
+
+// Delete deletes the endpoints.
+func Delete() Action {
+	return Action{
+		Name: "delete",
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			return client.Endpoint.Delete(ctx, t.AppId, t.Endpoint.Id)
+		},
+	}
+}
+
+// Recover resends the messages that failed to be delivered to the endpoints
+// since the given time.
+func Recover(since time.Time) Action {
+	return Action{
+		Name: "recover",
+		Do: func(ctx context.Context, client *svix.Svix, t *Target) error {
+			return client.Endpoint.Recover(ctx, t.AppId, t.Endpoint.Id, &svix.RecoverIn{Since: since})
+		},
+	}
+}
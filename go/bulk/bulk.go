@@ -0,0 +1,288 @@
+// Package bulk applies an operation to many endpoints at once.
+//
+// Endpoints are selected across applications with a Selector, and an Action
+// is then run on each of them with bounded concurrency and an optional rate
+// limit. Every endpoint gets a Result, so that failures can be retried:
+//
+//	targets, err := bulk.Select(ctx, client, &bulk.Selector{Hosts: []string{"*.example.com"}})
+//	report := bulk.Run(ctx, client, targets, bulk.AddFilterTypes("invoice.paid"), &bulk.Options{Concurrency: 8})
+//	for _, r := range report.Failed() {
+//		log.Printf("%s/%s: %s", r.Target.AppId, r.Target.Endpoint.Id, r.Err)
+//	}
+package bulk
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/internal/ptr"
+)
+
+// Target is an endpoint selected for an action.
+type Target struct {
+	AppId    string
+	AppUid   string
+	Endpoint *svix.EndpointOut
+}
+
+// Selector describes the endpoints to act on. An endpoint is selected when
+// it matches all the criteria that are set.
+type Selector struct {
+	// The applications to look into, by id or uid. All of them when empty.
+	Apps []string
+	// The hosts of the endpoint URL, any of which must match. A leading
+	// "*." matches subdomains: "*.example.com" matches "api.example.com"
+	// but not "example.com".
+	Hosts []string
+	// Metadata entries the endpoint must have.
+	Metadata map[string]string
+	// Event types the endpoint must be subscribed to explicitly. Endpoints
+	// without filter types, which receive every event type, don't match.
+	FilterTypes []string
+	// Whether the endpoint must be disabled, or enabled.
+	Disabled *bool
+	// An arbitrary predicate, applied last.
+	Match func(t *Target) bool
+}
+
+func (s *Selector) matches(t *Target) bool {
+	ep := t.Endpoint
+	if len(s.Hosts) != 0 {
+		u, err := url.Parse(ep.Url)
+		if err != nil || !matchHost(s.Hosts, u.Hostname()) {
+			return false
+		}
+	}
+	for k, v := range s.Metadata {
+		if got, ok := ep.Metadata[k]; !ok || got != v {
+			return false
+		}
+	}
+	for _, name := range s.FilterTypes {
+		if !contains(ep.FilterTypes, name) {
+			return false
+		}
+	}
+	if s.Disabled != nil && *s.Disabled != (ep.Disabled != nil && *ep.Disabled) {
+		return false
+	}
+	return s.Match == nil || s.Match(t)
+}
+
+func matchHost(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if suffix := strings.TrimPrefix(p, "*"); suffix != p {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Select lists the endpoints matching s.
+func Select(ctx context.Context, client *svix.Svix, s *Selector) ([]*Target, error) {
+	if s == nil {
+		s = &Selector{}
+	}
+	var apps []openapi.ApplicationOut
+	if len(s.Apps) != 0 {
+		for _, id := range s.Apps {
+			app, err := client.Application.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			apps = append(apps, openapi.ApplicationOut(*app))
+		}
+	} else {
+		var err error
+		apps, err = paginate.All(func(iterator *string) ([]openapi.ApplicationOut, *string, bool, error) {
+			out, err := client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+			if err != nil {
+				return nil, nil, false, err
+			}
+			return out.Data, out.Iterator.Get(), out.Done, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var targets []*Target
+	for _, app := range apps {
+		endpoints, err := paginate.All(func(iterator *string) ([]openapi.EndpointOut, *string, bool, error) {
+			out, err := client.Endpoint.List(ctx, app.Id, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+			if err != nil {
+				return nil, nil, false, err
+			}
+			return out.Data, out.Iterator.Get(), out.Done, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range endpoints {
+			ep := svix.EndpointOut(ep)
+			t := &Target{AppId: app.Id, AppUid: ptr.Value(app.Uid.Get()), Endpoint: &ep}
+			if s.matches(t) {
+				targets = append(targets, t)
+			}
+		}
+	}
+	return targets, nil
+}
+
+type Options struct {
+	// Number of endpoints processed concurrently. Defaults to 4.
+	Concurrency int
+	// Maximum number of actions started per second, across all workers.
+	// Zero means no limit.
+	RateLimit float64
+	// Only report what would be done, without calling the API.
+	DryRun bool
+	// Called with the result of each endpoint, as soon as it is known.
+	OnResult func(Result)
+}
+
+// Result is the outcome of an action on an endpoint.
+type Result struct {
+	Target *Target
+	// Set when the endpoint didn't need to be changed.
+	Skipped bool
+	// Set when the action wasn't applied because of Options.DryRun.
+	DryRun bool
+	Err    error
+}
+
+// Report holds the results of a Run, in the order of the targets.
+type Report struct {
+	Results []Result
+}
+
+// Succeeded returns the results of the endpoints the action was applied
+// to, or would have been with DryRun.
+func (r *Report) Succeeded() []Result {
+	return r.filter(func(res Result) bool { return res.Err == nil && !res.Skipped })
+}
+
+func (r *Report) Skipped() []Result {
+	return r.filter(func(res Result) bool { return res.Skipped })
+}
+
+func (r *Report) Failed() []Result {
+	return r.filter(func(res Result) bool { return res.Err != nil })
+}
+
+func (r *Report) filter(keep func(Result) bool) []Result {
+	var out []Result
+	for _, res := range r.Results {
+		if keep(res) {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Run applies action to the targets. It stops starting new actions when
+// ctx is done, reporting the remaining targets as failed with ctx.Err().
+func Run(ctx context.Context, client *svix.Svix, targets []*Target, action Action, options *Options) *Report {
+	var opts Options
+	if options != nil {
+		opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	limiter := newLimiter(opts.RateLimit)
+
+	report := &Report{Results: make([]Result, len(targets))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				res := Result{Target: targets[i]}
+				if err := limiter.wait(ctx); err != nil {
+					res.Err = err
+				} else {
+					res.Skipped, res.Err = action.apply(ctx, client, targets[i], opts.DryRun)
+					res.DryRun = opts.DryRun && !res.Skipped && res.Err == nil
+				}
+				mu.Lock()
+				report.Results[i] = res
+				if opts.OnResult != nil {
+					opts.OnResult(res)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range targets {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return report
+}
+
+// limiter spaces out the start of actions.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	l := &limiter{}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.interval == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+package bulk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/bulk"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func setup(t *testing.T) (*svixtest.Server, *svix.Svix) {
+	t.Helper()
+	srv := svixtest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	client := srv.Client()
+	ctx := context.Background()
+	for _, name := range []string{"invoice.paid", "invoice.voided"} {
+		if _, err := client.EventType.Create(ctx, &svix.EventTypeIn{Name: name, Description: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: fmt.Sprint("app ", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, host := range []string{"hooks.acme.com", "acme.com", "other.org"} {
+			in := &svix.EndpointIn{
+				Url:         fmt.Sprintf("https://%s/%d", host, i),
+				FilterTypes: []string{"invoice.paid"},
+				Metadata:    &map[string]string{"plan": []string{"free", "pro"}[j%2]},
+			}
+			if host == "other.org" {
+				in.FilterTypes = nil
+			}
+			if _, err := client.Endpoint.Create(ctx, app.Id, in); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return srv, client
+}
+
+func urls(results []bulk.Result) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.Target.Endpoint.Url)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestSelect(t *testing.T) {
+	_, client := setup(t)
+	ctx := context.Background()
+	testCases := []struct {
+		name     string
+		selector *bulk.Selector
+		count    int
+	}{
+		{"all", nil, 9},
+		{"exact host", &bulk.Selector{Hosts: []string{"ACME.com"}}, 3},
+		{"subdomains", &bulk.Selector{Hosts: []string{"*.acme.com"}}, 3},
+		{"metadata", &bulk.Selector{Metadata: map[string]string{"plan": "pro"}}, 3},
+		{"filter types", &bulk.Selector{FilterTypes: []string{"invoice.paid"}}, 6},
+		{"enabled", &bulk.Selector{Disabled: &[]bool{false}[0]}, 9},
+		{"predicate", &bulk.Selector{Match: func(t *bulk.Target) bool { return t.Endpoint.Url == "https://other.org/1" }}, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets, err := bulk.Select(ctx, client, tc.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(targets) != tc.count {
+				t.Errorf("expected %d endpoints, got %d", tc.count, len(targets))
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	_, client := setup(t)
+	ctx := context.Background()
+	targets, err := bulk.Select(ctx, client, &bulk.Selector{Hosts: []string{"acme.com", "other.org"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Endpoints without filter types already receive invoice.voided.
+	report := bulk.Run(ctx, client, targets, bulk.AddFilterTypes("invoice.voided"), &bulk.Options{DryRun: true})
+	if len(report.Succeeded()) != 3 || len(report.Skipped()) != 3 || !report.Results[0].DryRun {
+		t.Fatalf("unexpected dry-run report: %+v", report.Results)
+	}
+	if selected, _ := bulk.Select(ctx, client, &bulk.Selector{FilterTypes: []string{"invoice.voided"}}); len(selected) != 0 {
+		t.Fatal("the dry run changed endpoints")
+	}
+
+	var calls int
+	report = bulk.Run(ctx, client, targets, bulk.AddFilterTypes("invoice.voided"), &bulk.Options{
+		Concurrency: 2,
+		OnResult:    func(bulk.Result) { calls++ },
+	})
+	if calls != 6 || len(report.Succeeded()) != 3 || len(report.Failed()) != 0 {
+		t.Fatalf("unexpected report: %+v", report.Results)
+	}
+	if got := urls(report.Succeeded()); got[0] != "https://acme.com/0" {
+		t.Errorf("unexpected endpoints updated: %v", got)
+	}
+	if selected, _ := bulk.Select(ctx, client, &bulk.Selector{FilterTypes: []string{"invoice.paid", "invoice.voided"}}); len(selected) != 3 {
+		t.Fatalf("expected 3 endpoints with both filter types, got %d", len(selected))
+	}
+
+	// Removing both filter types would subscribe the endpoints to
+	// everything.
+	report = bulk.Run(ctx, client, targets, bulk.RemoveFilterTypes("invoice.paid", "invoice.voided"), nil)
+	if failed := report.Failed(); len(failed) != 3 || !errors.Is(failed[0].Err, bulk.ErrNoFilterTypesLeft) {
+		t.Fatalf("unexpected report: %+v", report.Results)
+	}
+
+	report = bulk.Run(ctx, client, targets, bulk.Disable(), nil)
+	if len(report.Succeeded()) != 6 {
+		t.Fatalf("unexpected report: %+v", report.Results)
+	}
+	if selected, _ := bulk.Select(ctx, client, &bulk.Selector{Disabled: &[]bool{true}[0]}); len(selected) != 6 {
+		t.Fatalf("expected 6 disabled endpoints, got %d", len(selected))
+	}
+	if report := bulk.Run(ctx, client, targets, bulk.Disable(), nil); len(report.Skipped()) != 6 {
+		t.Fatalf("expected disabled endpoints to be skipped: %+v", report.Results)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	_, client := setup(t)
+	ctx := context.Background()
+	targets, err := bulk.Select(ctx, client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	report := bulk.Run(ctx, client, targets[:5], bulk.RotateSecret(), &bulk.Options{Concurrency: 5, RateLimit: 50})
+	if len(report.Succeeded()) != 5 {
+		t.Fatalf("unexpected report: %+v", report.Results)
+	}
+	// The first action starts immediately, and the others every 20ms.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected the rate limit to space out the actions, took %s", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	report = bulk.Run(cancelled, client, targets, bulk.Delete(), nil)
+	if failed := report.Failed(); len(failed) != len(targets) || !errors.Is(failed[0].Err, context.Canceled) {
+		t.Fatalf("expected every action to be cancelled: %+v", report.Results)
+	}
+}
@@ -0,0 +1,26 @@
+module github.com/svix/svix-webhooks/go/cmd
+
+go 1.20
+
+require (
+	github.com/svix/svix-webhooks v0.0.0
+	github.com/svix/svix-webhooks/go/eventgen v0.0.0
+	github.com/svix/svix-webhooks/go/reconcile v0.0.0
+	github.com/svix/svix-webhooks/go/relay v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/golang/protobuf v1.4.2 // indirect
+	golang.org/x/net v0.7.0 // indirect
+	golang.org/x/oauth2 v0.0.0-20210514164344-f6687ab2804c // indirect
+	google.golang.org/appengine v1.6.6 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+)
+
+replace (
+	github.com/svix/svix-webhooks => ../..
+	github.com/svix/svix-webhooks/go/eventgen => ../eventgen
+	github.com/svix/svix-webhooks/go/reconcile => ../reconcile
+	github.com/svix/svix-webhooks/go/relay => ../relay
+)
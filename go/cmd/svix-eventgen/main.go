@@ -0,0 +1,83 @@
+// Command svix-eventgen generates Go types for the payloads of event types.
+//
+// Usage:
+//
+//	svix-eventgen [-package events] [-out events_gen.go] [-from file] [-version 1]
+//
+// The event types are fetched from the API, with the token and server URL
+// of the SVIX_AUTH_TOKEN and SVIX_SERVER_URL environment variables, or read
+// from -from: a backup archive or a desired-state file of the svix CLI. It
+// is meant to be run by `go generate`:
+//
+//	//go:generate go run github.com/svix/svix-webhooks/go/cmd/svix-eventgen -out events_gen.go
+//
+// The package defaults to the one running `go generate`. See package
+// eventgen for the generated code.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/eventgen"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("svix-eventgen: ")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	out := flag.String("out", "", "file to write (default stdout)")
+	from := flag.String("from", "", "backup archive or state file to read the event types from, instead of the API")
+	version := flag.String("version", "", "schema version to generate (default the latest of each event type)")
+	flag.Parse()
+	if *pkg == "" {
+		log.Fatal("-package is required outside of go generate")
+	}
+
+	var types []eventgen.EventType
+	var err error
+	if *from != "" {
+		types, err = eventgen.LoadFile(*from, *version)
+	} else {
+		types, err = fetch(*version)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := eventgen.Generate(types, &eventgen.Options{Package: *pkg})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*out, src, 0o644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fetch(version string) ([]eventgen.EventType, error) {
+	token := os.Getenv("SVIX_AUTH_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("SVIX_AUTH_TOKEN must be set, or the event types read with -from")
+	}
+	options := &svix.SvixOptions{}
+	if raw := os.Getenv("SVIX_SERVER_URL"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SVIX_SERVER_URL: %w", err)
+		}
+		options.ServerUrl = u
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return eventgen.Fetch(ctx, svix.New(token, options), version)
+}
@@ -0,0 +1,76 @@
+// Command svix-exporter serves the metrics of a Svix environment to
+// Prometheus.
+//
+// Usage:
+//
+//	SVIX_AUTH_TOKEN=sk_... svix-exporter [-addr :9464] [-app app_1 -app app_2] [-per-endpoint]
+//
+// The metrics (see package exporter) are served on /metrics. The server URL
+// defaults to the one of the token's region, and can be set with
+// SVIX_SERVER_URL.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/exporter"
+)
+
+type appsFlag []string
+
+func (f *appsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *appsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func main() {
+	addr := flag.String("addr", ":9464", "address to listen on")
+	var apps appsFlag
+	flag.Var(&apps, "app", "application id or uid to export (repeatable, default all)")
+	perEndpoint := flag.Bool("per-endpoint", false, "also export the metrics of each endpoint")
+	tasks := flag.Bool("background-tasks", false, "export the number of background tasks")
+	window := flag.Duration("window", time.Hour, "window over which messages and attempts are counted")
+	cacheTTL := flag.Duration("cache-ttl", time.Minute, "how long metrics are cached between collections")
+	flag.Parse()
+
+	token := os.Getenv("SVIX_AUTH_TOKEN")
+	if token == "" {
+		log.Fatal("SVIX_AUTH_TOKEN must be set")
+	}
+	options := &svix.SvixOptions{}
+	if raw := os.Getenv("SVIX_SERVER_URL"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			log.Fatalf("invalid SVIX_SERVER_URL: %v", err)
+		}
+		options.ServerUrl = u
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter.New(svix.New(token, options), &exporter.Options{
+		Apps:            apps,
+		PerEndpoint:     *perEndpoint,
+		BackgroundTasks: *tasks,
+		Window:          *window,
+		CacheTTL:        *cacheTTL,
+		OnError: func(err error) {
+			log.Printf("collecting metrics: %v", err)
+		},
+	}))
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("exporter listening on %s", *addr)
+	log.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,36 @@
+// Command svix-relay runs a relay server for `svix listen`.
+//
+// Usage:
+//
+//	svix-relay [-addr :8080] [-public-url https://relay.example.com]
+//
+// Listeners connect to ws://<addr>/listen, and requests to
+// <public-url>/e/<token> are forwarded to them. The keys of the sessions
+// are derived from $SVIX_RELAY_SECRET, so that listeners can resume their
+// sessions after a restart; without it, they are lost.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/relay"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	publicURL := flag.String("public-url", "", "base URL under which the server is reachable (default the Host of the listener's request)")
+	timeout := flag.Duration("timeout", 30*time.Second, "how long to wait for a listener's response")
+	flag.Parse()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           &relay.Server{PublicURL: *publicURL, Timeout: *timeout, Secret: []byte(os.Getenv("SVIX_RELAY_SECRET"))},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("relay listening on %s", *addr)
+	log.Fatal(srv.ListenAndServe())
+}
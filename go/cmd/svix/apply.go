@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/svix/svix-webhooks/go/reconcile"
+)
+
+var planCommand = &command{
+	name: "plan",
+	help: "Show the changes needed to match a desired-state file",
+	run: func(c *cli, ctx context.Context, args []string) error {
+		return c.reconcile(ctx, "plan", args)
+	},
+}
+
+var applyCommand = &command{
+	name: "apply",
+	help: "Apply a desired-state file",
+	run: func(c *cli, ctx context.Context, args []string) error {
+		return c.reconcile(ctx, "apply", args)
+	},
+}
+
+// reconcile implements plan and apply, which only differ in whether the plan
+// is applied once shown.
+func (c *cli) reconcile(ctx context.Context, name string, args []string) error {
+	fs := flag.NewFlagSet("svix "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	prune := fs.Bool("prune", false, "delete the applications, endpoints and event types missing from the file")
+	var yes *bool
+	if name == "apply" {
+		yes = fs.Bool("yes", false, "apply without asking for confirmation")
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix %s <file> [flags]\n\n", name)
+		fmt.Fprintf(c.stderr, "Compares the event types, applications and endpoints described in a YAML or\nJSON file (or - for stdin) with the live configuration")
+		if name == "apply" {
+			fmt.Fprintf(c.stderr, ", and applies the\ndifferences once confirmed")
+		}
+		fmt.Fprintf(c.stderr, ".\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	data, err := c.readInput(positional[0])
+	if err != nil {
+		return err
+	}
+	state, err := reconcile.Load(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", positional[0], err)
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	plan, err := reconcile.NewPlan(ctx, client, state, &reconcile.Options{Prune: *prune})
+	if err != nil {
+		return err
+	}
+	if c.output != "" {
+		if err := c.print(plan, nil); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprint(c.stdout, plan)
+	}
+	if name != "apply" || len(plan.Changes) == 0 {
+		return nil
+	}
+
+	if !*yes {
+		if positional[0] == "-" {
+			return errors.New("the state was read from stdin: pass --yes to apply it")
+		}
+		fmt.Fprint(c.stderr, "\nApply these changes? [y/N] ")
+		answer, _ := bufio.NewReader(c.stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return errors.New("aborted")
+		}
+	}
+	if err := plan.Apply(ctx, client); err != nil {
+		return err
+	}
+	if c.output == "" {
+		fmt.Fprintln(c.stdout, "Applied.")
+	}
+	return nil
+}
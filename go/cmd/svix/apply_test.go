@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	env := map[string]string{
+		"SVIX_AUTH_TOKEN": "testsk_cli",
+		"SVIX_SERVER_URL": srv.URL().String(),
+	}
+	state := filepath.Join(t.TempDir(), "svix.yaml")
+	err := os.WriteFile(state, []byte(`
+eventTypes:
+  - name: invoice.paid
+applications:
+  - uid: acme
+    name: Acme
+    endpoints:
+      - uid: billing
+        url: https://acme.example.com/webhooks
+        filterTypes: [invoice.paid]
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCLI(t, env, "", "plan", state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "+ endpoint acme/billing\n") || !strings.Contains(out, "Plan: 3 to create, 0 to update, 0 to delete.") {
+		t.Fatalf("unexpected plan:\n%s", out)
+	}
+
+	if _, err := runCLI(t, env, "n\n", "apply", state); err == nil || err.Error() != "aborted" {
+		t.Fatalf("expected apply to be aborted, got %v", err)
+	}
+	out, err = runCLI(t, env, "y\n", "apply", state)
+	if err != nil || !strings.HasSuffix(out, "Applied.\n") {
+		t.Fatalf("apply failed (%v):\n%s", err, out)
+	}
+	ep, err := srv.Client().Endpoint.Get(context.Background(), "acme", "billing")
+	if err != nil || ep.Url != "https://acme.example.com/webhooks" {
+		t.Fatalf("unexpected endpoint %+v (%v)", ep, err)
+	}
+
+	out, err = runCLI(t, env, "", "apply", state)
+	if err != nil || out != "No changes.\n" {
+		t.Fatalf("expected no changes (%v):\n%s", err, out)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/svix/svix-webhooks/go/backup"
+)
+
+var backupCommand = &command{
+	name: "backup",
+	help: "Back up the event types, applications, endpoints and integrations",
+	run:  (*cli).backup,
+}
+
+var restoreCommand = &command{
+	name: "restore",
+	help: "Restore a backup, possibly into another environment",
+	run:  (*cli).restore,
+}
+
+func (c *cli) backup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix backup", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	resume := fs.Bool("resume", false, "resume an interrupted backup into the same file")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix backup <file> [flags]\n\n")
+		fmt.Fprintf(c.stderr, "Writes a snapshot of the environment to a file, which holds the endpoint\nsecrets and must be stored accordingly. The file is saved after each\napplication, so that an interrupted backup can be resumed.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	path := positional[0]
+	opts := &backup.BackupOptions{
+		OnProgress: c.reportProgress("backed up"),
+		Checkpoint: func(archive *backup.Archive) error {
+			return writeFileAtomic(path, archive)
+		},
+	}
+	if *resume {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		opts.Resume, err = backup.Read(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if opts.Resume.Complete {
+			return fmt.Errorf("%s is already complete", path)
+		}
+	} else if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists: pass --resume to resume an interrupted backup", path)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	archive, err := backup.Backup(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, archive); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Backed up %d event types and %d applications to %s.\n", len(archive.EventTypes), len(archive.Applications), path)
+	return nil
+}
+
+func (c *cli) restore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix restore", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	statePath := fs.String("state", "", "file recording the progress of the restore (default <file>.state)")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix restore <file> [flags]\n\n")
+		fmt.Fprintf(c.stderr, "Recreates the contents of a backup in the environment. The progress and the\nids of the restored objects are recorded in the state file: running the\ncommand again resumes an interrupted restore.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	path := positional[0]
+	if *statePath == "" {
+		*statePath = path + ".state"
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	archive, err := backup.Read(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	opts := &backup.RestoreOptions{
+		OnProgress: c.reportProgress("restored"),
+		Checkpoint: func(state *backup.RestoreState) error {
+			return writeFileAtomic(*statePath, state)
+		},
+	}
+	if data, err := os.ReadFile(*statePath); err == nil {
+		opts.State = &backup.RestoreState{}
+		if err := json.Unmarshal(data, opts.State); err != nil {
+			return fmt.Errorf("%s: %w", *statePath, err)
+		}
+		fmt.Fprintf(c.stderr, "Resuming from %s\n", *statePath)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	state, err := backup.Restore(ctx, client, archive, opts)
+	if err != nil {
+		return fmt.Errorf("%s (run the command again to resume)", formatError(err))
+	}
+	if c.output != "" {
+		return c.print(state, nil)
+	}
+	fmt.Fprintf(c.stdout, "Restored %d objects. The mapping of the ids is in %s.\n", len(state.Done), *statePath)
+	return nil
+}
+
+func (c *cli) reportProgress(verb string) func(backup.Progress) {
+	return func(p backup.Progress) {
+		name := p.Id
+		if p.Uid != "" {
+			name += " (" + p.Uid + ")"
+		}
+		if p.NewId != "" {
+			name += " as " + p.NewId
+		}
+		if p.Resumed {
+			fmt.Fprintf(c.stderr, "%s %s already %s\n", p.Kind, name, verb)
+		} else {
+			fmt.Fprintf(c.stderr, "%s %s %s\n", p.Kind, name, verb)
+		}
+		for _, w := range p.Warnings {
+			fmt.Fprintf(c.stderr, "  warning: %s\n", w)
+		}
+	}
+}
+
+// writeFileAtomic writes v as JSON to path, readable by its owner only, so
+// that an interruption never leaves a truncated file.
+func writeFileAtomic(path string, v interface{}) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
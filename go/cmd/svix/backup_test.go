@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	source := svixtest.NewServer(nil)
+	defer source.Close()
+	target := svixtest.NewServer(nil)
+	defer target.Close()
+	ctx := context.Background()
+	app, err := source.Client().Application.Create(ctx, &svix.ApplicationIn{Name: "Acme", Uid: *svix.NullableString(svix.String("acme"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Client().Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://acme.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.json")
+	sourceEnv := map[string]string{"SVIX_AUTH_TOKEN": "testsk_source", "SVIX_SERVER_URL": source.URL().String()}
+	out, err := runCLI(t, sourceEnv, "", "backup", path)
+	if err != nil || out != "Backed up 0 event types and 1 applications to "+path+".\n" {
+		t.Fatalf("backup failed (%v):\n%s", err, out)
+	}
+	if _, err := runCLI(t, sourceEnv, "", "backup", path); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an existing backup not to be overwritten, got %v", err)
+	}
+
+	targetEnv := map[string]string{"SVIX_AUTH_TOKEN": "testsk_target", "SVIX_SERVER_URL": target.URL().String()}
+	out, err = runCLI(t, targetEnv, "", "restore", path)
+	if err != nil || !strings.HasPrefix(out, "Restored 2 objects.") {
+		t.Fatalf("restore failed (%v):\n%s", err, out)
+	}
+	eps, err := target.Client().Endpoint.List(ctx, "acme", nil)
+	if err != nil || len(eps.Data) != 1 || eps.Data[0].Url != "https://acme.example.com" {
+		t.Fatalf("unexpected endpoints %+v (%v)", eps, err)
+	}
+
+	// Running it again resumes from the state file, and doesn't duplicate
+	// anything.
+	if _, err := runCLI(t, targetEnv, "", "restore", path); err != nil {
+		t.Fatal(err)
+	}
+	if eps, _ := target.Client().Endpoint.List(ctx, "acme", nil); len(eps.Data) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(eps.Data))
+	}
+}
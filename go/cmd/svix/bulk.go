@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/svix/svix-webhooks/go/bulk"
+)
+
+var bulkCommand = &command{
+	name: "bulk",
+	help: "Apply an action to many endpoints at once",
+	run:  (*cli).bulk,
+}
+
+var bulkActions = []struct {
+	name string
+	args string
+	help string
+}{
+	{"add-filter-types", "<event-type>...", "subscribe the endpoints to event types"},
+	{"remove-filter-types", "<event-type>...", "unsubscribe the endpoints from event types"},
+	{"disable", "", "disable the endpoints"},
+	{"enable", "", "enable the endpoints"},
+	{"rotate-secret", "", "rotate the secrets of the endpoints"},
+	{"recover", "", "resend the failed messages since --since"},
+	{"delete", "", "delete the endpoints"},
+}
+
+func (c *cli) bulk(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix bulk", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	var sel bulk.Selector
+	var apps, hosts, metadata, filterTypes stringsFlag
+	fs.Var(&apps, "app", "only select the endpoints of this application id or uid (repeatable)")
+	fs.Var(&hosts, "host", "only select endpoints with this URL host, or *.domain for subdomains (repeatable)")
+	fs.Var(&metadata, "metadata", "only select endpoints with this `key=value` metadata (repeatable)")
+	fs.Var(&filterTypes, "filter-type", "only select endpoints subscribed to this event type (repeatable)")
+	state := fs.String("state", "", "only select enabled or disabled endpoints")
+	var since timeFlag
+	fs.Var(&since, "since", "for recover, resend the messages since this time (RFC 3339)")
+	dryRun := fs.Bool("dry-run", false, "list the endpoints that would be changed")
+	concurrency := fs.Int("concurrency", 4, "number of endpoints processed concurrently")
+	rate := fs.Float64("rate", 0, "maximum number of endpoints processed per second (default no limit)")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix bulk <action> [arguments] [flags]\n\nApplies an action to the endpoints matching all the selection flags, across\napplications, and reports the result for each of them.\n\nActions:\n")
+		for _, a := range bulkActions {
+			fmt.Fprintf(c.stderr, "  %-36s %s\n", strings.TrimSpace(a.name+" "+a.args), a.help)
+		}
+		fmt.Fprintf(c.stderr, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var action bulk.Action
+	name, rest := positional[0], positional[1:]
+	switch name {
+	case "add-filter-types", "remove-filter-types":
+		if len(rest) == 0 {
+			fs.Usage()
+			return errUsage
+		}
+		if name == "add-filter-types" {
+			action = bulk.AddFilterTypes(rest...)
+		} else {
+			action = bulk.RemoveFilterTypes(rest...)
+		}
+		rest = nil
+	case "disable":
+		action = bulk.Disable()
+	case "enable":
+		action = bulk.Enable()
+	case "rotate-secret":
+		action = bulk.RotateSecret()
+	case "delete":
+		action = bulk.Delete()
+	case "recover":
+		if since.t == nil {
+			return errors.New("recover needs --since")
+		}
+		action = bulk.Recover(*since.t)
+	default:
+		fmt.Fprintf(c.stderr, "svix: unknown bulk action %q\n", name)
+		fs.Usage()
+		return errUsage
+	}
+	if len(rest) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	sel.Apps = apps
+	sel.Hosts = hosts
+	sel.FilterTypes = filterTypes
+	for _, kv := range metadata {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("invalid --metadata %q: expected key=value", kv)
+		}
+		if sel.Metadata == nil {
+			sel.Metadata = map[string]string{}
+		}
+		sel.Metadata[k] = v
+	}
+	switch *state {
+	case "":
+	case "enabled", "disabled":
+		disabled := *state == "disabled"
+		sel.Disabled = &disabled
+	default:
+		return fmt.Errorf("invalid --state %q: expected enabled or disabled", *state)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	targets, err := bulk.Select(ctx, client, &sel)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(c.stdout, "No endpoints selected.")
+		return nil
+	}
+	if !*dryRun && !*yes {
+		fmt.Fprintf(c.stderr, "About to %s %d endpoints. Continue? [y/N] ", action.Name, len(targets))
+		answer, _ := bufio.NewReader(c.stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return errors.New("aborted")
+		}
+	}
+
+	report := bulk.Run(ctx, client, targets, action, &bulk.Options{
+		Concurrency: *concurrency,
+		RateLimit:   *rate,
+		DryRun:      *dryRun,
+		OnResult: func(r bulk.Result) {
+			status := "ok"
+			switch {
+			case r.Err != nil:
+				status = "FAIL"
+			case r.Skipped:
+				status = "skip"
+			case r.DryRun:
+				status = "would"
+			}
+			fmt.Fprintf(c.stdout, "%-5s %s %s %s", status, r.Target.AppId, r.Target.Endpoint.Id, r.Target.Endpoint.Url)
+			if r.Err != nil {
+				fmt.Fprintf(c.stdout, ": %s", formatError(r.Err))
+			}
+			fmt.Fprintln(c.stdout)
+		},
+	})
+	verb := "changed"
+	if *dryRun {
+		verb = "would be changed"
+	}
+	failed := len(report.Failed())
+	fmt.Fprintf(c.stdout, "\n%d %s, %d skipped, %d failed.\n", len(report.Succeeded()), verb, len(report.Skipped()), failed)
+	if failed != 0 {
+		return fmt.Errorf("%s failed for %d endpoints", action.Name, failed)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestBulk(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{"https://churned.example.com", "https://active.example.com"} {
+		if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: u}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := map[string]string{"SVIX_AUTH_TOKEN": "testsk_cli", "SVIX_SERVER_URL": srv.URL().String()}
+
+	out, err := runCLI(t, env, "", "bulk", "disable", "--host", "churned.example.com", "--dry-run")
+	if err != nil || !strings.HasPrefix(out, "would ") || !strings.HasSuffix(out, "\n1 would be changed, 0 skipped, 0 failed.\n") {
+		t.Fatalf("unexpected dry run (%v):\n%s", err, out)
+	}
+	if _, err := runCLI(t, env, "n\n", "bulk", "disable", "--host", "churned.example.com"); err == nil || err.Error() != "aborted" {
+		t.Fatalf("expected the action to be aborted, got %v", err)
+	}
+	out, err = runCLI(t, env, "", "bulk", "disable", "--host", "churned.example.com", "--yes")
+	if err != nil || !strings.HasSuffix(out, "\n1 changed, 0 skipped, 0 failed.\n") {
+		t.Fatalf("unexpected output (%v):\n%s", err, out)
+	}
+	out, err = runCLI(t, env, "", "bulk", "delete", "--state", "disabled", "--yes")
+	if err != nil || !strings.Contains(out, "https://churned.example.com") {
+		t.Fatalf("unexpected output (%v):\n%s", err, out)
+	}
+	eps, err := client.Endpoint.List(ctx, app.Id, nil)
+	if err != nil || len(eps.Data) != 1 || eps.Data[0].Url != "https://active.example.com" {
+		t.Fatalf("unexpected endpoints %+v (%v)", eps, err)
+	}
+}
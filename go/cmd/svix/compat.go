@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/backup"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/reconcile"
+	"github.com/svix/svix-webhooks/go/schemacompat"
+)
+
+var compatCommand = &command{
+	name: "compat",
+	help: "Check that event type schema changes won't break consumers",
+	run:  (*cli).compat,
+}
+
+// compatResult is the comparison of the schema of an event type with the
+// existing one.
+type compatResult struct {
+	EventType       string `json:"eventType"`
+	ExistingVersion string `json:"existingVersion,omitempty"`
+	ProposedVersion string `json:"proposedVersion,omitempty"`
+	// Whether the event type doesn't exist yet, in which case there is no
+	// report.
+	New    bool                 `json:"new"`
+	Report *schemacompat.Report `json:"report,omitempty"`
+	Ok     bool                 `json:"ok"`
+}
+
+func (c *cli) compat(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix compat", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	base := fs.String("base", "", "compare with the event types of this file instead of the live ones")
+	version := fs.String("version", "", "existing schema version to compare with (default the latest)")
+	require := fs.String("require", string(schemacompat.Forward), "required compatibility: forward, backward, full or breaking")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix compat <file> [flags]\n\nCompares the latest schema of each event type of a desired-state file or\nbackup archive (or - for stdin) with the existing schema, lists the changes,\nand fails if they aren't compatible enough.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	required, err := schemacompat.ParseCompatibility(*require)
+	if err != nil {
+		return err
+	}
+	data, err := c.readInput(positional[0])
+	if err != nil {
+		return err
+	}
+	proposed, err := loadSchemas(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", positional[0], err)
+	}
+
+	var existing map[string]map[string]map[string]interface{}
+	if *base != "" {
+		data, err := os.ReadFile(*base)
+		if err != nil {
+			return err
+		}
+		baseSchemas, err := loadSchemas(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *base, err)
+		}
+		existing = baseSchemas.schemas
+	} else {
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+		if existing, err = fetchSchemas(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	var results []compatResult
+	failed := 0
+	for _, et := range proposed.order {
+		result := compatResult{EventType: et, ProposedVersion: svix.LatestSchemaVersion(proposed.schemas[et]), Ok: true}
+		schemas, ok := existing[et]
+		if !ok {
+			result.New = true
+			results = append(results, result)
+			continue
+		}
+		result.ExistingVersion = *version
+		if result.ExistingVersion == "" {
+			result.ExistingVersion = svix.LatestSchemaVersion(schemas)
+		}
+		var existingSchema, proposedSchema interface{}
+		if s, ok := schemas[result.ExistingVersion]; ok {
+			existingSchema = s
+		}
+		if s, ok := proposed.schemas[et][result.ProposedVersion]; ok {
+			proposedSchema = s
+		}
+		result.Report, err = schemacompat.Compare(existingSchema, proposedSchema)
+		if err != nil {
+			return fmt.Errorf("%s: %w", et, err)
+		}
+		if !result.Report.Compatibility.Satisfies(required) {
+			result.Ok = false
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if c.output != "" {
+		if err := c.print(results, nil); err != nil {
+			return err
+		}
+	} else {
+		c.printCompat(results)
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d event types have changes that aren't %s compatible", failed, required)
+	}
+	return nil
+}
+
+func (c *cli) printCompat(results []compatResult) {
+	if len(results) == 0 {
+		fmt.Fprintln(c.stdout, "No event types.")
+		return
+	}
+	describe := func(version string) string {
+		if version == "" {
+			return "no schema"
+		}
+		return "version " + version
+	}
+	for _, r := range results {
+		if r.New {
+			fmt.Fprintf(c.stdout, "%s: new event type\n", r.EventType)
+			continue
+		}
+		versions := describe(r.ExistingVersion)
+		if r.ProposedVersion != r.ExistingVersion {
+			versions += " -> " + describe(r.ProposedVersion)
+		}
+		fmt.Fprintf(c.stdout, "%s: %s (%s)\n", r.EventType, r.Report.Compatibility, versions)
+		for _, change := range r.Report.Changes {
+			fmt.Fprintf(c.stdout, "  %s\n", change)
+		}
+	}
+}
+
+// eventTypeSchemas are the schemas of event types, by name and version.
+type eventTypeSchemas struct {
+	order   []string
+	schemas map[string]map[string]map[string]interface{}
+}
+
+// loadSchemas reads the schemas of the event types that aren't archived
+// from a backup archive or a desired-state file.
+func loadSchemas(data []byte) (*eventTypeSchemas, error) {
+	out := &eventTypeSchemas{schemas: map[string]map[string]map[string]interface{}{}}
+	add := func(name string, archived bool, schemas map[string]map[string]interface{}) {
+		if archived {
+			return
+		}
+		out.order = append(out.order, name)
+		out.schemas[name] = schemas
+	}
+	if archive, err := backup.Read(bytes.NewReader(data)); err == nil {
+		for _, et := range archive.EventTypes {
+			add(et.Name, et.Archived, et.Schemas)
+		}
+		return out, nil
+	}
+	state, err := reconcile.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("neither a backup archive nor a state file: %w", err)
+	}
+	for _, et := range state.EventTypes {
+		add(et.Name, et.Archived, et.Schemas)
+	}
+	return out, nil
+}
+
+// fetchSchemas lists the schemas of all the event types, archived or not.
+func fetchSchemas(ctx context.Context, client *svix.Svix) (map[string]map[string]map[string]interface{}, error) {
+	schemas := map[string]map[string]map[string]interface{}{}
+	withContent, includeArchived := true, true
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := client.EventType.List(ctx, &svix.EventTypeListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize), WithContent: &withContent, IncludeArchived: &includeArchived})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, et := range out.Data {
+			schemas[et.Name] = et.Schemas
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
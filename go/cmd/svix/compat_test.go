@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestCompat(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	_, err := srv.Client().EventType.Create(context.Background(), &svix.EventTypeIn{
+		Name:        "invoice.paid",
+		Description: "An invoice was paid",
+		Schemas: map[string]map[string]interface{}{
+			"1": {"type": "object", "properties": map[string]interface{}{
+				"status": map[string]interface{}{"enum": []interface{}{"paid", "refunded"}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"SVIX_AUTH_TOKEN": "testsk_cli", "SVIX_SERVER_URL": srv.URL().String()}
+
+	state := filepath.Join(t.TempDir(), "svix.yaml")
+	err = os.WriteFile(state, []byte(`
+eventTypes:
+  - name: invoice.paid
+    description: An invoice was paid
+    schemas:
+      "2":
+        type: object
+        properties:
+          status:
+            enum: [paid]
+          note:
+            type: string
+  - name: user.signup
+    description: A user signed up
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := runCLI(t, env, "", "compat", state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `invoice.paid: forward (version 1 -> version 2)
+  /properties/note: optional property "note" added (full)
+  /properties/status: enum value "refunded" removed (forward)
+user.signup: new event type
+`
+	if out != expected {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	out, err = runCLI(t, env, "", "compat", state, "--require", "full", "-o", "json")
+	if err == nil || !strings.Contains(err.Error(), "1 event types have changes that aren't full compatible") {
+		t.Errorf("expected the check to fail, got %v", err)
+	}
+	if !strings.Contains(out, `"ok": false`) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type config struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*profile `yaml:"profiles"`
+}
+
+type profile struct {
+	Token     string `yaml:"token"`
+	ServerUrl string `yaml:"server_url"`
+}
+
+// loadConfig reads the configuration file. A missing file is only an error
+// when its path was given explicitly.
+func (c *cli) loadConfig() (*config, error) {
+	path := firstNonEmpty(c.configPath, c.getenv("SVIX_CONFIG"))
+	explicit := path != ""
+	if !explicit {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return &config{}, nil
+		}
+		path = filepath.Join(dir, "svix", "config.yaml")
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration: %w", err)
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing configuration %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// resolveProfile returns the selected profile, or an empty one if none is
+// configured.
+func (c *cli) resolveProfile() (*profile, error) {
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := firstNonEmpty(c.profile, c.getenv("SVIX_PROFILE"))
+	explicit := name != ""
+	if !explicit {
+		name = firstNonEmpty(cfg.DefaultProfile, "default")
+	}
+	if p, ok := cfg.Profiles[name]; ok && p != nil {
+		return p, nil
+	}
+	if explicit || cfg.DefaultProfile != "" {
+		return nil, fmt.Errorf("profile %q isn't configured", name)
+	}
+	return &profile{}, nil
+}
+
+func parseServerUrl(s string) (*url.URL, error) {
+	if s == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", s)
+	}
+	return u, nil
+}
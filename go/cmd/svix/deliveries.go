@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/deliveries"
+)
+
+var deliveriesCommand = &command{
+	name: "deliveries",
+	help: "Find failed deliveries and resend them",
+	run:  (*cli).deliveries,
+}
+
+func (c *cli) deliveries(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix deliveries", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	var apps, endpoints, eventTypes stringsFlag
+	fs.Var(&apps, "app", "application id or uid to scan (repeatable, default all)")
+	fs.Var(&endpoints, "endpoint", "endpoint id or uid to scan, with a single --app (repeatable, default all)")
+	fs.Var(&eventTypes, "event-type", "only consider messages of this event type (repeatable)")
+	channel := fs.String("channel", "", "only consider messages of this channel")
+	var after, before timeFlag
+	fs.Var(&after, "after", "only consider attempts after this time (RFC 3339)")
+	fs.Var(&before, "before", "only consider attempts before this time (RFC 3339)")
+	since := fs.Duration("since", 0, "only consider attempts in this last duration, e.g. 24h")
+	class := fs.String("status-code-class", "", "only report failures with a status code of this class: 1xx to 5xx, or none")
+	verbose := fs.Bool("v", false, "list the failures of each group")
+	var groups stringsFlag
+	fs.Var(&groups, "group", "for resend, only resend the failures of this group number (repeatable)")
+	dryRun := fs.Bool("dry-run", false, "for resend, list the messages that would be resent")
+	concurrency := fs.Int("concurrency", 4, "for resend, number of messages resent concurrently")
+	yes := fs.Bool("yes", false, "for resend, don't ask for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix deliveries scan [flags]\n       svix deliveries resend [flags]\n\n")
+		fmt.Fprintf(c.stderr, "scan lists the messages whose last delivery attempt failed, grouped by\nresponse, and resend resends them (or those of the groups given by --group).\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || (positional[0] != "scan" && positional[0] != "resend") {
+		fs.Usage()
+		return errUsage
+	}
+
+	q := &deliveries.Query{
+		Apps:       apps,
+		Endpoints:  endpoints,
+		EventTypes: eventTypes,
+		Channel:    *channel,
+	}
+	if after.t != nil {
+		q.After = *after.t
+	}
+	if *since != 0 {
+		q.After = time.Now().Add(-*since)
+	}
+	if before.t != nil {
+		q.Before = *before.t
+	}
+	if *class != "" {
+		codeClass, err := parseStatusCodeClass(*class)
+		if err != nil {
+			return err
+		}
+		q.StatusCodeClass = &codeClass
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	failures, err := deliveries.Scan(ctx, client, q)
+	if err != nil {
+		return err
+	}
+	grouped := deliveries.GroupFailures(failures)
+
+	if positional[0] == "scan" {
+		if c.output != "" {
+			return c.print(grouped, nil)
+		}
+		c.printGroups(grouped, *verbose)
+		return nil
+	}
+
+	if len(groups) != 0 {
+		failures = nil
+		for _, g := range groups {
+			n, err := strconv.Atoi(g)
+			if err != nil || n < 1 || n > len(grouped) {
+				return fmt.Errorf("invalid --group %q: there are %d groups", g, len(grouped))
+			}
+			failures = append(failures, grouped[n-1].Failures...)
+		}
+	}
+	if len(failures) == 0 {
+		fmt.Fprintln(c.stdout, "No failed deliveries.")
+		return nil
+	}
+	if !*dryRun && !*yes {
+		fmt.Fprintf(c.stderr, "About to resend %d messages. Continue? [y/N] ", len(failures))
+		answer, _ := bufio.NewReader(c.stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return errors.New("aborted")
+		}
+	}
+	failed := 0
+	results := deliveries.Resend(ctx, client, failures, &deliveries.ResendOptions{
+		Concurrency: *concurrency,
+		DryRun:      *dryRun,
+		OnResult: func(r deliveries.ResendResult) {
+			status := "ok"
+			switch {
+			case r.Err != nil:
+				status = "FAIL"
+				failed++
+			case r.DryRun:
+				status = "would"
+			}
+			fmt.Fprintf(c.stdout, "%-5s %s %s %s", status, r.Failure.AppId, r.Failure.MsgId, r.Failure.EndpointId)
+			if r.Err != nil {
+				fmt.Fprintf(c.stdout, ": %s", formatError(r.Err))
+			}
+			fmt.Fprintln(c.stdout)
+		},
+	})
+	verb := "resent"
+	if *dryRun {
+		verb = "would be resent"
+	}
+	fmt.Fprintf(c.stdout, "\n%d %s, %d failed.\n", len(results)-failed, verb, failed)
+	if failed != 0 {
+		return fmt.Errorf("failed to resend %d messages", failed)
+	}
+	return nil
+}
+
+func (c *cli) printGroups(groups []*deliveries.Group, verbose bool) {
+	if len(groups) == 0 {
+		fmt.Fprintln(c.stdout, "No failed deliveries.")
+		return
+	}
+	for i, g := range groups {
+		status := "no response"
+		if g.StatusCode != 0 {
+			status = "HTTP " + strconv.Itoa(int(g.StatusCode))
+		}
+		fmt.Fprintf(c.stdout, "#%d  %d failed  %s  %s\n", i+1, len(g.Failures), status, strconv.Quote(g.Response))
+		if verbose {
+			for _, f := range g.Failures {
+				fmt.Fprintf(c.stdout, "      %s  %s %s %s  %s\n", f.Timestamp.Format(time.RFC3339), f.AppId, f.MsgId, f.EndpointId, f.Url)
+			}
+		}
+	}
+}
+
+func parseStatusCodeClass(s string) (svix.StatusCodeClass, error) {
+	if s == "none" {
+		return svix.StatusCodeClass(0), nil
+	}
+	if len(s) == 3 && s[0] >= '1' && s[0] <= '5' && strings.ToLower(s[1:]) == "xx" {
+		return svix.StatusCodeClass(int32(s[0]-'0') * 100), nil
+	}
+	return 0, fmt.Errorf("invalid status code class %q: expected 1xx to 5xx, or none", s)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestDeliveries(t *testing.T) {
+	var healthy atomic.Bool
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			if healthy.Load() {
+				return 200, ""
+			}
+			return 500, "database is down"
+		},
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{EventType: "user.signup", Payload: map[string]interface{}{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.WaitForDeliveries()
+	env := map[string]string{"SVIX_AUTH_TOKEN": "testsk_cli", "SVIX_SERVER_URL": srv.URL().String()}
+
+	out, err := runCLI(t, env, "", "deliveries", "scan", "--status-code-class", "5xx")
+	if err != nil || out != "#1  2 failed  HTTP 500  \"database is down\"\n" {
+		t.Fatalf("unexpected scan (%v):\n%s", err, out)
+	}
+	if out, err := runCLI(t, env, "", "deliveries", "scan", "--status-code-class", "4xx"); err != nil || out != "No failed deliveries.\n" {
+		t.Fatalf("unexpected scan (%v):\n%s", err, out)
+	}
+	if _, err := runCLI(t, env, "", "deliveries", "resend", "--group", "2", "--yes"); err == nil {
+		t.Fatal("expected an invalid group to be refused")
+	}
+
+	healthy.Store(true)
+	out, err = runCLI(t, env, "", "deliveries", "resend", "--group", "1", "--yes")
+	if err != nil || !strings.HasSuffix(out, "\n2 resent, 0 failed.\n") {
+		t.Fatalf("unexpected resend (%v):\n%s", err, out)
+	}
+	srv.WaitForDeliveries()
+	if out, err := runCLI(t, env, "", "deliveries", "scan"); err != nil || out != "No failed deliveries.\n" {
+		t.Fatalf("unexpected scan (%v):\n%s", err, out)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+// listFlags are the pagination flags shared by the list actions.
+type listFlags struct {
+	iterator string
+	limit    int
+	all      bool
+}
+
+func addListFlags(fs *flag.FlagSet) *listFlags {
+	l := &listFlags{}
+	fs.StringVar(&l.iterator, "iterator", "", "iterator returned by the previous page")
+	fs.IntVar(&l.limit, "limit", 0, "maximum number of items per page")
+	fs.BoolVar(&l.all, "all", false, "fetch every page")
+	return l
+}
+
+func (l *listFlags) limitPtr() *int32 {
+	if l.limit <= 0 {
+		return nil
+	}
+	return svix.Int32(int32(l.limit))
+}
+
+type page[T any] struct {
+	Data     []T     `json:"data"`
+	Done     bool    `json:"done"`
+	Iterator *string `json:"iterator,omitempty"`
+}
+
+// list calls fetch for a single page, or for every page with --all, and
+// returns the items.
+func list[T any](l *listFlags, fetch func(iterator *string, limit *int32) ([]T, *string, bool, error)) (interface{}, error) {
+	var iterator *string
+	if l.iterator != "" {
+		iterator = svix.String(l.iterator)
+	}
+	result := &page[T]{Data: []T{}}
+	for {
+		data, next, done, err := fetch(iterator, l.limitPtr())
+		if err != nil {
+			return nil, err
+		}
+		result.Data = append(result.Data, data...)
+		result.Done = done
+		result.Iterator = next
+		if !l.all || done || next == nil {
+			return result, nil
+		}
+		iterator = next
+	}
+}
+
+// stringsFlag is a flag that can be repeated.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func (s stringsFlag) ptr() *[]string {
+	if len(s) == 0 {
+		return nil
+	}
+	return (*[]string)(&s)
+}
+
+// timeFlag is an RFC 3339 timestamp.
+type timeFlag struct{ t *time.Time }
+
+func (f *timeFlag) String() string {
+	if f.t == nil {
+		return ""
+	}
+	return f.t.Format(time.RFC3339)
+}
+
+func (f *timeFlag) Set(v string) error {
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return fmt.Errorf("expected an RFC 3339 timestamp")
+	}
+	f.t = &t
+	return nil
+}
+
+// orderFlag is a listing order.
+type orderFlag struct{ order *svix.Ordering }
+
+func (f *orderFlag) String() string {
+	if f.order == nil {
+		return ""
+	}
+	return string(*f.order)
+}
+
+func (f *orderFlag) Set(v string) error {
+	order, err := openapi.NewOrderingFromValue(v)
+	if err != nil {
+		return fmt.Errorf("expected ascending or descending")
+	}
+	o := svix.Ordering(*order)
+	f.order = &o
+	return nil
+}
+
+var messageStatuses = map[string]openapi.MessageStatus{
+	"success": openapi.MESSAGESTATUS_Success,
+	"pending": openapi.MESSAGESTATUS_Pending,
+	"fail":    openapi.MESSAGESTATUS_Fail,
+	"sending": openapi.MESSAGESTATUS_Sending,
+}
+
+// statusFlag is a message status, by name.
+type statusFlag struct{ status *svix.MessageStatus }
+
+func (f *statusFlag) String() string {
+	if f.status == nil {
+		return ""
+	}
+	for name, status := range messageStatuses {
+		if svix.MessageStatus(status) == *f.status {
+			return name
+		}
+	}
+	return ""
+}
+
+func (f *statusFlag) Set(v string) error {
+	status, ok := messageStatuses[v]
+	if !ok {
+		return fmt.Errorf("expected success, pending, fail or sending")
+	}
+	s := svix.MessageStatus(status)
+	f.status = &s
+	return nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/relay"
+)
+
+var listenCommand = &command{
+	name: "listen",
+	help: "Relay the webhooks of an application to a local URL",
+	run:  (*cli).listen,
+}
+
+// listenSession is the state of a running listen command.
+type listenSession struct {
+	c        *cli
+	client   *svix.Svix
+	appId    string
+	endpoint *svix.EndpointOut
+	webhook  *svix.Webhook
+
+	mu       sync.Mutex
+	received []string // ids of the messages received, in order
+}
+
+func (c *cli) listen(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix listen", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	relayURL := fs.String("relay-url", "", "URL of the relay server, ws:// or wss:// (default $SVIX_RELAY_URL)")
+	relayToken := fs.String("relay-token", "", "token of a previous session, to keep its URL and endpoint")
+	relayKey := fs.String("relay-key", "", "key of the previous session given with --relay-token (default $SVIX_RELAY_KEY)")
+	keep := fs.Bool("keep", false, "keep the endpoint when exiting")
+	var eventTypes stringsFlag
+	fs.Var(&eventTypes, "event-type", "only relay messages of this event type (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix listen <app-id> <local-url> [flags]\n\n")
+		fmt.Fprintf(c.stderr, "Creates an endpoint in the application that relays its messages to the local URL,\n")
+		fmt.Fprintf(c.stderr, "verifying their signature. Type r and Enter to resend the last message, r N to\n")
+		fmt.Fprintf(c.stderr, "resend the Nth one, and q to quit.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return errUsage
+	}
+	appId := positional[0]
+	target, err := url.Parse(positional[1])
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return fmt.Errorf("invalid local URL %q", positional[1])
+	}
+	*relayURL = firstNonEmpty(*relayURL, c.getenv("SVIX_RELAY_URL"))
+	if *relayURL == "" {
+		return errors.New("no relay server: pass --relay-url or set SVIX_RELAY_URL")
+	}
+	*relayKey = firstNonEmpty(*relayKey, c.getenv("SVIX_RELAY_KEY"))
+	if *relayToken != "" && *relayKey == "" {
+		return errors.New("resuming a session requires its key: pass --relay-key or set SVIX_RELAY_KEY")
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	l, err := relay.Dial(ctx, *relayURL, *relayToken, *relayKey)
+	if err != nil {
+		return fmt.Errorf("connecting to the relay: %w", err)
+	}
+	defer l.Close()
+
+	s := &listenSession{c: c, client: client, appId: appId}
+	created, err := s.setupEndpoint(ctx, l, eventTypes)
+	if err != nil {
+		return err
+	}
+	if !*keep {
+		defer func() {
+			// ctx is likely done by now.
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := client.Endpoint.Delete(ctx, appId, s.endpoint.Id); err != nil {
+				fmt.Fprintf(c.stderr, "svix: deleting endpoint %s: %s\n", s.endpoint.Id, formatError(err))
+				return
+			}
+			fmt.Fprintf(c.stdout, "Deleted endpoint %s\n", s.endpoint.Id)
+		}()
+	}
+	verb := "Reusing"
+	if created {
+		verb = "Created"
+	}
+	s.printf("%s endpoint %s (%s)\nRelaying its messages to %s\nPass --relay-token %s with --relay-key %s to keep this URL in later sessions.\nType r to resend the last message, r N to resend the Nth one, q to quit.\n",
+		verb, s.endpoint.Id, l.URL(), target, l.Token(), l.Key())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.readCommands(ctx, cancel)
+	err = l.Serve(ctx, s.handler(relay.Forward(target, nil)))
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// setupEndpoint creates the endpoint pointing to the relay, or updates the
+// one of a previous session, and reports whether it was created.
+func (s *listenSession) setupEndpoint(ctx context.Context, l *relay.Listener, eventTypes stringsFlag) (bool, error) {
+	ep, err := s.findEndpoint(ctx, l.URL())
+	created := false
+	switch {
+	case err == nil && ep == nil:
+		ep, err = s.client.Endpoint.Create(ctx, s.appId, &svix.EndpointIn{
+			Url:         l.URL(),
+			Description: svix.String("svix listen"),
+			FilterTypes: eventTypes,
+		})
+		created = true
+	case err == nil:
+		ep, err = s.client.Endpoint.Update(ctx, s.appId, ep.Id, &svix.EndpointUpdate{
+			Url:         l.URL(),
+			Description: svix.String("svix listen"),
+			FilterTypes: eventTypes,
+		})
+	}
+	if err != nil {
+		return false, fmt.Errorf("setting up the endpoint: %s", formatError(err))
+	}
+	secret, err := s.client.Endpoint.GetSecret(ctx, s.appId, ep.Id)
+	if err != nil {
+		return false, fmt.Errorf("getting the endpoint secret: %s", formatError(err))
+	}
+	s.webhook, err = svix.NewWebhook(secret.Key)
+	if err != nil {
+		return false, err
+	}
+	s.endpoint = ep
+	return created, nil
+}
+
+// findEndpoint returns the endpoint of the application with the given URL,
+// or nil if there is none.
+func (s *listenSession) findEndpoint(ctx context.Context, endpointURL string) (*svix.EndpointOut, error) {
+	var found *svix.EndpointOut
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := s.client.Endpoint.List(ctx, s.appId, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, ep := range out.Data {
+			if ep.Url == endpointURL {
+				ep := svix.EndpointOut(ep)
+				found = &ep
+				return nil, true, nil
+			}
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	return found, err
+}
+
+// handler prints and verifies the relayed messages before passing them to
+// next.
+func (s *listenSession) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		verification := "signature verified"
+		if err := s.webhook.Verify(body, r.Header); err != nil {
+			verification = "signature INVALID: " + err.Error()
+		}
+		msgId := r.Header.Get("svix-id")
+		if msgId == "" {
+			msgId = r.Header.Get("webhook-id")
+		}
+		s.mu.Lock()
+		s.received = append(s.received, msgId)
+		n := len(s.received)
+		s.mu.Unlock()
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		var payload bytes.Buffer
+		if json.Compact(&payload, body) != nil {
+			payload.Reset()
+			payload.Write(body)
+		}
+		s.printf("[%d] %s %s -> %d %s in %s (%s)\n    %s\n",
+			n, start.Format("15:04:05"), msgId, rec.status, http.StatusText(rec.status),
+			time.Since(start).Round(time.Millisecond), verification, payload.String())
+	})
+}
+
+// readCommands handles the commands typed on stdin.
+func (s *listenSession) readCommands(ctx context.Context, quit func()) {
+	scanner := bufio.NewScanner(s.c.stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "q", "quit":
+			quit()
+			return
+		case "r", "resend":
+			s.resend(ctx, fields[1:])
+		default:
+			s.printf("Unknown command %q: type r to resend the last message, r N to resend the Nth one, q to quit.\n", fields[0])
+		}
+	}
+}
+
+func (s *listenSession) resend(ctx context.Context, args []string) {
+	s.mu.Lock()
+	received := append([]string(nil), s.received...)
+	s.mu.Unlock()
+	if len(received) == 0 {
+		s.printf("No message received yet.\n")
+		return
+	}
+	n := len(received)
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 || n > len(received) {
+			s.printf("There is no message %s.\n", args[0])
+			return
+		}
+	}
+	msgId := received[n-1]
+	if err := s.client.MessageAttempt.Resend(ctx, s.appId, msgId, s.endpoint.Id); err != nil {
+		s.printf("Resending %s failed: %s\n", msgId, formatError(err))
+		return
+	}
+	s.printf("Resending %s\n", msgId)
+}
+
+func (s *listenSession) printf(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.c.stdout, format, args...)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
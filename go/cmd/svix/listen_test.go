@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/relay"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestListen(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{Deliver: svixtest.HTTPDelivery(nil)})
+	defer srv.Close()
+	relaySrv := httptest.NewServer(&relay.Server{})
+	defer relaySrv.Close()
+
+	received := make(chan string, 10)
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer local.Close()
+
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, typed := io.Pipe()
+	stdout := &lockedBuffer{}
+	c := &cli{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: io.Discard,
+		getenv: env{
+			"SVIX_AUTH_TOKEN": "testsk_listen",
+			"SVIX_SERVER_URL": srv.URL().String(),
+			"SVIX_RELAY_URL":  "ws" + strings.TrimPrefix(relaySrv.URL, "http"),
+		}.get,
+	}
+	done := make(chan error, 1)
+	go func() { done <- c.run(ctx, []string{"listen", app.Id, local.URL + "/webhooks"}) }()
+
+	waitFor(t, "the endpoint", func() bool {
+		eps, err := client.Endpoint.List(ctx, app.Id, nil)
+		return err == nil && len(eps.Data) == 1
+	})
+	if _, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{
+		EventType: "user.signup",
+		EventId:   *svix.NullableString(svix.String("evt_listen")),
+		Payload:   map[string]interface{}{"id": "u_1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if body := <-received; body != `{"id":"u_1"}` {
+		t.Errorf("unexpected body relayed: %s", body)
+	}
+	waitFor(t, "the event to be printed", func() bool { return strings.Contains(stdout.String(), "[1]") })
+	if out := stdout.String(); !strings.Contains(out, "-> 204 No Content") || !strings.Contains(out, "signature verified") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	typed.Write([]byte("r\n"))
+	if body := <-received; body != `{"id":"u_1"}` {
+		t.Errorf("unexpected body resent: %s", body)
+	}
+	typed.Write([]byte("q\n"))
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	eps, err := client.Endpoint.List(ctx, app.Id, nil)
+	if err != nil || len(eps.Data) != 0 {
+		t.Errorf("expected the endpoint to be deleted, got %v (%v)", eps, err)
+	}
+}
+
+func TestListenHandlerUnbrandedHeaders(t *testing.T) {
+	wh, err := svix.NewWebhook("whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := &lockedBuffer{}
+	s := &listenSession{c: &cli{stdout: stdout}, webhook: wh}
+	h := s.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := `{"id":"u_1"}`
+	ts := time.Now()
+	sig, err := wh.Sign("msg_unbranded", ts, []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("webhook-id", "msg_unbranded")
+	r.Header.Set("webhook-timestamp", strconv.FormatInt(ts.Unix(), 10))
+	r.Header.Set("webhook-signature", sig)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(s.received) != 1 || s.received[0] != "msg_unbranded" {
+		t.Errorf("unexpected ids received: %v", s.received)
+	}
+	if out := stdout.String(); !strings.Contains(out, "msg_unbranded -> 200") || !strings.Contains(out, "signature verified") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,321 @@
+// Command svix is a command-line client for the Svix API, built on the Go
+// library.
+//
+// Usage:
+//
+//	svix [flags] <resource> <action> [arguments] [flags]
+//
+// The resources are application, endpoint, event-type, message,
+// message-attempt, integration and authentication; run `svix <resource>` to
+// list the actions of one of them. Request bodies are read as JSON or YAML
+// from --data (a literal, `@file` or `-` for stdin) or from stdin when it
+// isn't a terminal.
+//
+// The listen command relays the webhooks of an application to a local
+// server, and the verify and sign commands help debugging signatures. The
+// plan and apply commands reconcile the event types, applications and
+// endpoints with a desired-state file (see package reconcile), and backup
+// and restore snapshot an environment (see package backup). The bulk
+// command changes many endpoints at once (see package bulk), the
+// deliveries command finds and resends failed deliveries (see package
+// deliveries), and the monitor command alerts on unhealthy endpoints (see
+// package monitor). The compat command fails when the event type schemas of
+// a desired-state file would break consumers (see package schemacompat),
+// for use in release pipelines.
+//
+// The token and server URL are taken, in order of precedence, from the
+// --token and --server-url flags, the SVIX_AUTH_TOKEN and SVIX_SERVER_URL
+// environment variables, or a profile of the configuration file
+// ($XDG_CONFIG_HOME/svix/config.yaml by default):
+//
+//	default_profile: staging
+//	profiles:
+//	  staging:
+//	    token: testsk_...
+//	    server_url: http://localhost:8071
+//	  production:
+//	    token: sk_...
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+// errUsage is returned for invalid invocations, after the usage has been
+// printed.
+var errUsage = errors.New("invalid usage")
+
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	// Global flags, accepted before the resource or after the action.
+	token      string
+	serverUrl  string
+	profile    string
+	configPath string
+	output     string
+}
+
+func main() {
+	c := &cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := c.run(ctx, os.Args[1:])
+	stop()
+	if err != nil {
+		if err != errUsage && err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "svix: %s\n", formatError(err))
+		}
+		os.Exit(1)
+	}
+}
+
+func (c *cli) globalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.token, "token", c.token, "API token (default $SVIX_AUTH_TOKEN or the profile's)")
+	fs.StringVar(&c.serverUrl, "server-url", c.serverUrl, "API server URL (default $SVIX_SERVER_URL or the profile's)")
+	fs.StringVar(&c.profile, "profile", c.profile, "configuration profile (default $SVIX_PROFILE or the file's default_profile)")
+	fs.StringVar(&c.configPath, "config", c.configPath, "configuration file (default $SVIX_CONFIG or $XDG_CONFIG_HOME/svix/config.yaml)")
+	fs.StringVar(&c.output, "o", c.output, "output format: json (the default for API results), yaml or table")
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix [flags] <resource> <action> [arguments] [flags]\n       svix [flags] <command> [arguments] [flags]\n\nCommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(c.stderr, "  %-16s %s\n", cmd.name, cmd.help)
+		}
+		fmt.Fprintf(c.stderr, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) == 0 || args[0] == "help" {
+		fs.Usage()
+		return errUsage
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(c.stderr, "svix: unknown command %q\n", args[0])
+		fs.Usage()
+		return errUsage
+	}
+	if cmd.run != nil {
+		return cmd.run(c, ctx, args[1:])
+	}
+	if len(args) == 1 || args[1] == "help" {
+		cmd.usage(c.stderr)
+		return errUsage
+	}
+	act := cmd.action(args[1])
+	if act == nil {
+		fmt.Fprintf(c.stderr, "svix: unknown action %q for %s\n", args[1], cmd.name)
+		cmd.usage(c.stderr)
+		return errUsage
+	}
+	return c.runAction(ctx, cmd, act, args[2:])
+}
+
+func (c *cli) runAction(ctx context.Context, cmd *command, act *action, args []string) error {
+	fs := flag.NewFlagSet("svix "+cmd.name+" "+act.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	var data string
+	if act.body != bodyNone {
+		fs.StringVar(&data, "data", "", "request body as JSON or YAML, `@file` or - for stdin")
+	}
+	run := act.setup(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix %s %s", cmd.name, act.name)
+		for _, arg := range act.args {
+			fmt.Fprintf(c.stderr, " <%s>", arg)
+		}
+		fmt.Fprintf(c.stderr, " [flags]\n\n%s\n\nFlags:\n", act.help)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != len(act.args) {
+		fmt.Fprintf(c.stderr, "svix: %s %s takes %d argument(s), got %d\n", cmd.name, act.name, len(act.args), len(positional))
+		fs.Usage()
+		return errUsage
+	}
+	switch c.output {
+	case "":
+		c.output = "json"
+	case "json", "yaml", "table":
+	default:
+		return fmt.Errorf("unknown output format %q", c.output)
+	}
+
+	var body []byte
+	if act.body != bodyNone {
+		body, err = c.readBody(data, act.body == bodyRequired)
+		if err != nil {
+			return err
+		}
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	result, err := run(ctx, client, positional, body)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return c.print(result, cmd.columns)
+}
+
+// parseInterspersed parses the flags in args, which may appear before,
+// between or after the positional arguments, and returns the latter.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// client builds the Svix client from the flags, environment and
+// configuration file.
+func (c *cli) client() (*svix.Svix, error) {
+	p, err := c.resolveProfile()
+	if err != nil {
+		return nil, err
+	}
+	token := firstNonEmpty(c.token, c.getenv("SVIX_AUTH_TOKEN"), p.Token)
+	if token == "" {
+		return nil, errors.New("no API token: pass --token, set SVIX_AUTH_TOKEN or configure a profile")
+	}
+	serverUrl, err := parseServerUrl(firstNonEmpty(c.serverUrl, c.getenv("SVIX_SERVER_URL"), p.ServerUrl))
+	if err != nil {
+		return nil, err
+	}
+	return svix.New(token, &svix.SvixOptions{ServerUrl: serverUrl}), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// formatError adds the details returned by the API to err.
+func formatError(err error) string {
+	var svixErr *svix.Error
+	if errors.As(err, &svixErr) && svixErr.Status() != 0 {
+		body := strings.TrimSpace(string(svixErr.Body()))
+		if body == "" {
+			return fmt.Sprintf("request failed with status %d", svixErr.Status())
+		}
+		return fmt.Sprintf("request failed with status %d: %s", svixErr.Status(), body)
+	}
+	return err.Error()
+}
+
+type bodyMode int
+
+const (
+	bodyNone bodyMode = iota
+	bodyOptional
+	bodyRequired
+)
+
+// runFunc executes an action with its positional arguments and request body,
+// returning the value to print, if any.
+type runFunc func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error)
+
+type action struct {
+	name string
+	args []string
+	body bodyMode
+	help string
+	// Registers the action's own flags and returns the function running it.
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+type command struct {
+	name    string
+	help    string
+	columns []string
+	actions []*action
+	// Runs the commands that don't have actions, with the arguments
+	// following their name.
+	run func(c *cli, ctx context.Context, args []string) error
+}
+
+func (cmd *command) action(name string) *action {
+	for _, act := range cmd.actions {
+		if act.name == name {
+			return act
+		}
+	}
+	return nil
+}
+
+func (cmd *command) usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: svix %s <action> [arguments] [flags]\n\nActions:\n", cmd.name)
+	actions := append([]*action(nil), cmd.actions...)
+	sort.Slice(actions, func(i, j int) bool { return actions[i].name < actions[j].name })
+	for _, act := range actions {
+		usage := act.name
+		for _, arg := range act.args {
+			usage += " <" + arg + ">"
+		}
+		fmt.Fprintf(w, "  %-40s %s\n", usage, act.help)
+	}
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// noFlags adapts a runFunc for an action without flags of its own.
+func noFlags(run runFunc) func(*flag.FlagSet) runFunc {
+	return func(*flag.FlagSet) runFunc { return run }
+}
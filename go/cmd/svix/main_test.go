@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+// runCLI runs the command with args, returning its standard output.
+func runCLI(t *testing.T, env map[string]string, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(key string) string { return env[key] },
+	}
+	err := c.run(context.Background(), args)
+	if err != nil {
+		t.Logf("stderr: %s", stderr.String())
+	}
+	return stdout.String(), err
+}
+
+func TestApplicationCommands(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	env := map[string]string{
+		"SVIX_AUTH_TOKEN": "testsk_cli",
+		"SVIX_SERVER_URL": srv.URL().String(),
+		"SVIX_CONFIG":     filepath.Join(t.TempDir(), "missing.yaml"),
+	}
+	// An explicitly configured file must exist.
+	if _, err := runCLI(t, env, "", "application", "list"); err == nil {
+		t.Fatal("expected a missing configuration file to fail")
+	}
+	delete(env, "SVIX_CONFIG")
+
+	out, err := runCLI(t, env, "", "application", "create", "--data", `{"name": "First", "uid": "first"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var app struct{ Id, Name, Uid string }
+	if err := json.Unmarshal([]byte(out), &app); err != nil || app.Name != "First" || app.Uid != "first" {
+		t.Fatalf("unexpected output %q (%v)", out, err)
+	}
+
+	// YAML bodies from stdin.
+	if _, err := runCLI(t, env, "name: Second\nuid: second\n", "application", "create"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = runCLI(t, env, "", "-o", "table", "application", "list", "--limit", "1", "--all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[2], "second") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+
+	out, err = runCLI(t, env, "", "application", "get", "first", "-o", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "name: First\n") || !strings.Contains(out, "id: "+app.Id) {
+		t.Errorf("unexpected yaml:\n%s", out)
+	}
+
+	if _, err := runCLI(t, env, "", "authentication", "expire-all", "first", "--data", `{"expiry": 60}`); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := runCLI(t, env, "", "application", "create", "--data", `{"nmae": "typo"}`); err == nil || !strings.Contains(err.Error(), "nmae") {
+		t.Errorf("expected unknown fields to be rejected, got %v", err)
+	}
+	_, err = runCLI(t, env, "", "application", "get", "missing")
+	if err == nil || !strings.Contains(formatError(err), "404") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{Token: "testsk_profile"})
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "default_profile: local\nprofiles:\n  local:\n    token: testsk_profile\n    server_url: " + srv.URL().String() + "\n  other:\n    token: testsk_wrong\n    server_url: " + srv.URL().String() + "\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"SVIX_CONFIG": path}
+
+	if _, err := runCLI(t, env, "", "application", "list"); err != nil {
+		t.Errorf("default profile: %v", err)
+	}
+	if _, err := runCLI(t, env, "", "--profile", "other", "application", "list"); err == nil {
+		t.Errorf("expected the other profile's token to be rejected")
+	}
+	if _, err := runCLI(t, env, "", "--profile", "other", "--token", "testsk_profile", "application", "list"); err != nil {
+		t.Errorf("--token should override the profile: %v", err)
+	}
+	if _, err := runCLI(t, env, "", "--profile", "nope", "application", "list"); err == nil {
+		t.Errorf("expected an unknown profile to fail")
+	}
+}
+
+type env map[string]string
+
+func (e env) get(key string) string { return e[key] }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/monitor"
+)
+
+var monitorCommand = &command{
+	name: "monitor",
+	help: "Watch the health of endpoints and alert on failures",
+	run:  (*cli).monitor,
+}
+
+func (c *cli) monitor(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix monitor", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	var apps, windows, webhooks stringsFlag
+	fs.Var(&apps, "app", "application id or uid to watch (repeatable, default all)")
+	fs.Var(&windows, "window", "window over which failure rates are computed (repeatable, default 15m and 1h)")
+	fs.Var(&webhooks, "webhook", "URL to post the alerts to as JSON (repeatable)")
+	interval := fs.Duration("interval", time.Minute, "time between polls")
+	failureThreshold := fs.Float64("failure-threshold", 0.5, "failure rate at or above which an endpoint is unhealthy")
+	recoveryThreshold := fs.Float64("recovery-threshold", 0.2, "failure rate below which an endpoint recovers")
+	minDeliveries := fs.Int64("min-deliveries", 10, "minimum number of deliveries in a window to judge it")
+	polls := fs.Int("polls", 2, "number of consecutive polls before a change is reported")
+	disableWarning := fs.Duration("disable-warning-after", 72*time.Hour, "warn about endpoints failing for this long")
+	once := fs.Bool("once", false, "print the health of the endpoints and exit")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix monitor [flags]\n\nPolls the statistics of endpoints and prints an alert when they become\nunhealthy, recover, or have been failing long enough to risk being disabled.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	opts := &monitor.Options{
+		Apps:                apps,
+		Interval:            *interval,
+		FailureThreshold:    *failureThreshold,
+		RecoveryThreshold:   *recoveryThreshold,
+		MinDeliveries:       *minDeliveries,
+		ConsecutivePolls:    *polls,
+		DisableWarningAfter: *disableWarning,
+		OnError: func(err error) {
+			fmt.Fprintf(c.stderr, "svix monitor: %s\n", formatError(err))
+		},
+	}
+	for _, w := range windows {
+		d, err := time.ParseDuration(w)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid --window %q", w)
+		}
+		opts.Windows = append(opts.Windows, d)
+	}
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	if *once {
+		// A single poll can't confirm a change over several polls.
+		opts.ConsecutivePolls = 1
+		m := monitor.New(client, opts)
+		if err := m.Poll(ctx); err != nil {
+			return err
+		}
+		if c.output != "" {
+			return c.print(m.Health(), nil)
+		}
+		c.printHealth(m.Health())
+		return nil
+	}
+
+	opts.Notifiers = []monitor.Notifier{monitor.LogNotifier(log.New(c.stdout, "", log.LstdFlags))}
+	for _, url := range webhooks {
+		opts.Notifiers = append(opts.Notifiers, monitor.WebhookNotifier(url, nil))
+	}
+	err = monitor.New(client, opts).Run(ctx)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func (c *cli) printHealth(health []monitor.EndpointHealth) {
+	if len(health) == 0 {
+		fmt.Fprintln(c.stdout, "No endpoints.")
+		return
+	}
+	for _, h := range health {
+		var rates []string
+		for _, w := range h.Windows {
+			rates = append(rates, fmt.Sprintf("%s %.0f%% (%d/%d)", w.Window, 100*w.FailureRate(), w.Fail, w.Success+w.Fail))
+		}
+		fmt.Fprintf(c.stdout, "%-9s %s %s %s  %s", h.Status, h.AppId, h.EndpointId, h.Url, strings.Join(rates, ", "))
+		if !h.FailingSince.IsZero() {
+			fmt.Fprintf(c.stdout, "  failing since %s", h.FailingSince.Format(time.RFC3339))
+		}
+		fmt.Fprintln(c.stdout)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestMonitor(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			if strings.Contains(d.Url, "down") {
+				return 500, ""
+			}
+			return 200, ""
+		},
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{"https://down.example.com", "https://up.example.com"} {
+		if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: u}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{EventType: "user.signup", Payload: map[string]interface{}{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.WaitForDeliveries()
+	env := map[string]string{"SVIX_AUTH_TOKEN": "testsk_cli", "SVIX_SERVER_URL": srv.URL().String()}
+
+	out, err := runCLI(t, env, "", "monitor", "--once", "--window", "1h", "--min-deliveries", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	for _, line := range lines {
+		down := strings.Contains(line, "https://down.example.com")
+		if down != strings.HasPrefix(line, "unhealthy ") || down != strings.Contains(line, "1h0m0s 100% (2/2)  failing since ") {
+			t.Errorf("unexpected line: %s", line)
+		}
+	}
+	if _, err := runCLI(t, env, "", "monitor", "--once", "--window", "soon"); err == nil {
+		t.Error("expected an invalid window to be refused")
+	}
+}
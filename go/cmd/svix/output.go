@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readBody returns the request body given by --data or stdin, converted to
+// JSON. YAML is accepted as well, being a superset of JSON.
+func (c *cli) readBody(data string, required bool) ([]byte, error) {
+	var raw []byte
+	var err error
+	switch {
+	case data == "-":
+		raw, err = io.ReadAll(c.stdin)
+	case strings.HasPrefix(data, "@"):
+		raw, err = os.ReadFile(data[1:])
+	case data != "":
+		raw = []byte(data)
+	case !isTerminal(c.stdin):
+		raw, err = io.ReadAll(c.stdin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if required {
+			return nil, errors.New("a request body is required: pass --data or pipe it on stdin")
+		}
+		return nil, nil
+	}
+	var v interface{}
+	if err := yaml.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("parsing request body: %w", err)
+	}
+	return json.Marshal(v)
+}
+
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// decodeBody decodes a JSON request body into v, rejecting unknown fields
+// so that typos don't go unnoticed.
+func decodeBody(body []byte, v interface{}) error {
+	if body == nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// print writes v in the selected output format. The table format prints a
+// row per item for lists, using columns when given, and a row per field
+// otherwise.
+func (c *cli) print(v interface{}, columns []string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if c.output == "json" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := c.stdout.Write(buf.Bytes())
+		return err
+	}
+
+	// JSON is valid YAML, and decoding it as a node keeps the order of the
+	// fields.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	node := doc.Content[0]
+	if c.output == "yaml" {
+		resetStyle(node)
+		enc := yaml.NewEncoder(c.stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	if items := field(node, "data"); items != nil && items.Kind == yaml.SequenceNode {
+		if len(columns) == 0 && len(items.Content) > 0 {
+			columns = scalarKeys(items.Content[0])
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, item := range items.Content {
+			cells := make([]string, len(columns))
+			for i, col := range columns {
+				cells[i] = cell(field(item, col))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	} else if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fmt.Fprintf(tw, "%s\t%s\n", node.Content[i].Value, cell(node.Content[i+1]))
+		}
+	} else {
+		fmt.Fprintln(tw, cell(node))
+	}
+	return tw.Flush()
+}
+
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, child := range n.Content {
+		resetStyle(child)
+	}
+}
+
+// field returns the value of key in a mapping node, or nil.
+func field(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarKeys(n *yaml.Node) []string {
+	var keys []string
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i+1].Kind == yaml.ScalarNode {
+			keys = append(keys, n.Content[i].Value)
+		}
+	}
+	return keys
+}
+
+// cell formats a value on a single line.
+func cell(n *yaml.Node) string {
+	if n == nil {
+		return ""
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return ""
+		}
+		return n.Value
+	case yaml.SequenceNode:
+		values := make([]string, len(n.Content))
+		for i, child := range n.Content {
+			values[i] = cell(child)
+		}
+		return strings.Join(values, ",")
+	}
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return "?"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
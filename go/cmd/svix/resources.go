@@ -0,0 +1,632 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+// The results are converted to their openapi counterparts before printing,
+// as those omit the unset optional fields when marshalled.
+
+var commands = []*command{
+	applicationCommand,
+	endpointCommand,
+	eventTypeCommand,
+	messageCommand,
+	messageAttemptCommand,
+	integrationCommand,
+	authenticationCommand,
+	listenCommand,
+	verifyCommand,
+	signCommand,
+	planCommand,
+	applyCommand,
+	backupCommand,
+	restoreCommand,
+	bulkCommand,
+	deliveriesCommand,
+	monitorCommand,
+	compatCommand,
+}
+
+var applicationCommand = &command{
+	name:    "application",
+	help:    "Manage applications",
+	columns: []string{"id", "uid", "name", "createdAt"},
+	actions: []*action{
+		{
+			name: "list",
+			help: "List applications",
+			setup: func(fs *flag.FlagSet) runFunc {
+				l := addListFlags(fs)
+				var order orderFlag
+				fs.Var(&order, "order", "listing order: ascending or descending")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					return list(l, func(iterator *string, limit *int32) ([]openapi.ApplicationOut, *string, bool, error) {
+						out, err := client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: limit, Order: order.order})
+						if err != nil {
+							return nil, nil, false, err
+						}
+						return out.Data, out.Iterator.Get(), out.Done, nil
+					})
+				}
+			},
+		},
+		{
+			name: "get",
+			args: []string{"app-id"},
+			help: "Get an application by id or uid",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Application.Get(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.ApplicationOut(*out), nil
+			}),
+		},
+		{
+			name: "create",
+			body: bodyRequired,
+			help: "Create an application from an ApplicationIn body",
+			setup: func(fs *flag.FlagSet) runFunc {
+				getIfExists := fs.Bool("get-if-exists", false, "return the existing application with the same uid instead of failing")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					var in svix.ApplicationIn
+					if err := decodeBody(body, &in); err != nil {
+						return nil, err
+					}
+					create := client.Application.Create
+					if *getIfExists {
+						create = client.Application.GetOrCreate
+					}
+					out, err := create(ctx, &in)
+					if err != nil {
+						return nil, err
+					}
+					return openapi.ApplicationOut(*out), nil
+				}
+			},
+		},
+		{
+			name: "update",
+			args: []string{"app-id"},
+			body: bodyRequired,
+			help: "Update an application from an ApplicationIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.ApplicationIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Application.Update(ctx, args[0], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.ApplicationOut(*out), nil
+			}),
+		},
+		{
+			name: "delete",
+			args: []string{"app-id"},
+			help: "Delete an application",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				return nil, client.Application.Delete(ctx, args[0])
+			}),
+		},
+	},
+}
+
+var endpointCommand = &command{
+	name:    "endpoint",
+	help:    "Manage the endpoints of an application",
+	columns: []string{"id", "uid", "url", "disabled", "filterTypes", "createdAt"},
+	actions: []*action{
+		{
+			name: "list",
+			args: []string{"app-id"},
+			help: "List the endpoints of an application",
+			setup: func(fs *flag.FlagSet) runFunc {
+				l := addListFlags(fs)
+				var order orderFlag
+				fs.Var(&order, "order", "listing order: ascending or descending")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					return list(l, func(iterator *string, limit *int32) ([]openapi.EndpointOut, *string, bool, error) {
+						out, err := client.Endpoint.List(ctx, args[0], &svix.EndpointListOptions{Iterator: iterator, Limit: limit, Order: order.order})
+						if err != nil {
+							return nil, nil, false, err
+						}
+						return out.Data, out.Iterator.Get(), out.Done, nil
+					})
+				}
+			},
+		},
+		{
+			name: "get",
+			args: []string{"app-id", "endpoint-id"},
+			help: "Get an endpoint by id or uid",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Endpoint.Get(ctx, args[0], args[1])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EndpointOut(*out), nil
+			}),
+		},
+		{
+			name: "create",
+			args: []string{"app-id"},
+			body: bodyRequired,
+			help: "Create an endpoint from an EndpointIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.EndpointIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Endpoint.Create(ctx, args[0], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EndpointOut(*out), nil
+			}),
+		},
+		{
+			name: "update",
+			args: []string{"app-id", "endpoint-id"},
+			body: bodyRequired,
+			help: "Update an endpoint from an EndpointUpdate body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.EndpointUpdate
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Endpoint.Update(ctx, args[0], args[1], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EndpointOut(*out), nil
+			}),
+		},
+		{
+			name: "delete",
+			args: []string{"app-id", "endpoint-id"},
+			help: "Delete an endpoint",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				return nil, client.Endpoint.Delete(ctx, args[0], args[1])
+			}),
+		},
+		{
+			name: "get-secret",
+			args: []string{"app-id", "endpoint-id"},
+			help: "Get the signing secret of an endpoint",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Endpoint.GetSecret(ctx, args[0], args[1])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EndpointSecretOut(*out), nil
+			}),
+		},
+		{
+			name: "rotate-secret",
+			args: []string{"app-id", "endpoint-id"},
+			body: bodyOptional,
+			help: "Rotate the signing secret of an endpoint, optionally to the key of an EndpointSecretRotateIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.EndpointSecretRotateIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				return nil, client.Endpoint.RotateSecret(ctx, args[0], args[1], &in)
+			}),
+		},
+	},
+}
+
+var eventTypeCommand = &command{
+	name:    "event-type",
+	help:    "Manage event types",
+	columns: []string{"name", "description", "archived", "createdAt"},
+	actions: []*action{
+		{
+			name: "list",
+			help: "List event types",
+			setup: func(fs *flag.FlagSet) runFunc {
+				l := addListFlags(fs)
+				withContent := fs.Bool("with-content", false, "include the schemas")
+				includeArchived := fs.Bool("include-archived", false, "include archived event types")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					return list(l, func(iterator *string, limit *int32) ([]openapi.EventTypeOut, *string, bool, error) {
+						out, err := client.EventType.List(ctx, &svix.EventTypeListOptions{
+							Iterator:        iterator,
+							Limit:           limit,
+							WithContent:     withContent,
+							IncludeArchived: includeArchived,
+						})
+						if err != nil {
+							return nil, nil, false, err
+						}
+						return out.Data, out.Iterator.Get(), out.Done, nil
+					})
+				}
+			},
+		},
+		{
+			name: "get",
+			args: []string{"name"},
+			help: "Get an event type",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.EventType.Get(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EventTypeOut(*out), nil
+			}),
+		},
+		{
+			name: "create",
+			body: bodyRequired,
+			help: "Create an event type from an EventTypeIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.EventTypeIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.EventType.Create(ctx, &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EventTypeOut(*out), nil
+			}),
+		},
+		{
+			name: "update",
+			args: []string{"name"},
+			body: bodyRequired,
+			help: "Update an event type from an EventTypeUpdate body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.EventTypeUpdate
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.EventType.Update(ctx, args[0], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.EventTypeOut(*out), nil
+			}),
+		},
+		{
+			name: "delete",
+			args: []string{"name"},
+			help: "Archive an event type",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				return nil, client.EventType.Delete(ctx, args[0])
+			}),
+		},
+	},
+}
+
+var messageCommand = &command{
+	name:    "message",
+	help:    "Send and inspect messages",
+	columns: []string{"id", "eventId", "eventType", "timestamp"},
+	actions: []*action{
+		{
+			name: "list",
+			args: []string{"app-id"},
+			help: "List the messages of an application",
+			setup: func(fs *flag.FlagSet) runFunc {
+				l := addListFlags(fs)
+				var eventTypes stringsFlag
+				var before, after timeFlag
+				fs.Var(&eventTypes, "event-type", "only list messages of this event type (repeatable)")
+				fs.Var(&before, "before", "only list messages sent before this RFC 3339 time")
+				fs.Var(&after, "after", "only list messages sent after this RFC 3339 time")
+				channel := fs.String("channel", "", "only list messages sent to this channel")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					options := &svix.MessageListOptions{
+						EventTypes: eventTypes.ptr(),
+						Before:     before.t,
+						After:      after.t,
+					}
+					if *channel != "" {
+						options.Channel = channel
+					}
+					return list(l, func(iterator *string, limit *int32) ([]openapi.MessageOut, *string, bool, error) {
+						options.Iterator, options.Limit = iterator, limit
+						out, err := client.Message.List(ctx, args[0], options)
+						if err != nil {
+							return nil, nil, false, err
+						}
+						return out.Data, out.Iterator.Get(), out.Done, nil
+					})
+				}
+			},
+		},
+		{
+			name: "get",
+			args: []string{"app-id", "msg-id"},
+			help: "Get a message by id or eventId",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Message.Get(ctx, args[0], args[1])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.MessageOut(*out), nil
+			}),
+		},
+		{
+			name: "create",
+			args: []string{"app-id"},
+			body: bodyRequired,
+			help: "Send a message from a MessageIn body",
+			setup: func(fs *flag.FlagSet) runFunc {
+				idempotencyKey := fs.String("idempotency-key", "", "idempotency key of the request")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					var in svix.MessageIn
+					if err := decodeBody(body, &in); err != nil {
+						return nil, err
+					}
+					var options *svix.PostOptions
+					if *idempotencyKey != "" {
+						options = &svix.PostOptions{IdempotencyKey: idempotencyKey}
+					}
+					out, err := client.Message.CreateWithOptions(ctx, args[0], &in, options)
+					if err != nil {
+						return nil, err
+					}
+					return openapi.MessageOut(*out), nil
+				}
+			},
+		},
+	},
+}
+
+var messageAttemptCommand = &command{
+	name:    "message-attempt",
+	help:    "Inspect and resend delivery attempts",
+	columns: []string{"id", "msgId", "endpointId", "responseStatusCode", "status", "timestamp"},
+	actions: []*action{
+		{
+			name: "list",
+			args: []string{"app-id"},
+			help: "List the attempts of a message (--msg) or to an endpoint (--endpoint)",
+			setup: func(fs *flag.FlagSet) runFunc {
+				l := addListFlags(fs)
+				msgId := fs.String("msg", "", "list the attempts of this message")
+				endpointId := fs.String("endpoint", "", "list the attempts to this endpoint")
+				var status statusFlag
+				var eventTypes stringsFlag
+				fs.Var(&status, "status", "only list attempts with this status: success, pending, fail or sending")
+				fs.Var(&eventTypes, "event-type", "only list attempts of this event type (repeatable)")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					if (*msgId == "") == (*endpointId == "") {
+						return nil, errors.New("exactly one of --msg and --endpoint is required")
+					}
+					options := &svix.MessageAttemptListOptions{
+						Status:     status.status,
+						EventTypes: eventTypes.ptr(),
+					}
+					return list(l, func(iterator *string, limit *int32) ([]openapi.MessageAttemptOut, *string, bool, error) {
+						options.Iterator, options.Limit = iterator, limit
+						var out *svix.ListResponseMessageAttemptOut
+						var err error
+						if *msgId != "" {
+							out, err = client.MessageAttempt.ListByMsg(ctx, args[0], *msgId, options)
+						} else {
+							out, err = client.MessageAttempt.ListByEndpoint(ctx, args[0], *endpointId, options)
+						}
+						if err != nil {
+							return nil, nil, false, err
+						}
+						return out.Data, out.Iterator.Get(), out.Done, nil
+					})
+				}
+			},
+		},
+		{
+			name: "get",
+			args: []string{"app-id", "msg-id", "attempt-id"},
+			help: "Get an attempt",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.MessageAttempt.Get(ctx, args[0], args[1], args[2])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.MessageAttemptOut(*out), nil
+			}),
+		},
+		{
+			name: "resend",
+			args: []string{"app-id", "msg-id", "endpoint-id"},
+			help: "Resend a message to an endpoint",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				return nil, client.MessageAttempt.Resend(ctx, args[0], args[1], args[2])
+			}),
+		},
+	},
+}
+
+var integrationCommand = &command{
+	name:    "integration",
+	help:    "Manage the integrations of an application",
+	columns: []string{"id", "name", "createdAt"},
+	actions: []*action{
+		{
+			name: "list",
+			args: []string{"app-id"},
+			help: "List the integrations of an application",
+			setup: func(fs *flag.FlagSet) runFunc {
+				l := addListFlags(fs)
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					return list(l, func(iterator *string, limit *int32) ([]openapi.IntegrationOut, *string, bool, error) {
+						out, err := client.Integration.List(ctx, args[0], &svix.IntegrationListOptions{Iterator: iterator, Limit: limit})
+						if err != nil {
+							return nil, nil, false, err
+						}
+						return out.Data, out.Iterator.Get(), out.Done, nil
+					})
+				}
+			},
+		},
+		{
+			name: "get",
+			args: []string{"app-id", "integ-id"},
+			help: "Get an integration",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Integration.Get(ctx, args[0], args[1])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.IntegrationOut(*out), nil
+			}),
+		},
+		{
+			name: "create",
+			args: []string{"app-id"},
+			body: bodyRequired,
+			help: "Create an integration from an IntegrationIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.IntegrationIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Integration.Create(ctx, args[0], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.IntegrationOut(*out), nil
+			}),
+		},
+		{
+			name: "update",
+			args: []string{"app-id", "integ-id"},
+			body: bodyRequired,
+			help: "Update an integration from an IntegrationUpdate body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.IntegrationUpdate
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Integration.Update(ctx, args[0], args[1], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.IntegrationOut(*out), nil
+			}),
+		},
+		{
+			name: "delete",
+			args: []string{"app-id", "integ-id"},
+			help: "Delete an integration",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				return nil, client.Integration.Delete(ctx, args[0], args[1])
+			}),
+		},
+		{
+			name: "get-key",
+			args: []string{"app-id", "integ-id"},
+			help: "Get the key of an integration",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Integration.GetKey(ctx, args[0], args[1])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.IntegrationKeyOut(*out), nil
+			}),
+		},
+		{
+			name: "rotate-key",
+			args: []string{"app-id", "integ-id"},
+			help: "Rotate the key of an integration",
+			setup: func(fs *flag.FlagSet) runFunc {
+				verify := fs.Bool("verify", false, "check that the new key works and whether the previous one is rejected")
+				return func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+					if *verify {
+						return client.RotateIntegrationKey(ctx, args[0], args[1], nil)
+					}
+					out, err := client.Integration.RotateKey(ctx, args[0], args[1])
+					if err != nil {
+						return nil, err
+					}
+					return openapi.IntegrationKeyOut(*out), nil
+				}
+			},
+		},
+	},
+}
+
+var authenticationCommand = &command{
+	name: "authentication",
+	help: "Create access tokens",
+	actions: []*action{
+		{
+			name: "app-portal-access",
+			args: []string{"app-id"},
+			body: bodyOptional,
+			help: "Get a magic link to the application portal, optionally from an AppPortalAccessIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.AppPortalAccessIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Authentication.AppPortalAccess(ctx, args[0], &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.AppPortalAccessOut(*out), nil
+			}),
+		},
+		{
+			name: "dashboard-access",
+			args: []string{"app-id"},
+			help: "Get a magic link to the application dashboard",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				out, err := client.Authentication.DashboardAccess(ctx, args[0])
+				if err != nil {
+					return nil, err
+				}
+				return openapi.DashboardAccessOut(*out), nil
+			}),
+		},
+		{
+			name: "exchange-one-time-token",
+			body: bodyRequired,
+			help: "Exchange the one-time token of a magic link for its token, from a OneTimeTokenIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.OneTimeTokenIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				out, err := client.Authentication.ExchangeOneTimeToken(ctx, &in)
+				if err != nil {
+					return nil, err
+				}
+				return openapi.OneTimeTokenOut(*out), nil
+			}),
+		},
+		{
+			name: "expire-all",
+			args: []string{"app-id"},
+			body: bodyOptional,
+			help: "Expire all the tokens of an application, optionally from an ApplicationTokenExpireIn body",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				var in svix.ApplicationTokenExpireIn
+				if err := decodeBody(body, &in); err != nil {
+					return nil, err
+				}
+				return nil, client.Authentication.ExpireAll(ctx, args[0], &in)
+			}),
+		},
+		{
+			name: "logout",
+			help: "Invalidate the token in use",
+			setup: noFlags(func(ctx context.Context, client *svix.Svix, args []string, body []byte) (interface{}, error) {
+				return nil, client.Authentication.Logout(ctx)
+			}),
+		},
+	},
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/signature"
+)
+
+// The timestamp tolerance applied by Webhook.Verify.
+const signatureTolerance = 5 * time.Minute
+
+var verifyCommand = &command{
+	name: "verify",
+	help: "Verify a webhook signature and explain why it fails",
+	run:  (*cli).verify,
+}
+
+var signCommand = &command{
+	name: "sign",
+	help: "Sign a payload, printing the webhook headers",
+	run:  (*cli).sign,
+}
+
+// check is a step of the verification of a webhook.
+type check struct {
+	Name   string `json:"name"`
+	Ok     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// diagnosis explains the result of Webhook.Verify.
+type diagnosis struct {
+	Valid  bool     `json:"valid"`
+	Error  string   `json:"error,omitempty"`
+	Checks []*check `json:"checks"`
+}
+
+func (d *diagnosis) add(name string, ok bool, format string, args ...interface{}) {
+	d.Checks = append(d.Checks, &check{Name: name, Ok: ok, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (c *cli) verify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix verify", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	secret := fs.String("secret", "", "endpoint secret (default $SVIX_WEBHOOK_SECRET)")
+	payloadPath := fs.String("payload", "", "file holding the payload, or - for stdin")
+	requestPath := fs.String("request", "", "file holding a raw HTTP request (headers and payload), or - for stdin")
+	var headers stringsFlag
+	fs.Var(&headers, "header", "webhook header as `name: value` (repeatable)")
+	nowFlag := fs.Int64("now", 0, "verify as if the current time was this unix timestamp")
+	ignoreTimestamp := fs.Bool("ignore-timestamp", false, "don't check the timestamp tolerance")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix verify --secret <secret> (--payload <file> --header <header>... | --request <file>)\n\n")
+		fmt.Fprintf(c.stderr, "Verifies a webhook like Webhook.Verify, reporting which check fails and why.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if positional, err := parseInterspersed(fs, args); err != nil {
+		return err
+	} else if len(positional) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+	*secret = firstNonEmpty(*secret, c.getenv("SVIX_WEBHOOK_SECRET"))
+	if *secret == "" {
+		return errors.New("no secret: pass --secret or set SVIX_WEBHOOK_SECRET")
+	}
+
+	var payload []byte
+	header := http.Header{}
+	switch {
+	case *requestPath != "" && *payloadPath != "":
+		return errors.New("--payload and --request are mutually exclusive")
+	case *requestPath != "":
+		data, err := c.readInput(*requestPath)
+		if err != nil {
+			return err
+		}
+		req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(normalizeRequestDump(data))))
+		if err != nil {
+			return fmt.Errorf("parsing the request: %w", err)
+		}
+		if payload, err = io.ReadAll(req.Body); err != nil {
+			return fmt.Errorf("reading the request body: %w", err)
+		}
+		header = req.Header
+	case *payloadPath != "":
+		var err error
+		if payload, err = c.readInput(*payloadPath); err != nil {
+			return err
+		}
+	default:
+		return errors.New("pass the payload with --payload or --request")
+	}
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, expected `name: value`", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	var now time.Time
+	if *nowFlag != 0 {
+		now = time.Unix(*nowFlag, 0)
+	}
+	d := diagnose(*secret, payload, header, now, *ignoreTimestamp)
+	if c.output != "" {
+		if err := c.print(d, nil); err != nil {
+			return err
+		}
+	} else {
+		for _, check := range d.Checks {
+			mark := "ok  "
+			if !check.Ok {
+				mark = "FAIL"
+			}
+			fmt.Fprintf(c.stdout, "[%s] %-10s %s\n", mark, check.Name, check.Detail)
+		}
+	}
+	if !d.Valid {
+		return fmt.Errorf("verification failed: %s", d.Error)
+	}
+	if c.output == "" {
+		fmt.Fprintln(c.stdout, "The signature is valid.")
+	}
+	return nil
+}
+
+// diagnose verifies a webhook step by step, as of now or the current time
+// if now is zero. The verdict is the one of Webhook.Verify (or
+// VerifyIgnoringTimestamp when the time is simulated); the checks explain it.
+func diagnose(secret string, payload []byte, header http.Header, now time.Time, ignoreTimestamp bool) *diagnosis {
+	simulated := !now.IsZero()
+	if !simulated {
+		now = time.Now()
+	}
+	d := &diagnosis{}
+	fail := func(err string) *diagnosis {
+		d.Error = err
+		return d
+	}
+
+	wh, err := svix.NewWebhook(secret)
+	if err != nil {
+		d.add("secret", false, "the secret isn't valid base64 after its whsec_ prefix: %v", err)
+		return fail("invalid secret")
+	}
+	if strings.HasPrefix(secret, "whsec_") {
+		d.add("secret", true, "whsec_ secret")
+	} else {
+		d.add("secret", true, "base64 secret without the whsec_ prefix")
+	}
+
+	// Like Webhook.Verify, use the svix- headers if they're all present and
+	// fall back to the unbranded webhook- ones.
+	prefix := "svix-"
+	if !hasAllHeaders(header, prefix) {
+		prefix = "webhook-"
+	}
+	if !hasAllHeaders(header, prefix) {
+		// Report the headers missing from the set that was attempted.
+		if header.Get("webhook-id") == "" && header.Get("webhook-timestamp") == "" && header.Get("webhook-signature") == "" {
+			prefix = "svix-"
+		}
+		var missing []string
+		for _, name := range []string{"id", "timestamp", "signature"} {
+			if header.Get(prefix+name) == "" {
+				missing = append(missing, prefix+name)
+			}
+		}
+		d.add("headers", false, "missing %s", strings.Join(missing, ", "))
+		return fail("Missing Required Headers")
+	}
+	msgId := header.Get(prefix + "id")
+	d.add("headers", true, "%sid, %stimestamp and %ssignature present", prefix, prefix, prefix)
+
+	rawTimestamp := header.Get(prefix + "timestamp")
+	unix, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		d.add("timestamp", false, "%q isn't a unix timestamp in seconds", rawTimestamp)
+		return fail("Invalid Signature Headers")
+	}
+	timestamp := time.Unix(unix, 0)
+	// Timestamps far off would overflow a time.Duration.
+	skew := now.Unix() - unix
+	tolerance := int64(signatureTolerance / time.Second)
+	switch {
+	case ignoreTimestamp:
+		d.add("timestamp", true, "%s, not checked", timestamp.UTC().Format(time.RFC3339))
+	case skew > tolerance:
+		detail := fmt.Sprintf("%s is %s in the past, beyond the tolerance of %s", timestamp.UTC().Format(time.RFC3339), formatSeconds(skew), signatureTolerance)
+		if skew > 24*60*60 {
+			detail += " (is the webhook being replayed, or the clock wrong?)"
+		}
+		d.add("timestamp", false, "%s", detail)
+		return fail("Message timestamp too old")
+	case -skew > tolerance:
+		detail := fmt.Sprintf("%s is %s in the future, beyond the tolerance of %s", timestamp.UTC().Format(time.RFC3339), formatSeconds(-skew), signatureTolerance)
+		if unix > 1e12 {
+			detail += " (it looks like milliseconds instead of seconds)"
+		}
+		d.add("timestamp", false, "%s", detail)
+		return fail("Message timestamp too new")
+	default:
+		d.add("timestamp", true, "%s, %s of skew within the tolerance of %s", timestamp.UTC().Format(time.RFC3339), formatSeconds(skew), signatureTolerance)
+	}
+
+	expected, _ := wh.Sign(msgId, timestamp, payload)
+	var v1, other []string
+	signatures, malformed := signature.Parse(header.Get(prefix + "signature"))
+	for _, sig := range signatures {
+		if sig.Version == "v1" {
+			v1 = append(v1, sig.Value)
+		} else {
+			other = append(other, sig.Version)
+		}
+	}
+	if len(v1) == 0 {
+		detail := "no v1 signature"
+		if len(other) > 0 {
+			detail += fmt.Sprintf(", only unsupported versions %s", strings.Join(other, ", "))
+		}
+		if len(malformed) > 0 {
+			detail += fmt.Sprintf(", malformed entries %q (expected version,signature)", malformed)
+		}
+		d.add("signature", false, "%s", detail)
+		return fail("No matching signature found")
+	}
+
+	matched := false
+	for _, sig := range v1 {
+		if "v1,"+sig == expected {
+			matched = true
+		}
+	}
+	if !matched {
+		detail := fmt.Sprintf("none of the %d v1 signature(s) matches the expected %s, computed over %q followed by the %d byte payload",
+			len(v1), expected, msgId+"."+rawTimestamp+".", len(payload))
+		if hint := signatureHint(secret, msgId, timestamp, payload, v1); hint != "" {
+			detail += "; " + hint
+		}
+		d.add("signature", false, "%s", detail)
+		return fail("No matching signature found")
+	}
+	d.add("signature", true, "matches %s", expected)
+
+	if ignoreTimestamp || simulated {
+		err = wh.VerifyIgnoringTimestamp(payload, header)
+	} else {
+		err = wh.Verify(payload, header)
+	}
+	if err != nil {
+		return fail(err.Error())
+	}
+	d.Valid = true
+	return d
+}
+
+func formatSeconds(n int64) string {
+	if n > 1e9 || n < -1e9 {
+		return fmt.Sprintf("%ds", n)
+	}
+	return (time.Duration(n) * time.Second).String()
+}
+
+func hasAllHeaders(header http.Header, prefix string) bool {
+	return header.Get(prefix+"id") != "" && header.Get(prefix+"timestamp") != "" && header.Get(prefix+"signature") != ""
+}
+
+// signatureHint looks for the usual reasons of a mismatch: a payload
+// modified before verification, or the wrong use of the secret.
+func signatureHint(secret, msgId string, timestamp time.Time, payload []byte, signatures []string) string {
+	matches := func(wh *svix.Webhook, payload []byte) bool {
+		expected, _ := wh.Sign(msgId, timestamp, payload)
+		for _, sig := range signatures {
+			if "v1,"+sig == expected {
+				return true
+			}
+		}
+		return false
+	}
+	wh, _ := svix.NewWebhook(secret)
+	if trimmed := bytes.TrimRight(payload, "\r\n"); len(trimmed) != len(payload) && matches(wh, trimmed) {
+		return "it matches the payload without its trailing newline, which was added after signing"
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, payload) == nil && !bytes.Equal(compact.Bytes(), payload) && matches(wh, compact.Bytes()) {
+		return "it matches the payload with its whitespace removed: verify the raw body instead of re-serialized JSON"
+	}
+	if raw, _ := svix.NewWebhookRaw([]byte(secret)); matches(raw, payload) {
+		return "it matches when using the secret string itself as the key, instead of its base64 decoded value"
+	}
+	return "the payload must be the exact raw body, and the secret the one of the endpoint that received it"
+}
+
+func (c *cli) sign(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("svix sign", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.globalFlags(fs)
+	secret := fs.String("secret", "", "endpoint secret (default $SVIX_WEBHOOK_SECRET)")
+	payloadPath := fs.String("payload", "-", "file holding the payload, or - for stdin")
+	msgId := fs.String("id", "", "message id (default a random one)")
+	timestamp := fs.Int64("timestamp", 0, "unix timestamp of the signature (default now)")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: svix sign --secret <secret> [--payload <file>] [flags]\n\nPrints the headers of a webhook with the payload.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if positional, err := parseInterspersed(fs, args); err != nil {
+		return err
+	} else if len(positional) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+	*secret = firstNonEmpty(*secret, c.getenv("SVIX_WEBHOOK_SECRET"))
+	if *secret == "" {
+		return errors.New("no secret: pass --secret or set SVIX_WEBHOOK_SECRET")
+	}
+	wh, err := svix.NewWebhook(*secret)
+	if err != nil {
+		return fmt.Errorf("invalid secret: %w", err)
+	}
+	payload, err := c.readInput(*payloadPath)
+	if err != nil {
+		return err
+	}
+	if *msgId == "" {
+		b := make([]byte, 12)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		*msgId = "msg_" + hex.EncodeToString(b)
+	}
+	ts := time.Now()
+	if *timestamp != 0 {
+		ts = time.Unix(*timestamp, 0)
+	}
+	signature, err := wh.Sign(*msgId, ts, payload)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{
+		"svix-id":        *msgId,
+		"svix-timestamp": strconv.FormatInt(ts.Unix(), 10),
+		"svix-signature": signature,
+	}
+	if c.output != "" {
+		return c.print(headers, nil)
+	}
+	for _, name := range []string{"svix-id", "svix-timestamp", "svix-signature"} {
+		fmt.Fprintf(c.stdout, "%s: %s\n", name, headers[name])
+	}
+	return nil
+}
+
+// readInput reads a file, or stdin for "-".
+func (c *cli) readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(c.stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// normalizeRequestDump makes a request copied from a log or terminal
+// parseable: line endings are fixed, a missing request line is added, and
+// the body is whatever follows the headers, whatever their Content-Length.
+func normalizeRequestDump(data []byte) []byte {
+	head, body, found := bytes.Cut(data, []byte("\r\n\r\n"))
+	if !found {
+		head, body, _ = bytes.Cut(data, []byte("\n\n"))
+	}
+	var lines []string
+	for i, line := range strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n") {
+		if i == 0 && !strings.Contains(line, " HTTP/") {
+			lines = append(lines, "POST / HTTP/1.1")
+		}
+		name := strings.ToLower(line)
+		if strings.HasPrefix(name, "content-length:") || strings.HasPrefix(name, "transfer-encoding:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "Content-Length: "+strconv.Itoa(len(body)))
+	return append([]byte(strings.Join(lines, "\r\n")+"\r\n\r\n"), body...)
+}
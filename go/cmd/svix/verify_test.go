@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+const testSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	payload := filepath.Join(dir, "payload.json")
+	if err := os.WriteFile(payload, []byte(`{"test": 2432232314}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runCLI(t, nil, "", "sign", "--secret", testSecret, "--payload", payload, "--id", "msg_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "svix-id: msg_1\n") {
+		t.Fatalf("unexpected headers:\n%s", out)
+	}
+
+	// The output of sign is a valid request dump once followed by the payload.
+	request := filepath.Join(dir, "request.txt")
+	if err := os.WriteFile(request, []byte(out+"\n"+`{"test": 2432232314}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runCLI(t, nil, "", "verify", "--secret", testSecret, "--request", request)
+	if err != nil || !strings.Contains(out, "The signature is valid.") {
+		t.Fatalf("verification failed (%v):\n%s", err, out)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	payload := []byte(`{"test": 2432232314}`)
+	now := time.Unix(1700000000, 0)
+	wh, _ := svix.NewWebhook(testSecret)
+	headers := func(prefix string, timestamp time.Time, payload []byte) http.Header {
+		sig, _ := wh.Sign("msg_1", timestamp, payload)
+		h := http.Header{}
+		h.Set(prefix+"id", "msg_1")
+		h.Set(prefix+"timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+		h.Set(prefix+"signature", sig)
+		return h
+	}
+
+	testCases := []struct {
+		name    string
+		header  http.Header
+		payload []byte
+		err     string
+		detail  string
+	}{
+		{
+			name:    "valid",
+			header:  headers("svix-", now, payload),
+			payload: payload,
+		},
+		{
+			name:    "valid unbranded",
+			header:  headers("webhook-", now.Add(-time.Minute), payload),
+			payload: payload,
+		},
+		{
+			// Webhook.Verify ignores what follows the signature.
+			name: "extra fields",
+			header: func() http.Header {
+				h := headers("svix-", now, payload)
+				h.Set("svix-signature", "v1a,abc "+h.Get("svix-signature")+",extra")
+				return h
+			}(),
+			payload: payload,
+		},
+		{
+			name: "missing headers",
+			header: http.Header{
+				"Svix-Id": {"msg_1"},
+			},
+			payload: payload,
+			err:     "Missing Required Headers",
+			detail:  "missing svix-timestamp, svix-signature",
+		},
+		{
+			name:    "too old",
+			header:  headers("svix-", now.Add(-10*time.Minute), payload),
+			payload: payload,
+			err:     "Message timestamp too old",
+			detail:  "10m0s in the past, beyond the tolerance of 5m0s",
+		},
+		{
+			name: "milliseconds",
+			header: func() http.Header {
+				h := headers("svix-", now, payload)
+				h.Set("svix-timestamp", strconv.FormatInt(now.Unix(), 10)+"000")
+				return h
+			}(),
+			payload: payload,
+			err:     "Message timestamp too new",
+			detail:  "milliseconds",
+		},
+		{
+			name: "wrong version",
+			header: func() http.Header {
+				h := headers("svix-", now, payload)
+				h.Set("svix-signature", "v1a,abc")
+				return h
+			}(),
+			payload: payload,
+			err:     "No matching signature found",
+			detail:  "only unsupported versions v1a",
+		},
+		{
+			name:    "trailing newline",
+			header:  headers("svix-", now, payload),
+			payload: append(append([]byte{}, payload...), '\n'),
+			err:     "No matching signature found",
+			detail:  "without its trailing newline",
+		},
+		{
+			name:    "reserialized",
+			header:  headers("svix-", now, []byte(`{"test":2432232314}`)),
+			payload: payload,
+			err:     "No matching signature found",
+			detail:  "whitespace removed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := diagnose(testSecret, tc.payload, tc.header, now, false)
+			if d.Valid != (tc.err == "") || d.Error != tc.err {
+				t.Fatalf("expected error %q, got %q (valid=%v)", tc.err, d.Error, d.Valid)
+			}
+			last := d.Checks[len(d.Checks)-1]
+			if tc.detail != "" && !strings.Contains(last.Detail, tc.detail) {
+				t.Errorf("expected the %s check to mention %q, got %q", last.Name, tc.detail, last.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Package deliveries finds the messages that failed to be delivered and
+// resends them.
+//
+// Scan looks at the attempts of one or many endpoints, and reports the
+// messages whose last delivery attempt failed. The failures can be grouped
+// by response to understand what went wrong, and then resent:
+//
+//	failures, err := deliveries.Scan(ctx, client, &deliveries.Query{Apps: []string{appId}, After: since})
+//	for _, g := range deliveries.GroupFailures(failures) {
+//		fmt.Println(len(g.Failures), g.StatusCode, g.Response)
+//	}
+//	results := deliveries.Resend(ctx, client, failures, nil)
+//
+// Messages that are still being retried by Svix are reported too, as their
+// last attempt failed; restricting the query with Before avoids resending
+// them.
+package deliveries
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+)
+
+// Query selects the attempts to scan.
+type Query struct {
+	// The applications to scan, by id or uid. All of them when empty.
+	Apps []string
+	// The endpoints to scan, by id or uid, when Apps holds a single
+	// application. All of its endpoints when empty.
+	Endpoints []string
+	// The time range of the attempts. Zero values leave it open.
+	After  time.Time
+	Before time.Time
+	// Only report failures with a response status code of this class.
+	StatusCodeClass *svix.StatusCodeClass
+	EventTypes      []string
+	Channel         string
+}
+
+// Failure is a message whose last delivery attempt to an endpoint failed.
+type Failure struct {
+	AppId      string    `json:"appId"`
+	EndpointId string    `json:"endpointId"`
+	MsgId      string    `json:"msgId"`
+	AttemptId  string    `json:"attemptId"`
+	Url        string    `json:"url"`
+	StatusCode int32     `json:"statusCode"`
+	Response   string    `json:"response"`
+	Timestamp  time.Time `json:"timestamp"`
+	// The number of failed attempts found for the message.
+	Attempts int `json:"attempts"`
+}
+
+// Scan lists the messages whose last attempt to be delivered to one of the
+// selected endpoints failed, most recent first.
+func Scan(ctx context.Context, client *svix.Svix, q *Query) ([]*Failure, error) {
+	if q == nil {
+		q = &Query{}
+	}
+	if len(q.Endpoints) != 0 && len(q.Apps) != 1 {
+		return nil, errors.New("deliveries: selecting endpoints requires a single application")
+	}
+	apps := q.Apps
+	if len(apps) == 0 {
+		err := paginate.Each(func(iterator *string) (*string, bool, error) {
+			out, err := client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+			if err != nil {
+				return nil, false, err
+			}
+			for _, app := range out.Data {
+				apps = append(apps, app.Id)
+			}
+			return out.Iterator.Get(), out.Done, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var failures []*Failure
+	for _, appId := range apps {
+		endpoints := q.Endpoints
+		if len(endpoints) == 0 {
+			var err error
+			endpoints, err = listEndpoints(ctx, client, appId)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, endpointId := range endpoints {
+			found, err := scanEndpoint(ctx, client, appId, endpointId, q)
+			if err != nil {
+				return nil, err
+			}
+			failures = append(failures, found...)
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool { return failures[i].Timestamp.After(failures[j].Timestamp) })
+	return failures, nil
+}
+
+func listEndpoints(ctx context.Context, client *svix.Svix, appId string) ([]string, error) {
+	var ids []string
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := client.Endpoint.List(ctx, appId, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, ep := range out.Data {
+			ids = append(ids, ep.Id)
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	return ids, err
+}
+
+// scanEndpoint lists all the attempts of the endpoint, rather than only the
+// failed ones, so that messages whose delivery eventually succeeded aren't
+// reported.
+func scanEndpoint(ctx context.Context, client *svix.Svix, appId string, endpointId string, q *Query) ([]*Failure, error) {
+	options := &svix.MessageAttemptListOptions{Limit: svix.Int32(paginate.PageSize)}
+	if !q.After.IsZero() {
+		options.After = &q.After
+	}
+	if !q.Before.IsZero() {
+		options.Before = &q.Before
+	}
+	if len(q.EventTypes) != 0 {
+		options.EventTypes = &q.EventTypes
+	}
+	if q.Channel != "" {
+		options.Channel = &q.Channel
+	}
+
+	last := map[string]*openapi.MessageAttemptOut{}
+	failed := map[string]int{}
+	var order []string
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		options.Iterator = iterator
+		out, err := client.MessageAttempt.ListByEndpoint(ctx, appId, endpointId, options)
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range out.Data {
+			attempt := &out.Data[i]
+			prev, ok := last[attempt.MsgId]
+			if !ok {
+				order = append(order, attempt.MsgId)
+			}
+			if !ok || attempt.Timestamp.After(prev.Timestamp) {
+				last[attempt.MsgId] = attempt
+			}
+			if attempt.Status == openapi.MESSAGESTATUS_Fail {
+				failed[attempt.MsgId]++
+			}
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []*Failure
+	for _, msgId := range order {
+		attempt := last[msgId]
+		if attempt.Status != openapi.MESSAGESTATUS_Fail {
+			continue
+		}
+		if q.StatusCodeClass != nil && statusCodeClass(attempt.ResponseStatusCode) != *q.StatusCodeClass {
+			continue
+		}
+		failures = append(failures, &Failure{
+			AppId:      appId,
+			EndpointId: attempt.EndpointId,
+			MsgId:      msgId,
+			AttemptId:  attempt.Id,
+			Url:        attempt.Url,
+			StatusCode: attempt.ResponseStatusCode,
+			Response:   attempt.Response,
+			Timestamp:  attempt.Timestamp,
+			Attempts:   failed[msgId],
+		})
+	}
+	return failures, nil
+}
+
+func statusCodeClass(code int32) svix.StatusCodeClass {
+	if code < 100 || code > 599 {
+		return svix.StatusCodeClass(openapi.STATUSCODECLASS_CodeNone)
+	}
+	return svix.StatusCodeClass(code / 100 * 100)
+}
+
+// Group is a set of failures with the same response.
+type Group struct {
+	StatusCode int32 `json:"statusCode"`
+	// The response body, with surrounding whitespace removed and truncated
+	// to 200 characters.
+	Response string     `json:"response"`
+	Failures []*Failure `json:"failures"`
+}
+
+const maxGroupResponse = 200
+
+// GroupFailures groups failures by status code and response body, largest
+// groups first.
+func GroupFailures(failures []*Failure) []*Group {
+	type key struct {
+		code     int32
+		response string
+	}
+	groups := map[key]*Group{}
+	var out []*Group
+	for _, f := range failures {
+		response := strings.TrimSpace(f.Response)
+		if r := []rune(response); len(r) > maxGroupResponse {
+			response = string(r[:maxGroupResponse])
+		}
+		k := key{f.StatusCode, response}
+		g, ok := groups[k]
+		if !ok {
+			g = &Group{StatusCode: f.StatusCode, Response: response}
+			groups[k] = g
+			out = append(out, g)
+		}
+		g.Failures = append(g.Failures, f)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if len(out[i].Failures) != len(out[j].Failures) {
+			return len(out[i].Failures) > len(out[j].Failures)
+		}
+		if out[i].StatusCode != out[j].StatusCode {
+			return out[i].StatusCode < out[j].StatusCode
+		}
+		return out[i].Response < out[j].Response
+	})
+	return out
+}
+
+type ResendOptions struct {
+	// Number of messages resent concurrently. Defaults to 4.
+	Concurrency int
+	// Only report what would be resent.
+	DryRun bool
+	// Called with each result as soon as it is known.
+	OnResult func(ResendResult)
+}
+
+type ResendResult struct {
+	Failure *Failure
+	// Set when the message wasn't resent because of ResendOptions.DryRun.
+	DryRun bool
+	Err    error
+}
+
+// Resend resends the failed messages to their endpoint. A message is only
+// resent once per endpoint, even if it appears several times in failures.
+// Results are in the order of the deduplicated failures.
+func Resend(ctx context.Context, client *svix.Svix, failures []*Failure, options *ResendOptions) []ResendResult {
+	var opts ResendOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	seen := map[[3]string]bool{}
+	var unique []*Failure
+	for _, f := range failures {
+		k := [3]string{f.AppId, f.EndpointId, f.MsgId}
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, f)
+		}
+	}
+
+	results := make([]ResendResult, len(unique))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				f := unique[i]
+				res := ResendResult{Failure: f, DryRun: opts.DryRun}
+				if err := ctx.Err(); err != nil {
+					res.Err = err
+				} else if !opts.DryRun {
+					res.Err = client.MessageAttempt.Resend(ctx, f.AppId, f.MsgId, f.EndpointId)
+				}
+				mu.Lock()
+				results[i] = res
+				if opts.OnResult != nil {
+					opts.OnResult(res)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range unique {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
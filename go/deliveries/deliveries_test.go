@@ -0,0 +1,126 @@
+package deliveries_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/deliveries"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestScanAndResend(t *testing.T) {
+	var healthy atomic.Bool
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			switch {
+			case healthy.Load() || strings.Contains(d.Url, "ok"):
+				return 200, "ok"
+			case strings.Contains(d.Url, "down"):
+				return 503, "  upstream unavailable\n"
+			default:
+				return 404, "not found"
+			}
+		},
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints := map[string]string{}
+	for _, name := range []string{"ok", "down", "gone"} {
+		ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://" + name + ".example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		endpoints[name] = ep.Id
+	}
+	for i := 0; i < 3; i++ {
+		_, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{
+			EventType: []string{"invoice.paid", "invoice.paid", "user.signup"}[i],
+			Payload:   map[string]interface{}{"i": i},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.WaitForDeliveries()
+
+	failures, err := deliveries.Scan(ctx, client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 6 {
+		t.Fatalf("expected 6 failures, got %d", len(failures))
+	}
+	groups := deliveries.GroupFailures(failures)
+	if len(groups) != 2 || groups[0].StatusCode != 404 || groups[1].StatusCode != 503 || groups[1].Response != "upstream unavailable" {
+		for _, g := range groups {
+			t.Logf("%d %q: %d", g.StatusCode, g.Response, len(g.Failures))
+		}
+		t.Fatal("unexpected groups")
+	}
+
+	class := svix.StatusCodeClass(500)
+	testCases := []struct {
+		name  string
+		query *deliveries.Query
+		count int
+	}{
+		{"status code class", &deliveries.Query{StatusCodeClass: &class}, 3},
+		{"event types", &deliveries.Query{EventTypes: []string{"user.signup"}}, 2},
+		{"endpoint", &deliveries.Query{Apps: []string{app.Id}, Endpoints: []string{endpoints["gone"]}}, 3},
+		{"healthy endpoint", &deliveries.Query{Apps: []string{app.Id}, Endpoints: []string{endpoints["ok"]}}, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			found, err := deliveries.Scan(ctx, client, tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found) != tc.count {
+				t.Errorf("expected %d failures, got %d", tc.count, len(found))
+			}
+		})
+	}
+
+	// Duplicates are only resent once.
+	healthy.Store(true)
+	results := deliveries.Resend(ctx, client, append(failures, failures[0]), &deliveries.ResendOptions{Concurrency: 3})
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+	srv.WaitForDeliveries()
+	failures, err = deliveries.Scan(ctx, client, nil)
+	if err != nil || len(failures) != 0 {
+		t.Fatalf("expected the resent messages to be delivered, got %v (%v)", failures, err)
+	}
+}
+
+func TestGroupFailures(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	var failures []*deliveries.Failure
+	for i, resp := range []string{long, long + "y", "a", "b", "b"} {
+		failures = append(failures, &deliveries.Failure{MsgId: fmt.Sprint("msg_", i), StatusCode: 500, Response: resp})
+	}
+	groups := deliveries.GroupFailures(failures)
+	// Groups of the same size are ordered by response.
+	if len(groups) != 3 || groups[0].Response != "b" || len(groups[1].Failures) != 2 || groups[2].Response != "a" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if len(groups[1].Response) != 200 {
+		t.Errorf("expected long responses to be truncated, got %d characters", len(groups[1].Response))
+	}
+}
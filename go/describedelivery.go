@@ -0,0 +1,185 @@
+package svix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+)
+
+// DeliveryDescription describes the delivery of a message to its endpoints.
+type DeliveryDescription struct {
+	Message      *MessageOut            `json:"message"`
+	Destinations []*DeliveryDestination `json:"destinations"`
+	// The events of the delivery to all the endpoints, oldest first.
+	Timeline []*DeliveryEvent `json:"timeline"`
+}
+
+// DeliveryDestination is an endpoint the message was sent to.
+type DeliveryDestination struct {
+	Endpoint MessageEndpointOut `json:"endpoint"`
+	// Oldest first.
+	Attempts []*DeliveryAttempt `json:"attempts"`
+}
+
+// MarshalJSON encodes the endpoint with the encoder of the generated
+// client, which handles its unset nullable fields.
+func (d DeliveryDestination) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Endpoint openapi.MessageEndpointOut `json:"endpoint"`
+		Attempts []*DeliveryAttempt         `json:"attempts"`
+	}{openapi.MessageEndpointOut(d.Endpoint), d.Attempts})
+}
+
+type DeliveryAttempt struct {
+	MessageAttemptOut
+	// Nil when the headers of the attempt are no longer available.
+	Headers *MessageAttemptHeadersOut `json:"headers,omitempty"`
+}
+
+type DeliveryEventKind string
+
+const (
+	DeliveryEventCreated DeliveryEventKind = "created"
+	DeliveryEventAttempt DeliveryEventKind = "attempt"
+	// The next attempt scheduled by Svix for an endpoint, in the future.
+	DeliveryEventNextAttempt DeliveryEventKind = "next-attempt"
+)
+
+type DeliveryEvent struct {
+	Time       time.Time         `json:"time"`
+	Kind       DeliveryEventKind `json:"kind"`
+	EndpointId string            `json:"endpointId,omitempty"`
+	Url        string            `json:"url,omitempty"`
+	// Set for attempts.
+	Attempt *DeliveryAttempt `json:"attempt,omitempty"`
+}
+
+var messageStatusNames = map[openapi.MessageStatus]string{
+	openapi.MESSAGESTATUS_Success: "succeeded",
+	openapi.MESSAGESTATUS_Pending: "pending",
+	openapi.MESSAGESTATUS_Fail:    "failed",
+	openapi.MESSAGESTATUS_Sending: "sending",
+}
+
+func (e *DeliveryEvent) String() string {
+	ts := e.Time.UTC().Format(time.RFC3339)
+	switch e.Kind {
+	case DeliveryEventCreated:
+		return ts + " message created"
+	case DeliveryEventNextAttempt:
+		return fmt.Sprintf("%s next attempt to %s (%s)", ts, e.EndpointId, e.Url)
+	}
+	a := e.Attempt
+	line := fmt.Sprintf("%s attempt %s to %s (%s) %s", ts, a.Id, e.EndpointId, e.Url, messageStatusNames[a.Status])
+	if a.ResponseStatusCode != 0 {
+		line += fmt.Sprintf(" with %d", a.ResponseStatusCode)
+	}
+	return line
+}
+
+// String renders the timeline of the delivery, one event per line.
+func (d *DeliveryDescription) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "message %s (%s) sent to %d endpoints\n", d.Message.Id, d.Message.EventType, len(d.Destinations))
+	for _, e := range d.Timeline {
+		b.WriteString(e.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// DescribeDelivery gathers the message, the endpoints it was sent to, and
+// every attempt along with its headers, into a timeline of its delivery.
+func (s *Svix) DescribeDelivery(ctx context.Context, appId string, msgId string) (*DeliveryDescription, error) {
+	msg, err := s.Message.Get(ctx, appId, msgId)
+	if err != nil {
+		return nil, err
+	}
+	d := &DeliveryDescription{
+		Message:  msg,
+		Timeline: []*DeliveryEvent{{Time: msg.Timestamp, Kind: DeliveryEventCreated}},
+	}
+
+	err = paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := s.MessageAttempt.ListAttemptedDestinations(ctx, appId, msg.Id, &MessageAttemptListOptions{Iterator: iterator, Limit: Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, ep := range out.Data {
+			d.Destinations = append(d.Destinations, &DeliveryDestination{Endpoint: MessageEndpointOut(ep)})
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dest := range d.Destinations {
+		if dest.Attempts, err = s.deliveryAttempts(ctx, appId, msg.Id, dest.Endpoint.Id); err != nil {
+			return nil, err
+		}
+		for _, a := range dest.Attempts {
+			d.Timeline = append(d.Timeline, &DeliveryEvent{
+				Time:       a.Timestamp,
+				Kind:       DeliveryEventAttempt,
+				EndpointId: dest.Endpoint.Id,
+				Url:        a.Url,
+				Attempt:    a,
+			})
+		}
+		if next := dest.Endpoint.NextAttempt.Get(); next != nil {
+			d.Timeline = append(d.Timeline, &DeliveryEvent{
+				Time:       *next,
+				Kind:       DeliveryEventNextAttempt,
+				EndpointId: dest.Endpoint.Id,
+				Url:        dest.Endpoint.Url,
+			})
+		}
+	}
+	sort.SliceStable(d.Timeline, func(i, j int) bool {
+		return d.Timeline[i].Time.Before(d.Timeline[j].Time)
+	})
+	return d, nil
+}
+
+// deliveryAttempts returns the attempts of a message to an endpoint, oldest
+// first, with their headers.
+func (s *Svix) deliveryAttempts(ctx context.Context, appId string, msgId string, endpointId string) ([]*DeliveryAttempt, error) {
+	var attempts []*DeliveryAttempt
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := s.MessageAttempt.ListByMsg(ctx, appId, msgId, &MessageAttemptListOptions{Iterator: iterator, Limit: Int32(paginate.PageSize), EndpointId: &endpointId})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, a := range out.Data {
+			attempts = append(attempts, &DeliveryAttempt{MessageAttemptOut: MessageAttemptOut(a)})
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].Timestamp.Before(attempts[j].Timestamp)
+	})
+
+	for _, a := range attempts {
+		headers, err := s.MessageAttempt.GetHeaders(ctx, appId, msgId, a.Id)
+		var svixErr *Error
+		if errors.As(err, &svixErr) && svixErr.Status() == http.StatusNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		a.Headers = headers
+	}
+	return attempts, nil
+}
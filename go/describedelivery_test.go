@@ -0,0 +1,96 @@
+package svix_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestDescribeDelivery(t *testing.T) {
+	var calls atomic.Int32
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			if d.Url == "https://example.com/flaky" && calls.Add(1) == 1 {
+				return 503, "unavailable"
+			}
+			return 200, "ok"
+		},
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flaky, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/flaky"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Endpoint.UpdateHeaders(ctx, app.Id, flaky.Id, &svix.EndpointHeadersIn{
+		Headers: map[string]string{"X-Tenant": "acme", "Authorization": "Bearer secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/healthy"}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{EventType: "invoice.paid", Payload: map[string]interface{}{"id": "in_1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.WaitForDeliveries()
+	if err := client.MessageAttempt.Resend(ctx, app.Id, msg.Id, flaky.Id); err != nil {
+		t.Fatal(err)
+	}
+	srv.WaitForDeliveries()
+
+	d, err := client.DescribeDelivery(ctx, app.Id, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Message.Id != msg.Id || len(d.Destinations) != 2 || len(d.Timeline) != 4 {
+		t.Fatalf("unexpected description: %s", d)
+	}
+	if d.Timeline[0].Kind != svix.DeliveryEventCreated {
+		t.Errorf("expected the timeline to start with the message, got %s", d.Timeline[0])
+	}
+	for i := 1; i < len(d.Timeline); i++ {
+		if d.Timeline[i].Time.Before(d.Timeline[i-1].Time) {
+			t.Errorf("the timeline isn't sorted: %s", d)
+		}
+	}
+
+	var attempts []*svix.DeliveryAttempt
+	for _, dest := range d.Destinations {
+		if dest.Endpoint.Id == flaky.Id {
+			attempts = dest.Attempts
+		}
+	}
+	if len(attempts) != 2 || attempts[0].ResponseStatusCode != 503 || attempts[1].ResponseStatusCode != 200 {
+		t.Fatalf("unexpected attempts: %s", d)
+	}
+	headers := attempts[0].Headers
+	if headers == nil || headers.SentHeaders["X-Tenant"] != "acme" || headers.SentHeaders["svix-id"] != msg.Id {
+		t.Errorf("unexpected headers: %+v", headers)
+	}
+	if _, ok := headers.SentHeaders["Authorization"]; ok || len(headers.Sensitive) != 1 || headers.Sensitive[0] != "Authorization" {
+		t.Errorf("expected the authorization header to be hidden: %+v", headers)
+	}
+	if !strings.Contains(d.String(), "to "+flaky.Id+" (https://example.com/flaky) failed with 503") {
+		t.Errorf("unexpected rendering:\n%s", d)
+	}
+	if _, err := json.Marshal(d); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := client.DescribeDelivery(ctx, app.Id, "msg_missing"); err == nil {
+		t.Error("expected a missing message to fail")
+	}
+}
@@ -8,23 +8,25 @@ import (
 )
 
 type (
-	ListResponseEndpointOut   openapi.ListResponseEndpointOut
-	EndpointIn                openapi.EndpointIn
-	EndpointUpdate            openapi.EndpointUpdate
-	EndpointOut               openapi.EndpointOut
-	EndpointPatch             openapi.EndpointPatch
-	EndpointSecretOut         openapi.EndpointSecretOut
-	EndpointSecretRotateIn    openapi.EndpointSecretRotateIn
-	EndpointTransformationIn  openapi.EndpointTransformationIn
-	RecoverIn                 openapi.RecoverIn
-	ReplayIn                  openapi.ReplayIn
-	EndpointHeadersIn         openapi.EndpointHeadersIn
-	EndpointHeadersPatchIn    openapi.EndpointHeadersPatchIn
-	EndpointHeadersOut        openapi.EndpointHeadersOut
-	EndpointStats             openapi.EndpointStats
-	EndpointTransformationOut openapi.EndpointTransformationOut
-	EventExampleIn            openapi.EventExampleIn
-	Ordering                  openapi.Ordering
+	ListResponseEndpointOut           openapi.ListResponseEndpointOut
+	EndpointIn                        openapi.EndpointIn
+	EndpointUpdate                    openapi.EndpointUpdate
+	EndpointOut                       openapi.EndpointOut
+	EndpointPatch                     openapi.EndpointPatch
+	EndpointSecretOut                 openapi.EndpointSecretOut
+	EndpointSecretRotateIn            openapi.EndpointSecretRotateIn
+	EndpointTransformationIn          openapi.EndpointTransformationIn
+	RecoverIn                         openapi.RecoverIn
+	ReplayIn                          openapi.ReplayIn
+	EndpointHeadersIn                 openapi.EndpointHeadersIn
+	EndpointHeadersPatchIn            openapi.EndpointHeadersPatchIn
+	EndpointHeadersOut                openapi.EndpointHeadersOut
+	EndpointStats                     openapi.EndpointStats
+	EndpointTransformationOut         openapi.EndpointTransformationOut
+	EndpointTransformationSimulateIn  openapi.EndpointTransformationSimulateIn
+	EndpointTransformationSimulateOut openapi.EndpointTransformationSimulateOut
+	EventExampleIn                    openapi.EventExampleIn
+	Ordering                          openapi.Ordering
 )
 
 type Endpoint struct {
@@ -258,7 +260,7 @@ func (e *Endpoint) TransformationGet(ctx context.Context, appId string, endpoint
 
 func (e *Endpoint) TransformatioPartialUpdate(ctx context.Context, appId string, endpointId string, transformation *EndpointTransformationIn) error {
 	req := e.api.EndpointApi.V1EndpointTransformationPartialUpdate(ctx, appId, endpointId)
-	req.EndpointTransformationIn(openapi.EndpointTransformationIn(*transformation))
+	req = req.EndpointTransformationIn(openapi.EndpointTransformationIn(*transformation))
 
 	res, err := req.Execute()
 	if err != nil {
@@ -268,6 +270,28 @@ func (e *Endpoint) TransformatioPartialUpdate(ctx context.Context, appId string,
 	return nil
 }
 
+func (e *Endpoint) TransformationSimulate(ctx context.Context, appId string, endpointId string, simulateIn *EndpointTransformationSimulateIn) (*EndpointTransformationSimulateOut, error) {
+	return e.TransformationSimulateWithOptions(ctx, appId, endpointId, simulateIn, nil)
+}
+
+func (e *Endpoint) TransformationSimulateWithOptions(ctx context.Context, appId string, endpointId string, simulateIn *EndpointTransformationSimulateIn, options *PostOptions) (*EndpointTransformationSimulateOut, error) {
+	req := e.api.EndpointApi.V1EndpointTransformationSimulate(ctx, appId, endpointId)
+	req = req.EndpointTransformationSimulateIn(openapi.EndpointTransformationSimulateIn(*simulateIn))
+	if options != nil {
+		if options.IdempotencyKey != nil {
+			req = req.IdempotencyKey(*options.IdempotencyKey)
+		}
+	}
+
+	out, res, err := req.Execute()
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+
+	ret := EndpointTransformationSimulateOut(out)
+	return &ret, nil
+}
+
 func (e *Endpoint) SendExample(ctx context.Context, appId string, endpointId string, eventExampleIn *EventExampleIn) (*MessageOut, error) {
 	return e.SendExampleWithOptions(ctx, appId, endpointId, eventExampleIn, nil)
 }
@@ -280,11 +304,11 @@ func (e *Endpoint) SendExampleWithOptions(
 	options *PostOptions,
 ) (*MessageOut, error) {
 	req := e.api.EndpointApi.V1EndpointSendExample(ctx, appId, endpointId)
-	req.EventExampleIn(openapi.EventExampleIn(*eventExampleIn))
+	req = req.EventExampleIn(openapi.EventExampleIn(*eventExampleIn))
 
 	if options != nil {
 		if options.IdempotencyKey != nil {
-			req.IdempotencyKey(*options.IdempotencyKey)
+			req = req.IdempotencyKey(*options.IdempotencyKey)
 		}
 	}
 
@@ -0,0 +1,194 @@
+// Package eventgen generates Go types for the payloads of event types, from
+// their JSON schemas.
+//
+// For every event type, Generate emits a constant holding its name, and a
+// struct for its payload with an EventType method returning that name:
+//
+//	const EventTypeInvoicePaid = "invoice.paid"
+//
+//	type InvoicePaid struct {
+//		Amount   int64                `json:"amount"`
+//		Customer *InvoicePaidCustomer `json:"customer,omitempty"`
+//	}
+//
+//	func (InvoicePaid) EventType() string { return EventTypeInvoicePaid }
+//
+// Required properties are values, and optional ones pointers (or nil
+// slices and maps) omitted when empty. Required arrays, maps and
+// json.RawMessage values are marshaled as null when nil, which their schema
+// usually rejects: set them, if only to empty values. Nested objects and
+// string enums get their own named types, and schemas that can't be
+// expressed in Go, such as oneOf, are kept as json.RawMessage. Payloads that
+// aren't objects get a named type without an EventType method.
+//
+// Event types whose names only differ by punctuation, such as a.b and a_b,
+// get the same identifier: each of them is then suffixed with a hash of its
+// name, so that the identifiers of the other event types don't depend on
+// them.
+//
+// The svix-eventgen command runs the generator from `go generate`.
+package eventgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Options struct {
+	// The package of the generated file. Required.
+	Package string
+	// The prefix of the event type name constants. Defaults to "EventType".
+	ConstPrefix string
+	// The command recorded in the header of the generated file. Defaults
+	// to "svix-eventgen".
+	Generator string
+}
+
+// Generate returns the formatted source of a Go file with the constants and
+// types of the event types. Event types without a schema only get a
+// constant.
+func Generate(types []EventType, options *Options) ([]byte, error) {
+	var opts Options
+	if options != nil {
+		opts = *options
+	}
+	if !isIdentifier(opts.Package) {
+		return nil, fmt.Errorf("eventgen: invalid package name %q", opts.Package)
+	}
+	if opts.ConstPrefix == "" {
+		opts.ConstPrefix = "EventType"
+	}
+	if opts.Generator == "" {
+		opts.Generator = "svix-eventgen"
+	}
+	types = append([]EventType(nil), types...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	bases := map[string]int{}
+	for _, et := range types {
+		if et.Name == "" {
+			return nil, errors.New("eventgen: event type without a name")
+		}
+		bases[exportedName(et.Name)]++
+	}
+	g := &generator{names: map[string]bool{}, structs: map[string]bool{}, imports: map[string]bool{}}
+	type event struct {
+		EventType
+		constName string
+		typeName  string
+	}
+	// The names of the event types are reserved before any nested type, so
+	// that they don't depend on the schemas of the others.
+	events := make([]event, len(types))
+	for i, et := range types {
+		base := exportedName(et.Name)
+		if bases[base] > 1 {
+			h := fnv.New32a()
+			h.Write([]byte(et.Name))
+			base = fmt.Sprintf("%s_%08x", base, h.Sum32())
+		}
+		events[i] = event{EventType: et, constName: g.reserve(opts.ConstPrefix + base)}
+		if et.Schema != nil {
+			events[i].typeName = g.reserve(base)
+		}
+	}
+	for _, e := range events {
+		et := e.EventType
+		if et.Schema != nil {
+			g.root, g.rootName = et.Schema, e.typeName
+			g.refs = map[string]string{"#": e.typeName}
+			doc := fmt.Sprintf("%s is the payload of %s events.", e.typeName, et.Name)
+			if et.Description != "" {
+				doc = fmt.Sprintf("%s is the payload of %s events: %s", e.typeName, et.Name, et.Description)
+			}
+			if err := g.define(e.typeName, doc, et.Schema); err != nil {
+				return nil, fmt.Errorf("eventgen: %s: %w", et.Name, err)
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by %s; DO NOT EDIT.\n\npackage %s\n\n", opts.Generator, opts.Package)
+	if len(g.imports) != 0 {
+		var imports []string
+		for path := range g.imports {
+			imports = append(imports, strconv.Quote(path))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(&b, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	if len(events) != 0 {
+		b.WriteString("// Event type names.\nconst (\n")
+		for _, e := range events {
+			fmt.Fprintf(&b, "%s = %s\n", e.constName, strconv.Quote(e.Name))
+		}
+		b.WriteString(")\n")
+	}
+	for _, d := range g.decls {
+		b.WriteString("\n")
+		b.WriteString(d)
+	}
+	for _, e := range events {
+		if g.structs[e.typeName] {
+			fmt.Fprintf(&b, "\n// EventType returns %s.\nfunc (%s) EventType() string { return %s }\n", strconv.Quote(e.Name), e.typeName, e.constName)
+		}
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("eventgen: formatting the generated code: %w", err)
+	}
+	return src, nil
+}
+
+// exportedName turns a name such as `invoice.paid` or `customer_id` into an
+// exported Go identifier such as InvoicePaid or CustomerId.
+func exportedName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || s == "_" {
+		return false
+	}
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// comment formats text as a Go comment, one line per line of text.
+func comment(text string, indent string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString(indent)
+		b.WriteString(strings.TrimRight("// "+strings.TrimSpace(line), " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
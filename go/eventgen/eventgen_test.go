@@ -0,0 +1,247 @@
+package eventgen_test
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/eventgen"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"required": ["id", "amount", "status", "createdAt", "lines"],
+	"properties": {
+		"id": {"type": "string", "description": "The id of the invoice."},
+		"amount": {"type": "integer"},
+		"status": {"enum": ["paid", "void"]},
+		"createdAt": {"type": "string", "format": "date-time"},
+		"note": {"type": ["string", "null"]},
+		"customer": {"$ref": "#/definitions/customer"},
+		"lines": {"type": "array", "items": {"type": "object", "properties": {"sku": {"type": "string"}, "quantity": {"type": "number"}}}},
+		"metadata": {"type": "object", "additionalProperties": {"type": "string"}},
+		"discount": {"oneOf": [{"type": "number"}, {"type": "string"}]},
+		"customer_id": {"type": "string"}
+	},
+	"definitions": {
+		"customer": {
+			"description": "The customer who paid.",
+			"allOf": [
+				{"properties": {"email": {"type": "string"}}, "required": ["email"]},
+				{"properties": {"vip": {"type": "boolean"}}}
+			]
+		}
+	}
+}`
+
+func generate(t *testing.T) string {
+	t.Helper()
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(invoiceSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	src, err := eventgen.Generate([]eventgen.EventType{
+		{Name: "user.signup"},
+		{Name: "invoice.paid", Description: "An invoice was paid.", Schema: schema},
+	}, &eventgen.Options{Package: "events"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(src)
+}
+
+func TestGenerate(t *testing.T) {
+	src := generate(t)
+	// Alignment depends on the other fields.
+	spaces := regexp.MustCompile(`[ \t]+`)
+	flat := spaces.ReplaceAllString(src, " ")
+	for _, expected := range []string{
+		"// Code generated by svix-eventgen; DO NOT EDIT.\n",
+		"\tEventTypeInvoicePaid = \"invoice.paid\"\n\tEventTypeUserSignup  = \"user.signup\"\n",
+		"// InvoicePaid is the payload of invoice.paid events: An invoice was paid.\ntype InvoicePaid struct {\n",
+		"\tAmount    int64                  `json:\"amount\"`\n",
+		"\tCreatedAt time.Time              `json:\"createdAt\"`\n",
+		"\tCustomer  *InvoicePaidCustomer   `json:\"customer,omitempty\"`\n",
+		"\tCustomerId *string `json:\"customer_id,omitempty\"`\n",
+		"\tDiscount  json.RawMessage        `json:\"discount,omitempty\"`\n",
+		"\t// The id of the invoice.\n\tId string `json:\"id\"`\n",
+		"\tLines     []InvoicePaidLinesItem `json:\"lines\"`\n",
+		"\tMetadata  map[string]string      `json:\"metadata,omitempty\"`\n",
+		"\tNote      *string                `json:\"note,omitempty\"`\n",
+		"\tStatus    InvoicePaidStatus      `json:\"status\"`\n",
+		"type InvoicePaidStatus string\n",
+		"\tInvoicePaidStatusPaid InvoicePaidStatus = \"paid\"\n",
+		"// The customer who paid.\ntype InvoicePaidCustomer struct {\n\tEmail string `json:\"email\"`\n\tVip   *bool  `json:\"vip,omitempty\"`\n}\n",
+		"\tQuantity *float64 `json:\"quantity,omitempty\"`\n",
+		"func (InvoicePaid) EventType() string { return EventTypeInvoicePaid }\n",
+	} {
+		if !strings.Contains(flat, spaces.ReplaceAllString(expected, " ")) {
+			t.Errorf("expected %q in the generated code:\n%s", expected, src)
+		}
+	}
+	if strings.Contains(src, "type UserSignup") {
+		t.Error("expected no type for event types without a schema")
+	}
+
+	typeCheck(t, src)
+	if again := generate(t); again != src {
+		t.Error("expected the output to be stable")
+	}
+}
+
+// typeCheck checks that the generated code compiles.
+func typeCheck(t *testing.T, src string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "events.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("events", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+}
+
+func TestGenerateEventTypeProperty(t *testing.T) {
+	var schema map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["eventType"],
+		"properties": {
+			"eventType": {"type": "string"},
+			"data": {"type": "object", "properties": {"eventType": {"type": "string"}}}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := eventgen.Generate([]eventgen.EventType{{Name: "invoice.paid", Schema: schema}}, &eventgen.Options{Package: "events"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(out)
+	flat := regexp.MustCompile(`[ \t]+`).ReplaceAllString(src, " ")
+	if !strings.Contains(flat, " EventType2 string `json:\"eventType\"`") || !strings.Contains(flat, " EventType *string `json:\"eventType,omitempty\"`") {
+		t.Errorf("expected the property to be renamed only in the payload type:\n%s", src)
+	}
+	typeCheck(t, src)
+}
+
+func TestGenerateNames(t *testing.T) {
+	object := map[string]interface{}{"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "string"}}}
+	list := map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	generate := func(types ...eventgen.EventType) string {
+		t.Helper()
+		out, err := eventgen.Generate(types, &eventgen.Options{Package: "events"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		typeCheck(t, string(out))
+		return string(out)
+	}
+	typeName := regexp.MustCompile(`type (\w+) struct`)
+
+	before := generate(eventgen.EventType{Name: "invoice.paid", Schema: object}, eventgen.EventType{Name: "invoice_paid", Schema: object})
+	after := generate(eventgen.EventType{Name: "invoice-paid", Schema: object}, eventgen.EventType{Name: "invoice.paid", Schema: object}, eventgen.EventType{Name: "invoice_paid", Schema: object})
+	names := typeName.FindAllStringSubmatch(before, -1)
+	if len(names) != 2 || names[0][1] == names[1][1] || strings.Contains(before, "InvoicePaid2") {
+		t.Fatalf("expected colliding names to be suffixed with hashes:\n%s", before)
+	}
+	for _, name := range names {
+		if !strings.Contains(after, "type "+name[1]+" struct") {
+			t.Errorf("expected %s to keep its name when another event type collides with it:\n%s", name[1], after)
+		}
+	}
+
+	src := generate(eventgen.EventType{Name: "invoice.lines", Schema: list})
+	if !strings.Contains(src, "type InvoiceLines []string") || strings.Contains(src, "func (InvoiceLines) EventType()") {
+		t.Errorf("expected a list payload without an EventType method:\n%s", src)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		schema string
+		pkg    string
+	}{
+		"package":  {`{}`, "my-events"},
+		"ref":      {`{"properties": {"a": {"$ref": "other.json#/a"}}}`, "events"},
+		"property": {`{"properties": {"a,b": {}}}`, "events"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var schema map[string]interface{}
+			if err := json.Unmarshal([]byte(tc.schema), &schema); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := eventgen.Generate([]eventgen.EventType{{Name: "a", Schema: schema}}, &eventgen.Options{Package: tc.pkg}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestFetchAndLoad(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+	client := srv.Client()
+	schemas := map[string]map[string]interface{}{
+		"1":  {"properties": map[string]interface{}{"v1": map[string]interface{}{}}},
+		"10": {"properties": map[string]interface{}{"v10": map[string]interface{}{}}},
+		"2":  {"properties": map[string]interface{}{"v2": map[string]interface{}{}}},
+	}
+	if _, err := client.EventType.Create(ctx, &svix.EventTypeIn{Name: "invoice.paid", Description: "paid", Schemas: schemas}); err != nil {
+		t.Fatal(err)
+	}
+	archived := true
+	if _, err := client.EventType.Create(ctx, &svix.EventTypeIn{Name: "invoice.old", Description: "old", Archived: &archived}); err != nil {
+		t.Fatal(err)
+	}
+
+	fetched, err := eventgen.Fetch(ctx, client, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// svixtest has default event types.
+	var found *eventgen.EventType
+	for i := range fetched {
+		if fetched[i].Name == "invoice.old" {
+			t.Error("expected archived event types to be skipped")
+		}
+		if fetched[i].Name == "invoice.paid" {
+			found = &fetched[i]
+		}
+	}
+	if found == nil || found.Schema["properties"].(map[string]interface{})["v10"] == nil {
+		t.Fatalf("expected the latest schema of invoice.paid, got %+v", found)
+	}
+	if v2, err := eventgen.Fetch(ctx, client, "2"); err != nil || len(v2) != len(fetched) {
+		t.Fatalf("unexpected event types (%v): %+v", err, v2)
+	}
+
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	state := "eventTypes:\n  - name: invoice.paid\n    description: paid\n    schemas:\n      \"1\":\n        properties:\n          amount: {type: integer}\n"
+	if err := os.WriteFile(path, []byte(state), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := eventgen.LoadFile(path, "")
+	if err != nil || len(loaded) != 1 || loaded[0].Schema["properties"] == nil {
+		t.Fatalf("unexpected event types (%v): %+v", err, loaded)
+	}
+	src, err := eventgen.Generate(loaded, &eventgen.Options{Package: "events"})
+	if err != nil || !strings.Contains(string(src), "Amount *int64") {
+		t.Fatalf("unexpected code (%v):\n%s", err, src)
+	}
+}
@@ -0,0 +1,85 @@
+package eventgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/backup"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/reconcile"
+)
+
+// EventType is an event type to generate code for.
+type EventType struct {
+	Name        string
+	Description string
+	// The JSON schema of the payload, or nil if the event type has none.
+	Schema map[string]interface{}
+}
+
+// NewEventType returns the event type with the schema of the given version,
+// or of the latest version if version is empty.
+func NewEventType(name string, description string, schemas map[string]map[string]interface{}, version string) EventType {
+	et := EventType{Name: name, Description: description}
+	if version != "" {
+		et.Schema = schemas[version]
+		return et
+	}
+	et.Schema = schemas[svix.LatestSchemaVersion(schemas)]
+	return et
+}
+
+// Fetch lists the event types of the environment that aren't archived.
+func Fetch(ctx context.Context, client *svix.Svix, version string) ([]EventType, error) {
+	withContent := true
+	var types []EventType
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := client.EventType.List(ctx, &svix.EventTypeListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize), WithContent: &withContent})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, et := range out.Data {
+			if et.Archived != nil && *et.Archived {
+				continue
+			}
+			types = append(types, NewEventType(et.Name, et.Description, et.Schemas, version))
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// LoadFile reads the event types that aren't archived from an exported
+// environment: a backup archive (see package backup) or a desired-state file
+// (see package reconcile).
+func LoadFile(path string, version string) ([]EventType, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var types []EventType
+	if archive, err := backup.Read(bytes.NewReader(data)); err == nil {
+		for _, et := range archive.EventTypes {
+			if !et.Archived {
+				types = append(types, NewEventType(et.Name, et.Description, et.Schemas, version))
+			}
+		}
+		return types, nil
+	}
+	state, err := reconcile.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither a backup archive nor a state file: %w", path, err)
+	}
+	for _, et := range state.EventTypes {
+		if !et.Archived {
+			types = append(types, NewEventType(et.Name, et.Description, et.Schemas, version))
+		}
+	}
+	return types, nil
+}
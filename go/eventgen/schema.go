@@ -0,0 +1,330 @@
+package eventgen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generator translates JSON schemas into Go declarations.
+type generator struct {
+	// The identifiers declared at the top level.
+	names map[string]bool
+	// The types declared as structs.
+	structs map[string]bool
+	imports map[string]bool
+	decls   []string
+
+	// The schema being translated, and the types of its definitions by
+	// reference.
+	root     map[string]interface{}
+	rootName string
+	refs     map[string]string
+}
+
+func (g *generator) reserve(name string) string {
+	n := name
+	for i := 2; g.names[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	g.names[n] = true
+	return n
+}
+
+// define declares the type name, already reserved, for schema.
+func (g *generator) define(name string, doc string, schema map[string]interface{}) error {
+	var b strings.Builder
+	if doc != "" {
+		b.WriteString(comment(doc, ""))
+	}
+
+	properties, required, isStruct, err := g.objectProperties(schema)
+	if err != nil {
+		return err
+	}
+	if !isStruct {
+		if values, ok := stringEnum(schema); ok {
+			return g.defineEnum(name, doc, values)
+		}
+		typ, _, err := g.goType(schema, name+"Value")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "type %s %s\n", name, typ)
+		g.decls = append(g.decls, b.String())
+		return nil
+	}
+
+	// The declaration is reserved before the fields are translated, so
+	// that nested types come after it.
+	index := len(g.decls)
+	g.decls = append(g.decls, "")
+	g.structs[name] = true
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := map[string]bool{}
+	if name == g.rootName {
+		// Taken by the EventType method of the payload types.
+		fields["EventType"] = true
+	}
+	for _, key := range keys {
+		if strings.ContainsAny(key, "\"`,") || strings.TrimSpace(key) != key || key == "" {
+			return fmt.Errorf("unsupported property name %q", key)
+		}
+		prop, _ := properties[key].(map[string]interface{})
+		field := exportedName(key)
+		for i := 2; fields[field]; i++ {
+			field = exportedName(key) + strconv.Itoa(i)
+		}
+		fields[field] = true
+
+		typ, nullable, err := g.goType(prop, name+field)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		tag := key
+		if !required[key] {
+			tag += ",omitempty"
+		}
+		if (!required[key] || nullable) && !isNilable(typ) {
+			typ = "*" + typ
+		}
+		if desc, ok := prop["description"].(string); ok && desc != "" {
+			b.WriteString(comment(desc, "\t"))
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%s`\n", field, typ, strconv.Quote(tag))
+	}
+	b.WriteString("}\n")
+	g.decls[index] = b.String()
+	return nil
+}
+
+func (g *generator) defineEnum(name string, doc string, values []string) error {
+	var b strings.Builder
+	if doc != "" {
+		b.WriteString(comment(doc, ""))
+	}
+	fmt.Fprintf(&b, "type %s string\n\nconst (\n", name)
+	for _, v := range values {
+		fmt.Fprintf(&b, "\t%s %s = %s\n", g.reserve(name+exportedName(v)), name, strconv.Quote(v))
+	}
+	b.WriteString(")\n")
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+// objectProperties returns the properties of an object schema, merging
+// those of allOf. isStruct is false if the schema isn't an object with
+// properties.
+func (g *generator) objectProperties(schema map[string]interface{}) (properties map[string]interface{}, required map[string]bool, isStruct bool, err error) {
+	properties = map[string]interface{}{}
+	required = map[string]bool{}
+	var merge func(s map[string]interface{}, depth int) error
+	merge = func(s map[string]interface{}, depth int) error {
+		if depth > 32 {
+			return fmt.Errorf("allOf is too deeply nested")
+		}
+		if ref, ok := s["$ref"].(string); ok {
+			target, err := g.resolve(ref)
+			if err != nil {
+				return err
+			}
+			s = target
+		}
+		if props, ok := s["properties"].(map[string]interface{}); ok {
+			isStruct = true
+			for k, v := range props {
+				properties[k] = v
+			}
+		}
+		if req, ok := s["required"].([]interface{}); ok {
+			for _, r := range req {
+				if name, ok := r.(string); ok {
+					required[name] = true
+				}
+			}
+		}
+		if all, ok := s["allOf"].([]interface{}); ok {
+			for _, sub := range all {
+				if subSchema, ok := sub.(map[string]interface{}); ok {
+					if err := merge(subSchema, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	err = merge(schema, 0)
+	return properties, required, isStruct, err
+}
+
+// goType returns the Go type of a schema, declaring the named types it
+// needs with names starting with hint. nullable is set when the schema
+// allows null.
+func (g *generator) goType(schema map[string]interface{}, hint string) (typ string, nullable bool, err error) {
+	if schema == nil {
+		return "interface{}", false, nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		name, err := g.ref(ref)
+		return name, false, err
+	}
+
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	var nonNull []string
+	for _, t := range types {
+		if t == "null" {
+			nullable = true
+		} else {
+			nonNull = append(nonNull, t)
+		}
+	}
+
+	if len(nonNull) == 0 {
+		for _, key := range []string{"oneOf", "anyOf"} {
+			branches, ok := schema[key].([]interface{})
+			if !ok {
+				continue
+			}
+			var rest []map[string]interface{}
+			for _, b := range branches {
+				branch, _ := b.(map[string]interface{})
+				if branch["type"] == "null" {
+					nullable = true
+				} else {
+					rest = append(rest, branch)
+				}
+			}
+			if len(rest) == 1 {
+				typ, _, err := g.goType(rest[0], hint)
+				return typ, nullable, err
+			}
+			g.imports["encoding/json"] = true
+			return "json.RawMessage", nullable, nil
+		}
+		switch {
+		case schema["properties"] != nil || schema["allOf"] != nil || schema["additionalProperties"] != nil:
+			nonNull = []string{"object"}
+		case schema["items"] != nil:
+			nonNull = []string{"array"}
+		default:
+			if _, ok := stringEnum(schema); ok {
+				nonNull = []string{"string"}
+			}
+		}
+	}
+	if len(nonNull) != 1 {
+		return "interface{}", nullable, nil
+	}
+
+	switch nonNull[0] {
+	case "string":
+		if values, ok := stringEnum(schema); ok {
+			name := g.reserve(hint)
+			return name, nullable, g.defineEnum(name, "", values)
+		}
+		if schema["format"] == "date-time" {
+			g.imports["time"] = true
+			return "time.Time", nullable, nil
+		}
+		return "string", nullable, nil
+	case "integer":
+		return "int64", nullable, nil
+	case "number":
+		return "float64", nullable, nil
+	case "boolean":
+		return "bool", nullable, nil
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		elem, _, err := g.goType(items, hint+"Item")
+		return "[]" + elem, nullable, err
+	case "object":
+		if _, _, isStruct, err := g.objectProperties(schema); err != nil || isStruct {
+			if err != nil {
+				return "", false, err
+			}
+			name := g.reserve(hint)
+			desc, _ := schema["description"].(string)
+			return name, nullable, g.define(name, desc, schema)
+		}
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			elem, _, err := g.goType(additional, hint+"Value")
+			return "map[string]" + elem, nullable, err
+		}
+		return "map[string]interface{}", nullable, nil
+	}
+	return "interface{}", nullable, nil
+}
+
+// ref returns the type of a reference to a definition of the root schema,
+// declaring it the first time.
+func (g *generator) ref(ref string) (string, error) {
+	if name, ok := g.refs[ref]; ok {
+		return name, nil
+	}
+	target, err := g.resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	name := g.reserve(g.rootName + exportedName(ref[strings.LastIndex(ref, "/")+1:]))
+	g.refs[ref] = name
+	desc, _ := target["description"].(string)
+	return name, g.define(name, desc, target)
+}
+
+// resolve looks up a reference of the form #/definitions/name or
+// #/$defs/name in the root schema.
+func (g *generator) resolve(ref string) (map[string]interface{}, error) {
+	if ref == "#" {
+		return g.root, nil
+	}
+	for _, prefix := range []string{"#/definitions/", "#/$defs/"} {
+		if name := strings.TrimPrefix(ref, prefix); name != ref {
+			defs, _ := g.root[strings.TrimSuffix(prefix[2:], "/")].(map[string]interface{})
+			if target, ok := defs[name].(map[string]interface{}); ok {
+				return target, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unsupported reference %q", ref)
+}
+
+// stringEnum returns the values of a schema that is an enum of strings.
+func stringEnum(schema map[string]interface{}) ([]string, bool) {
+	enum, ok := schema["enum"].([]interface{})
+	if !ok || len(enum) == 0 {
+		return nil, false
+	}
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, s)
+	}
+	return values, true
+}
+
+// isNilable reports whether a Go type already has a nil value, so that it
+// doesn't need a pointer to be optional.
+func isNilable(typ string) bool {
+	return strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || strings.HasPrefix(typ, "*") ||
+		typ == "interface{}" || typ == "json.RawMessage"
+}
@@ -2,6 +2,10 @@ package svix
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/svix/svix-webhooks/go/internal/openapi"
 )
@@ -16,8 +20,16 @@ type (
 	EventTypeOut             openapi.EventTypeOut
 	EventTypePatch           openapi.EventTypePatch
 	EventTypeUpdate          openapi.EventTypeUpdate
+	EventTypeSchemaIn        openapi.EventTypeSchemaIn
 )
 
+// EventTypeExampleOut is an example generated from a schema. The generated
+// client expects every property of the example to be an object, so it's
+// decoded here instead.
+type EventTypeExampleOut struct {
+	Example map[string]interface{} `json:"example"`
+}
+
 type EventTypeListOptions struct {
 	Iterator        *string
 	Limit           *int32
@@ -106,3 +118,47 @@ func (e *EventType) Delete(ctx context.Context, eventTypeName string) error {
 	res, err := req.Execute()
 	return wrapError(err, res)
 }
+
+// GenerateExample generates a fake payload matching a JSON schema.
+func (e *EventType) GenerateExample(ctx context.Context, eventTypeSchemaIn *EventTypeSchemaIn) (*EventTypeExampleOut, error) {
+	return e.GenerateExampleWithOptions(ctx, eventTypeSchemaIn, nil)
+}
+
+func (e *EventType) GenerateExampleWithOptions(ctx context.Context, eventTypeSchemaIn *EventTypeSchemaIn, options *PostOptions) (*EventTypeExampleOut, error) {
+	body, err := json.Marshal(openapi.EventTypeSchemaIn(*eventTypeSchemaIn))
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	if options != nil && options.IdempotencyKey != nil {
+		headers["idempotency-key"] = *options.IdempotencyKey
+	}
+	var ret EventTypeExampleOut
+	path := "/api/v1/event-type/schema/generate-example/"
+	if err := rawRequest(ctx, e.api, "EventTypeApiService.V1EventTypeGenerateExample", http.MethodPost, path, headers, body, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// LatestSchemaVersion returns the highest version of the schemas of an
+// event type, or the empty string if there are none.
+func LatestSchemaVersion(schemas map[string]map[string]interface{}) string {
+	versions := make([]string, 0, len(schemas))
+	for v := range schemas {
+		versions = append(versions, v)
+	}
+	// Versions are numbers, but sort the others after them rather than fail.
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := strconv.Atoi(versions[i])
+		b, errB := strconv.Atoi(versions[j])
+		if errA != nil || errB != nil {
+			return errA == nil || (errB != nil && versions[i] < versions[j])
+		}
+		return a < b
+	})
+	if len(versions) == 0 {
+		return ""
+	}
+	return versions[len(versions)-1]
+}
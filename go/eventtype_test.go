@@ -0,0 +1,24 @@
+package svix_test
+
+import (
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+func TestLatestSchemaVersion(t *testing.T) {
+	for expected, schemas := range map[string][]string{
+		"":     nil,
+		"1":    {"1"},
+		"10":   {"2", "10", "9"},
+		"beta": {"3", "beta", "alpha"},
+	} {
+		m := map[string]map[string]interface{}{}
+		for _, version := range schemas {
+			m[version] = map[string]interface{}{}
+		}
+		if latest := svix.LatestSchemaVersion(m); latest != expected {
+			t.Errorf("expected %q to be the latest of %v, got %q", expected, schemas, latest)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package exporter exposes the delivery statistics of a Svix environment as
+// Prometheus metrics.
+//
+// An Exporter is an http.Handler serving the metrics in the Prometheus text
+// format. The metrics are collected from the API when scraped, and cached for
+// CacheTTL so that frequent or concurrent scrapes don't hammer the API.
+// Collections run in the background, bounded by CollectTimeout: a scrape
+// that gives up on a slow collection is served the previous metrics, and
+// the collection completes for the next scrape:
+//
+//	http.Handle("/metrics", exporter.New(client, &exporter.Options{PerEndpoint: true}))
+//
+// The exported metrics are:
+//
+//	svix_up                         whether the last collection succeeded
+//	svix_collect_duration_seconds   how long the last collection took
+//	svix_collect_timestamp_seconds  when the last collection happened
+//	svix_app_endpoints              endpoints per application and state (enabled or disabled)
+//	svix_app_messages               messages per application and status of their last attempt, over Window
+//	svix_app_attempts               attempts per application and result (success or failure), over Window
+//	svix_endpoint_disabled          whether an endpoint is disabled, with PerEndpoint
+//	svix_endpoint_messages          svix_app_messages per endpoint, with PerEndpoint
+//	svix_endpoint_attempts          svix_app_attempts per endpoint, with PerEndpoint
+//	svix_background_tasks           background tasks per task type and status, with BackgroundTasks
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/internal/ptr"
+)
+
+type Options struct {
+	// The applications to export, by id or uid. All of them when empty.
+	Apps []string
+	// Also export the metrics of each endpoint, and not only their sum per
+	// application.
+	PerEndpoint bool
+	// Export the number of background tasks.
+	BackgroundTasks bool
+	// The window over which messages and attempts are counted. Defaults to
+	// 1 hour.
+	Window time.Duration
+	// How long collected metrics are served before they are collected
+	// again. Defaults to 1 minute.
+	CacheTTL time.Duration
+	// How long a collection may take before it fails. Defaults to 5
+	// minutes.
+	CollectTimeout time.Duration
+	// Called with the errors of collections, which are otherwise only
+	// reported by svix_up.
+	OnError func(error)
+}
+
+// Exporter serves the metrics of a Svix environment. It must be created
+// with New.
+type Exporter struct {
+	client *svix.Svix
+	opts   Options
+
+	mu          sync.Mutex
+	body        []byte
+	collectedAt time.Time
+	// Closed when the running collection completes, nil when none is
+	// running.
+	collecting chan struct{}
+}
+
+func New(client *svix.Svix, options *Options) *Exporter {
+	e := &Exporter{client: client}
+	if options != nil {
+		e.opts = *options
+	}
+	if e.opts.Window <= 0 {
+		e.opts.Window = time.Hour
+	}
+	if e.opts.CacheTTL <= 0 {
+		e.opts.CacheTTL = time.Minute
+	}
+	if e.opts.CollectTimeout <= 0 {
+		e.opts.CollectTimeout = 5 * time.Minute
+	}
+	return e
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := e.metrics(r.Context())
+	if body == nil {
+		http.Error(w, "the first collection of metrics is still running", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(body)
+}
+
+// metrics returns the cached metrics, collecting them if they are stale.
+// Concurrent scrapes wait for a single collection. When ctx is done first,
+// the stale metrics are returned, or nil if there are none yet.
+func (e *Exporter) metrics(ctx context.Context) []byte {
+	e.mu.Lock()
+	if e.body != nil && time.Since(e.collectedAt) < e.opts.CacheTTL {
+		defer e.mu.Unlock()
+		return e.body
+	}
+	done := e.collecting
+	if done == nil {
+		done = make(chan struct{})
+		e.collecting = done
+		go e.collectInBackground(done)
+	}
+	stale := e.body
+	e.mu.Unlock()
+
+	select {
+	case <-done:
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.body
+	case <-ctx.Done():
+		return stale
+	}
+}
+
+// collectInBackground collects the metrics, independently of the scrapes
+// waiting for them, caches them and closes done.
+func (e *Exporter) collectInBackground(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.opts.CollectTimeout)
+	defer cancel()
+	start := time.Now()
+	var m metricSet
+	err := e.collect(ctx, &m)
+	up := 1.0
+	if err != nil {
+		if e.opts.OnError != nil {
+			e.opts.OnError(err)
+		}
+		// Partial metrics would look like drops in the graphs.
+		m = metricSet{}
+		up = 0
+	}
+	m.add("svix_up", "Whether the last collection of metrics from the Svix API succeeded.", up)
+	m.add("svix_collect_duration_seconds", "Duration of the last collection of metrics from the Svix API.", time.Since(start).Seconds())
+	m.add("svix_collect_timestamp_seconds", "Time of the last collection of metrics from the Svix API.", float64(start.UnixNano())/1e9)
+
+	var b bytes.Buffer
+	m.write(&b)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.body = b.Bytes()
+	// Failed collections are cached too, so that an unavailable API isn't
+	// retried on every scrape.
+	e.collectedAt = time.Now()
+	e.collecting = nil
+	close(done)
+}
+
+func (e *Exporter) collect(ctx context.Context, m *metricSet) error {
+	apps, err := e.apps(ctx)
+	if err != nil {
+		return err
+	}
+	since := time.Now().Add(-e.opts.Window)
+	for _, app := range apps {
+		appLabels := []string{"app_id", app.Id, "app_uid", ptr.Value(app.Uid.Get())}
+		if err := e.collectApp(ctx, m, app.Id, appLabels, since); err != nil {
+			return err
+		}
+	}
+	if e.opts.BackgroundTasks {
+		return e.collectTasks(ctx, m)
+	}
+	return nil
+}
+
+func (e *Exporter) apps(ctx context.Context) ([]*svix.ApplicationOut, error) {
+	var apps []*svix.ApplicationOut
+	if len(e.opts.Apps) != 0 {
+		for _, id := range e.opts.Apps {
+			app, err := e.client.Application.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			apps = append(apps, app)
+		}
+		return apps, nil
+	}
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := e.client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range out.Data {
+			app := svix.ApplicationOut(out.Data[i])
+			apps = append(apps, &app)
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+var messageStatuses = []string{"success", "fail", "pending", "sending"}
+
+func (e *Exporter) collectApp(ctx context.Context, m *metricSet, appId string, appLabels []string, since time.Time) error {
+	var enabled, disabled float64
+	var messages [4]int64
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := e.client.Endpoint.List(ctx, appId, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, ep := range out.Data {
+			isDisabled := ep.Disabled != nil && *ep.Disabled
+			if isDisabled {
+				disabled++
+			} else {
+				enabled++
+			}
+			stats, err := e.client.Endpoint.GetStatsWithOptions(ctx, appId, ep.Id, svix.EndpointStatsOptions{Since: &since})
+			if err != nil {
+				return nil, false, err
+			}
+			counts := [4]int64{stats.Success, stats.Fail, stats.Pending, stats.Sending}
+			for i := range counts {
+				messages[i] += counts[i]
+			}
+			if !e.opts.PerEndpoint {
+				continue
+			}
+
+			labels := append(appLabels, "endpoint_id", ep.Id, "endpoint_uid", ptr.Value(ep.Uid.Get()))
+			m.add("svix_endpoint_disabled", "Whether the endpoint is disabled.", gaugeValue(isDisabled), labels...)
+			for i, status := range messageStatuses {
+				m.add("svix_endpoint_messages", "Messages sent to the endpoint over the window, by status of their last attempt.", float64(counts[i]), append(labels, "status", status)...)
+			}
+			attempts, err := e.client.Statistics.EndpointAttempts(ctx, appId, ep.Id, &svix.AttemptStatisticsOptions{StartDate: &since})
+			if err != nil {
+				return nil, false, err
+			}
+			addAttempts(m, "svix_endpoint_attempts", "Delivery attempts to the endpoint over the window, by result.", attempts, labels)
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.add("svix_app_endpoints", "Endpoints of the application, by state.", enabled, append(appLabels, "state", "enabled")...)
+	m.add("svix_app_endpoints", "Endpoints of the application, by state.", disabled, append(appLabels, "state", "disabled")...)
+	for i, status := range messageStatuses {
+		m.add("svix_app_messages", "Messages sent to the endpoints of the application over the window, by status of their last attempt.", float64(messages[i]), append(appLabels, "status", status)...)
+	}
+	attempts, err := e.client.Statistics.AppAttempts(ctx, appId, &svix.AttemptStatisticsOptions{StartDate: &since})
+	if err != nil {
+		return err
+	}
+	addAttempts(m, "svix_app_attempts", "Delivery attempts of the application over the window, by result.", attempts, appLabels)
+	return nil
+}
+
+func addAttempts(m *metricSet, name string, help string, stats *svix.AttemptStatisticsResponse, labels []string) {
+	var success, failure float64
+	for _, n := range stats.Data.SuccessCount {
+		success += float64(n)
+	}
+	for _, n := range stats.Data.FailureCount {
+		failure += float64(n)
+	}
+	m.add(name, help, success, append(labels, "result", "success")...)
+	m.add(name, help, failure, append(labels, "result", "failure")...)
+}
+
+func (e *Exporter) collectTasks(ctx context.Context, m *metricSet) error {
+	type key struct {
+		task   openapi.BackgroundTaskType
+		status openapi.BackgroundTaskStatus
+	}
+	counts := map[key]float64{}
+	for _, task := range []openapi.BackgroundTaskType{
+		openapi.BACKGROUNDTASKTYPE_ENDPOINT_REPLAY,
+		openapi.BACKGROUNDTASKTYPE_ENDPOINT_RECOVER,
+		openapi.BACKGROUNDTASKTYPE_APPLICATION_STATS,
+		openapi.BACKGROUNDTASKTYPE_MESSAGE_BROADCAST,
+	} {
+		for _, status := range []openapi.BackgroundTaskStatus{
+			openapi.BACKGROUNDTASKSTATUS_RUNNING,
+			openapi.BACKGROUNDTASKSTATUS_FINISHED,
+			openapi.BACKGROUNDTASKSTATUS_FAILED,
+		} {
+			counts[key{task, status}] = 0
+		}
+	}
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := e.client.BackgroundTask.List(ctx, &svix.BackgroundTaskListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, t := range out.Data {
+			counts[key{t.Task, t.Status}]++
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return err
+	}
+	for k, n := range counts {
+		m.add("svix_background_tasks", "Background tasks, by task type and status.", n, "task", string(k.task), "status", string(k.status))
+	}
+	return nil
+}
+
+// gaugeValue returns the value of a boolean gauge.
+func gaugeValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,163 @@
+package exporter_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/exporter"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected response: %d %s", rec.Code, rec.Header())
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestExporter(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			if strings.Contains(d.Url, "down") {
+				return 500, ""
+			}
+			return 200, ""
+		},
+	})
+	defer srv.Close()
+	ctx := context.Background()
+	client := srv.Client()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app", Uid: *svix.NullableString(svix.String("shop"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	down, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://down.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled := true
+	if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://up.example.com", Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://up.example.com/2"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{EventType: "user.signup", Payload: map[string]interface{}{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.WaitForDeliveries()
+	if err := client.Endpoint.Recover(ctx, app.Id, down.Id, &svix.RecoverIn{Since: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	srv.WaitForDeliveries()
+
+	transport := &countingTransport{}
+	counted := svix.New("testsk_exporter", &svix.SvixOptions{ServerUrl: srv.URL(), HTTPClient: &http.Client{Transport: transport}})
+	e := exporter.New(counted, &exporter.Options{Apps: []string{"shop"}, PerEndpoint: true, BackgroundTasks: true})
+	body := scrape(t, e)
+	appLabels := `app_id="` + app.Id + `",app_uid="shop"`
+	downLabels := appLabels + `,endpoint_id="` + down.Id + `",endpoint_uid=""`
+	for _, line := range []string{
+		"# TYPE svix_up gauge\nsvix_up 1\n",
+		"svix_app_endpoints{" + appLabels + `,state="disabled"} 1` + "\n",
+		"svix_app_endpoints{" + appLabels + `,state="enabled"} 2` + "\n",
+		"svix_app_messages{" + appLabels + `,status="fail"} 3` + "\n",
+		"svix_app_messages{" + appLabels + `,status="success"} 3` + "\n",
+		// The recovered messages failed again.
+		"svix_app_attempts{" + appLabels + `,result="failure"} 6` + "\n",
+		"svix_endpoint_disabled{" + downLabels + "} 0\n",
+		"svix_endpoint_messages{" + downLabels + `,status="fail"} 3` + "\n",
+		"svix_endpoint_attempts{" + downLabels + `,result="success"} 0` + "\n",
+		`svix_background_tasks{task="endpoint.recover",status="finished"} 1` + "\n",
+		`svix_background_tasks{task="message.broadcast",status="running"} 0` + "\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected %q in the metrics:\n%s", line, body)
+		}
+	}
+
+	// Scrapes within the cache TTL don't query the API.
+	n := transport.requests.Load()
+	if scrape(t, e) != body || transport.requests.Load() != n {
+		t.Error("expected the metrics to be cached")
+	}
+}
+
+func TestExporterError(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	var reported error
+	e := exporter.New(srv.Client(), &exporter.Options{
+		Apps:    []string{"missing"},
+		OnError: func(err error) { reported = err },
+	})
+	body := scrape(t, e)
+	var svixErr *svix.Error
+	if !strings.Contains(body, "\nsvix_up 0\n") || strings.Contains(body, "svix_app_") || !errors.As(reported, &svixErr) || svixErr.Status() != 404 {
+		t.Errorf("unexpected metrics (%v):\n%s", reported, body)
+	}
+}
+
+// slowHandler delays the API requests until release is closed.
+type slowHandler struct {
+	next    http.Handler
+	release chan struct{}
+}
+
+func (h *slowHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	<-h.release
+	h.next.ServeHTTP(w, r)
+}
+
+func TestExporterScrapeTimeout(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	if _, err := srv.Client().Application.Create(context.Background(), &svix.ApplicationIn{Name: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	slow := &slowHandler{next: srv, release: make(chan struct{})}
+	api := httptest.NewServer(slow)
+	defer api.Close()
+	serverUrl, _ := url.Parse(api.URL)
+	client := svix.New("test_token", &svix.SvixOptions{ServerUrl: serverUrl})
+	e := exporter.New(client, nil)
+
+	// The scrape gives up before the collection completes...
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil).WithContext(ctx))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 without metrics, got %d", rec.Code)
+	}
+
+	// ...which isn't cancelled, and serves the next scrape.
+	close(slow.release)
+	if body := scrape(t, e); !strings.Contains(body, "\nsvix_up 1\n") {
+		t.Errorf("unexpected metrics:\n%s", body)
+	}
+}
@@ -0,0 +1,88 @@
+package exporter
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricSet holds gauges to be written in the Prometheus text format.
+type metricSet struct {
+	families []*family
+	byName   map[string]*family
+}
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	labels string
+	value  float64
+}
+
+// add records a sample of the gauge name, with labels given as name-value
+// pairs.
+func (m *metricSet) add(name string, help string, value float64, labels ...string) {
+	f := m.byName[name]
+	if f == nil {
+		if m.byName == nil {
+			m.byName = map[string]*family{}
+		}
+		f = &family{name: name, help: help}
+		m.byName[name] = f
+		m.families = append(m.families, f)
+	}
+	var b strings.Builder
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(labels[i+1]))
+		b.WriteByte('"')
+	}
+	f.samples = append(f.samples, sample{labels: b.String(), value: value})
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// write writes the metrics in the order their families were first added,
+// with the samples of each family sorted by labels.
+func (m *metricSet) write(w io.Writer) {
+	for _, f := range m.families {
+		io.WriteString(w, "# HELP "+f.name+" "+helpEscaper.Replace(f.help)+"\n")
+		io.WriteString(w, "# TYPE "+f.name+" gauge\n")
+		sort.SliceStable(f.samples, func(i, j int) bool { return f.samples[i].labels < f.samples[j].labels })
+		for _, s := range f.samples {
+			line := f.name
+			if s.labels != "" {
+				line += "{" + s.labels + "}"
+			}
+			io.WriteString(w, line+" "+formatValue(s.value)+"\n")
+		}
+	}
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
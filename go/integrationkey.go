@@ -0,0 +1,118 @@
+package svix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WithToken returns a client with the same options as s, authenticated
+// with another token.
+func (s *Svix) WithToken(token string) *Svix {
+	return New(token, s.options)
+}
+
+// IntegrationClient returns a client authenticated with the key of an
+// integration, which only gives access to the API of its application.
+func (s *Svix) IntegrationClient(ctx context.Context, appId string, integId string) (*Svix, error) {
+	key, err := s.Integration.GetKey(ctx, appId, integId)
+	if err != nil {
+		return nil, err
+	}
+	return s.WithToken(key.Key), nil
+}
+
+type IntegrationKeyRotationOptions struct {
+	IdempotencyKey *string
+	// The number of times the new key is tried before giving up, as it may
+	// take a moment to be accepted. Defaults to 3.
+	VerifyAttempts int
+	// The time between two tries. Defaults to 1 second.
+	VerifyInterval time.Duration
+}
+
+// IntegrationKeyRotation reports the rotation of the key of an integration.
+type IntegrationKeyRotation struct {
+	AppId         string    `json:"appId"`
+	IntegrationId string    `json:"integrationId"`
+	Key           string    `json:"key"`
+	RotatedAt     time.Time `json:"rotatedAt"`
+	// Whether a request authenticated with the new key succeeded.
+	Verified bool `json:"verified"`
+	// Whether a request authenticated with the previous key failed after
+	// the rotation.
+	PreviousKeyRejected bool `json:"previousKeyRejected"`
+}
+
+func (r *IntegrationKeyRotation) String() string {
+	verified := "verified"
+	if !r.Verified {
+		verified = "not verified"
+	}
+	previous := "the previous key is rejected"
+	if !r.PreviousKeyRejected {
+		previous = "the previous key still works"
+	}
+	return fmt.Sprintf("rotated the key of integration %s of application %s at %s: the new key is %s, %s",
+		r.IntegrationId, r.AppId, r.RotatedAt.Format(time.RFC3339), verified, previous)
+}
+
+// RotateIntegrationKey rotates the key of an integration, and checks that
+// the new key works by listing the endpoints of the application with it.
+//
+// When the new key can't be verified, the key has still been rotated: the
+// report is returned with an error.
+func (s *Svix) RotateIntegrationKey(ctx context.Context, appId string, integId string, options *IntegrationKeyRotationOptions) (*IntegrationKeyRotation, error) {
+	opts := IntegrationKeyRotationOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.VerifyAttempts <= 0 {
+		opts.VerifyAttempts = 3
+	}
+	if opts.VerifyInterval <= 0 {
+		opts.VerifyInterval = time.Second
+	}
+
+	previous, err := s.Integration.GetKey(ctx, appId, integId)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.Integration.RotateKeyWithOptions(ctx, appId, integId, &PostOptions{IdempotencyKey: opts.IdempotencyKey})
+	if err != nil {
+		return nil, err
+	}
+	report := &IntegrationKeyRotation{
+		AppId:         appId,
+		IntegrationId: integId,
+		Key:           key.Key,
+		RotatedAt:     time.Now(),
+	}
+
+	check := func(token string) error {
+		_, err := s.WithToken(token).Endpoint.List(ctx, appId, &EndpointListOptions{Limit: Int32(1)})
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		err = check(key.Key)
+		if err == nil || attempt == opts.VerifyAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-time.After(opts.VerifyInterval):
+		}
+	}
+	if err != nil {
+		return report, fmt.Errorf("the key of integration %s was rotated, but the new key doesn't work: %w", integId, err)
+	}
+	report.Verified = true
+	var svixErr *Error
+	if err := check(previous.Key); errors.As(err, &svixErr) {
+		report.PreviousKeyRejected = svixErr.Status() == http.StatusUnauthorized || svixErr.Status() == http.StatusForbidden
+	}
+	return report, nil
+}
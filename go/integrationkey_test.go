@@ -0,0 +1,57 @@
+package svix_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestIntegrationKeys(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{Token: "testsk_admin"})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	var apps []*svix.ApplicationOut
+	for _, name := range []string{"partner", "other"} {
+		app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		apps = append(apps, app)
+	}
+	integ, err := client.Integration.Create(ctx, apps[0].Id, &svix.IntegrationIn{Name: "partner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scoped, err := client.IntegrationClient(ctx, apps[0].Id, integ.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scoped.Endpoint.Create(ctx, apps[0].Id, &svix.EndpointIn{Url: "https://example.com/webhook"}); err != nil {
+		t.Fatal(err)
+	}
+	var svixErr *svix.Error
+	if _, err := scoped.Endpoint.List(ctx, apps[1].Id, nil); !errors.As(err, &svixErr) || svixErr.Status() != http.StatusUnauthorized {
+		t.Errorf("expected the key to be refused for another application, got %v", err)
+	}
+
+	report, err := client.RotateIntegrationKey(ctx, apps[0].Id, integ.Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := client.Integration.GetKey(ctx, apps[0].Id, integ.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Verified || !report.PreviousKeyRejected || report.Key != key.Key || report.IntegrationId != integ.Id {
+		t.Errorf("unexpected report: %s", report)
+	}
+	if _, err := scoped.Endpoint.List(ctx, apps[0].Id, nil); err == nil {
+		t.Error("expected the previous key to be refused")
+	}
+}
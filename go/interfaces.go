@@ -0,0 +1,164 @@
+package svix
+
+import (
+	"context"
+)
+
+//go:generate go run ./internal/mockgen -in interfaces.go -out svixmock/mocks.go
+
+// The interfaces below are implemented by the resources of Svix. Code that
+// depends on them instead of the concrete types can be tested with the mocks
+// in the svixmock package.
+
+type AuthenticationAPI interface {
+	AppPortalAccess(ctx context.Context, appId string, appPortalAccessIn *AppPortalAccessIn) (*AppPortalAccessOut, error)
+	AppPortalAccessWithOptions(ctx context.Context, appId string, appPortalAccessIn *AppPortalAccessIn, options *PostOptions) (*AppPortalAccessOut, error)
+	DashboardAccess(ctx context.Context, appId string) (*DashboardAccessOut, error)
+	DashboardAccessWithOptions(ctx context.Context, appId string, options *PostOptions) (*DashboardAccessOut, error)
+	ExchangeOneTimeToken(ctx context.Context, oneTimeTokenIn *OneTimeTokenIn) (*OneTimeTokenOut, error)
+	ExchangeOneTimeTokenWithOptions(ctx context.Context, oneTimeTokenIn *OneTimeTokenIn, options *PostOptions) (*OneTimeTokenOut, error)
+	ExpireAll(ctx context.Context, appId string, applicationTokenExpireIn *ApplicationTokenExpireIn) error
+	ExpireAllWithOptions(ctx context.Context, appId string, applicationTokenExpireIn *ApplicationTokenExpireIn, options *PostOptions) error
+	Logout(ctx context.Context) error
+	LogoutWithOptions(ctx context.Context, options *PostOptions) error
+}
+
+type ApplicationAPI interface {
+	List(ctx context.Context, options *ApplicationListOptions) (*ListResponseApplicationOut, error)
+	Create(ctx context.Context, applicationIn *ApplicationIn) (*ApplicationOut, error)
+	CreateWithOptions(ctx context.Context, applicationIn *ApplicationIn, options *PostOptions) (*ApplicationOut, error)
+	GetOrCreate(ctx context.Context, applicationIn *ApplicationIn) (*ApplicationOut, error)
+	GetOrCreateWithOptions(ctx context.Context, applicationIn *ApplicationIn, options *PostOptions) (*ApplicationOut, error)
+	Get(ctx context.Context, appId string) (*ApplicationOut, error)
+	Update(ctx context.Context, appId string, applicationIn *ApplicationIn) (*ApplicationOut, error)
+	Patch(ctx context.Context, appId string, applicationPatch *ApplicationPatch) (*ApplicationOut, error)
+	Delete(ctx context.Context, appId string) error
+}
+
+type BackgroundTaskAPI interface {
+	List(ctx context.Context, options *BackgroundTaskListOptions) (*ListResponseBackgroundTaskOut, error)
+	Get(ctx context.Context, taskId string) (*BackgroundTaskOut, error)
+}
+
+type EndpointAPI interface {
+	List(ctx context.Context, appId string, options *EndpointListOptions) (*ListResponseEndpointOut, error)
+	Create(ctx context.Context, appId string, endpointIn *EndpointIn) (*EndpointOut, error)
+	CreateWithOptions(ctx context.Context, appId string, endpointIn *EndpointIn, options *PostOptions) (*EndpointOut, error)
+	Get(ctx context.Context, appId string, endpointId string) (*EndpointOut, error)
+	Update(ctx context.Context, appId string, endpointId string, endpointUpdate *EndpointUpdate) (*EndpointOut, error)
+	Patch(ctx context.Context, appId string, endpointId string, endpointPatch *EndpointPatch) (*EndpointOut, error)
+	Delete(ctx context.Context, appId string, endpointId string) error
+	GetSecret(ctx context.Context, appId string, endpointId string) (*EndpointSecretOut, error)
+	RotateSecret(ctx context.Context, appId string, endpointId string, endpointSecretRotateIn *EndpointSecretRotateIn) error
+	RotateSecretWithOptions(ctx context.Context, appId string, endpointId string, endpointSecretRotateIn *EndpointSecretRotateIn, options *PostOptions) error
+	Recover(ctx context.Context, appId string, endpointId string, recoverIn *RecoverIn) error
+	RecoverWithOptions(ctx context.Context, appId string, endpointId string, recoverIn *RecoverIn, options *PostOptions) error
+	GetHeaders(ctx context.Context, appId string, endpointId string) (*EndpointHeadersOut, error)
+	UpdateHeaders(ctx context.Context, appId string, endpointId string, endpointHeadersIn *EndpointHeadersIn) error
+	PatchHeaders(ctx context.Context, appId string, endpointId string, endpointHeadersIn *EndpointHeadersPatchIn) error
+	GetStats(ctx context.Context, appId string, endpointId string) (*EndpointStats, error)
+	GetStatsWithOptions(ctx context.Context, appId string, endpointId string, options EndpointStatsOptions) (*EndpointStats, error)
+	ReplayMissing(ctx context.Context, appId string, endpointId string, replayIn *ReplayIn) error
+	ReplayMissingWithOptions(ctx context.Context, appId string, endpointId string, replayIn *ReplayIn, options *PostOptions) error
+	TransformationGet(ctx context.Context, appId string, endpointId string) (*EndpointTransformationOut, error)
+	TransformatioPartialUpdate(ctx context.Context, appId string, endpointId string, transformation *EndpointTransformationIn) error
+	TransformationSimulate(ctx context.Context, appId string, endpointId string, simulateIn *EndpointTransformationSimulateIn) (*EndpointTransformationSimulateOut, error)
+	TransformationSimulateWithOptions(ctx context.Context, appId string, endpointId string, simulateIn *EndpointTransformationSimulateIn, options *PostOptions) (*EndpointTransformationSimulateOut, error)
+	SendExample(ctx context.Context, appId string, endpointId string, eventExampleIn *EventExampleIn) (*MessageOut, error)
+	SendExampleWithOptions(ctx context.Context, appId string, endpointId string, eventExampleIn *EventExampleIn, options *PostOptions) (*MessageOut, error)
+}
+
+type EventTypeAPI interface {
+	List(ctx context.Context, options *EventTypeListOptions) (*ListResponseEventTypeOut, error)
+	Create(ctx context.Context, eventTypeIn *EventTypeIn) (*EventTypeOut, error)
+	CreateWithOptions(ctx context.Context, eventTypeIn *EventTypeIn, options *PostOptions) (*EventTypeOut, error)
+	Get(ctx context.Context, eventTypeName string) (*EventTypeOut, error)
+	Update(ctx context.Context, eventTypeName string, eventTypeUpdate *EventTypeUpdate) (*EventTypeOut, error)
+	Patch(ctx context.Context, eventTypeName string, eventTypePatch *EventTypePatch) (*EventTypeOut, error)
+	Delete(ctx context.Context, eventTypeName string) error
+	GenerateExample(ctx context.Context, eventTypeSchemaIn *EventTypeSchemaIn) (*EventTypeExampleOut, error)
+	GenerateExampleWithOptions(ctx context.Context, eventTypeSchemaIn *EventTypeSchemaIn, options *PostOptions) (*EventTypeExampleOut, error)
+}
+
+type IntegrationAPI interface {
+	List(ctx context.Context, appId string, options *IntegrationListOptions) (*ListResponseIntegrationOut, error)
+	Create(ctx context.Context, appId string, integrationIn *IntegrationIn) (*IntegrationOut, error)
+	CreateWithOptions(ctx context.Context, appId string, integrationIn *IntegrationIn, options *PostOptions) (*IntegrationOut, error)
+	Get(ctx context.Context, appId string, integId string) (*IntegrationOut, error)
+	Update(ctx context.Context, appId string, integId string, integrationUpdate *IntegrationUpdate) (*IntegrationOut, error)
+	Delete(ctx context.Context, appId string, integId string) error
+	GetKey(ctx context.Context, appId string, integId string) (*IntegrationKeyOut, error)
+	RotateKey(ctx context.Context, appId string, integId string) (*IntegrationKeyOut, error)
+	RotateKeyWithOptions(ctx context.Context, appId string, integId string, options *PostOptions) (*IntegrationKeyOut, error)
+}
+
+type MessageAPI interface {
+	List(ctx context.Context, appId string, options *MessageListOptions) (*ListResponseMessageOut, error)
+	Create(ctx context.Context, appId string, messageIn *MessageIn) (*MessageOut, error)
+	CreateWithOptions(ctx context.Context, appId string, messageIn *MessageIn, options *PostOptions) (*MessageOut, error)
+	Get(ctx context.Context, appId string, msgId string) (*MessageOut, error)
+	ExpungeContent(ctx context.Context, appId string, msgId string) error
+	CreateRaw(ctx context.Context, appId string, messageIn *RawMessageIn, options *PostOptions) (*RawMessageOut, error)
+	GetRaw(ctx context.Context, appId string, msgId string) (*RawMessageOut, error)
+}
+
+type MessageAttemptAPI interface {
+	List(ctx context.Context, appId string, msgId string, options *MessageAttemptListOptions) (*ListResponseMessageAttemptOut, error)
+	ListByMsg(ctx context.Context, appId string, msgId string, options *MessageAttemptListOptions) (*ListResponseMessageAttemptOut, error)
+	ListByEndpoint(ctx context.Context, appId string, endpointId string, options *MessageAttemptListOptions) (*ListResponseMessageAttemptOut, error)
+	Get(ctx context.Context, appId string, msgId string, attemptID string) (*MessageAttemptOut, error)
+	GetHeaders(ctx context.Context, appId string, msgId string, attemptId string) (*MessageAttemptHeadersOut, error)
+	Resend(ctx context.Context, appId string, msgId string, endpointId string) error
+	ResendWithOptions(ctx context.Context, appId string, msgId string, endpointId string, options *PostOptions) error
+	ListAttemptedMessages(ctx context.Context, appId string, endpointId string, options *MessageAttemptListOptions) (*ListResponseEndpointMessageOut, error)
+	ListAttemptedMessagesRaw(ctx context.Context, appId string, endpointId string, options *MessageAttemptListOptions) (*ListResponseRawEndpointMessageOut, error)
+	ListAttemptedDestinations(ctx context.Context, appId string, msgId string, options *MessageAttemptListOptions) (*ListResponseMessageEndpointOut, error)
+	ListAttemptsForEndpoint(ctx context.Context, appId string, msgId string, endpointId string, options *MessageAttemptListOptions) (*ListResponseMessageAttemptEndpointOut, error)
+	ExpungeContent(ctx context.Context, appId string, msgId string, attemptId string) error
+}
+
+type StatisticsAPI interface {
+	AppAttempts(ctx context.Context, appId string, options *AttemptStatisticsOptions) (*AttemptStatisticsResponse, error)
+	EndpointAttempts(ctx context.Context, appId string, endpointId string, options *AttemptStatisticsOptions) (*AttemptStatisticsResponse, error)
+}
+
+var (
+	_ AuthenticationAPI = (*Authentication)(nil)
+	_ ApplicationAPI    = (*Application)(nil)
+	_ BackgroundTaskAPI = (*BackgroundTask)(nil)
+	_ EndpointAPI       = (*Endpoint)(nil)
+	_ EventTypeAPI      = (*EventType)(nil)
+	_ IntegrationAPI    = (*Integration)(nil)
+	_ MessageAPI        = (*Message)(nil)
+	_ MessageAttemptAPI = (*MessageAttempt)(nil)
+	_ StatisticsAPI     = (*Statistics)(nil)
+)
+
+// SvixAPI is the counterpart of Svix where every resource is an interface,
+// so that any of them can be swapped for a fake.
+type SvixAPI struct {
+	Authentication AuthenticationAPI
+	Application    ApplicationAPI
+	BackgroundTask BackgroundTaskAPI
+	Endpoint       EndpointAPI
+	EventType      EventTypeAPI
+	Integration    IntegrationAPI
+	Message        MessageAPI
+	MessageAttempt MessageAttemptAPI
+	Statistics     StatisticsAPI
+}
+
+// API returns a SvixAPI backed by the resources of svx.
+func (svx *Svix) API() *SvixAPI {
+	return &SvixAPI{
+		Authentication: svx.Authentication,
+		Application:    svx.Application,
+		BackgroundTask: svx.BackgroundTask,
+		Endpoint:       svx.Endpoint,
+		EventType:      svx.EventType,
+		Integration:    svx.Integration,
+		Message:        svx.Message,
+		MessageAttempt: svx.MessageAttempt,
+		Statistics:     svx.Statistics,
+	}
+}
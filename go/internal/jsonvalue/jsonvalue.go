@@ -0,0 +1,154 @@
+// Package jsonvalue works with decoded JSON values, as shared by the schema
+// packages.
+package jsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Normalize converts v to the values of encoding/json, with numbers as
+// json.Number so that they are compared exactly.
+func Normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Rat converts a JSON number to a rational, exactly.
+func Rat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(n) == nil {
+			return nil, false
+		}
+		return r, true
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	}
+	return nil, false
+}
+
+// RatString formats r as an integer, or else as a float.
+func RatString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Equal compares normalized values, with numbers compared by value.
+func Equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		ra, okA := Rat(a)
+		rb, okB := Rat(b)
+		return okA && okB && ra.Cmp(rb) == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !Equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, va := range a {
+			vb, ok := b[k]
+			if !ok || !Equal(va, vb) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// Describe formats v as JSON for messages.
+func Describe(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// DescribeList formats the first values of a list for messages.
+func DescribeList(values []interface{}) string {
+	const max = 10
+	parts := make([]string, 0, max+1)
+	for i, v := range values {
+		if i == max {
+			parts = append(parts, fmt.Sprintf("(%d more)", len(values)-max))
+			break
+		}
+		parts = append(parts, Describe(v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// EscapePointer escapes s as a token of a JSON pointer.
+func EscapePointer(s string) string {
+	return pointerEscaper.Replace(s)
+}
+
+// Lookup returns the value at a JSON pointer.
+func Lookup(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%q isn't a JSON pointer", pointer)
+	}
+	v := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = pointerUnescaper.Replace(token)
+		switch container := v.(type) {
+		case map[string]interface{}:
+			child, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("nothing at %q", pointer)
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(container) {
+				return nil, fmt.Errorf("nothing at %q", pointer)
+			}
+			v = container[i]
+		default:
+			return nil, fmt.Errorf("nothing at %q", pointer)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,42 @@
+package jsonvalue
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	a, _ := Normalize(map[string]interface{}{"n": 1, "list": []interface{}{"a", 2.5}})
+	b, _ := Normalize(map[string]interface{}{"n": 1.0, "list": []interface{}{"a", 2.50}})
+	if !Equal(a, b) {
+		t.Errorf("expected %v and %v to be equal", a, b)
+	}
+	c, _ := Normalize(map[string]interface{}{"n": 2, "list": []interface{}{"a", 2.5}})
+	if Equal(a, c) {
+		t.Errorf("expected %v and %v to differ", a, c)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	doc, _ := Normalize(map[string]interface{}{
+		"a/b": map[string]interface{}{"~c": []interface{}{"x", "y"}},
+	})
+	v, err := Lookup(doc, "/"+EscapePointer("a/b")+"/"+EscapePointer("~c")+"/1")
+	if err != nil || v != "y" {
+		t.Errorf("unexpected value %v (%v)", v, err)
+	}
+	for _, pointer := range []string{"a", "/missing", "/a~1b/~0c/2"} {
+		if _, err := Lookup(doc, pointer); err == nil {
+			t.Errorf("expected %q not to be found", pointer)
+		}
+	}
+}
+
+func TestDescribeList(t *testing.T) {
+	values := make([]interface{}, 12)
+	for i := range values {
+		values[i] = i
+	}
+	if s := DescribeList(values); s != "0, 1, 2, 3, 4, 5, 6, 7, 8, 9, (2 more)" {
+		t.Errorf("unexpected description %q", s)
+	}
+}
@@ -0,0 +1,184 @@
+// Command mockgen generates the svixmock package from the resource interfaces
+// declared in the svix package.
+//
+// For every interface `XxxAPI` it emits a struct of the same name with one
+// `MethodFunc` field per method, that records its calls and delegates to the
+// corresponding field.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Import paths of the packages that may be referenced by interface methods.
+var knownImports = map[string]string{
+	"context": "context",
+	"http":    "net/http",
+	"json":    "encoding/json",
+	"time":    "time",
+}
+
+func main() {
+	in := flag.String("in", "interfaces.go", "file declaring the interfaces")
+	out := flag.String("out", "svixmock/mocks.go", "generated file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, *in, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imports := map[string]bool{"sync": true}
+	var body bytes.Buffer
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			iface, ok := ts.Type.(*ast.InterfaceType)
+			if !ok || !strings.HasSuffix(ts.Name.Name, "API") {
+				continue
+			}
+			names = append(names, ts.Name.Name)
+			writeMock(&body, ts.Name.Name, iface, imports)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by internal/mockgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package svixmock\n\nimport (\n")
+	var paths []string
+	for pkg := range imports {
+		path := pkg
+		if p, ok := knownImports[pkg]; ok {
+			path = p
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	fmt.Fprintf(&buf, "\n\tsvix \"github.com/svix/svix-webhooks/go\"\n)\n\n")
+	fmt.Fprintf(&buf, "var (\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t_ svix.%s = (*%s)(nil)\n", name, name)
+	}
+	fmt.Fprintf(&buf, ")\n\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeMock(w *bytes.Buffer, name string, iface *ast.InterfaceType, imports map[string]bool) {
+	fmt.Fprintf(w, "// %s is a mock implementation of svix.%s.\n", name, name)
+	fmt.Fprintf(w, "// Calling a method whose Func field is nil panics.\n")
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	type method struct {
+		name    string
+		params  []string
+		args    []string
+		results string
+	}
+	var methods []method
+	for _, field := range iface.Methods.List {
+		fn := field.Type.(*ast.FuncType)
+		m := method{name: field.Names[0].Name}
+		for i, p := range fn.Params.List {
+			typ := typeString(p.Type, imports)
+			if len(p.Names) == 0 {
+				arg := fmt.Sprintf("p%d", i)
+				m.params = append(m.params, arg+" "+typ)
+				m.args = append(m.args, arg)
+			}
+			for _, n := range p.Names {
+				m.params = append(m.params, n.Name+" "+typ)
+				m.args = append(m.args, n.Name)
+			}
+		}
+		if fn.Results != nil {
+			var results []string
+			for _, r := range fn.Results.List {
+				results = append(results, typeString(r.Type, imports))
+			}
+			m.results = strings.Join(results, ", ")
+			if len(results) > 1 {
+				m.results = "(" + m.results + ")"
+			}
+		}
+		methods = append(methods, m)
+		fmt.Fprintf(w, "\t%sFunc func(%s) %s\n", m.name, strings.Join(m.params, ", "), m.results)
+	}
+	fmt.Fprintf(w, "\n\tmu sync.Mutex\n\tcalls []Call\n}\n\n")
+
+	fmt.Fprintf(w, "// Calls returns the calls made to the mock so far.\n")
+	fmt.Fprintf(w, "func (m *%s) Calls() []Call {\n\tm.mu.Lock()\n\tdefer m.mu.Unlock()\n\treturn append([]Call(nil), m.calls...)\n}\n\n", name)
+
+	for _, m := range methods {
+		fmt.Fprintf(w, "func (m *%s) %s(%s) %s {\n", name, m.name, strings.Join(m.params, ", "), m.results)
+		fmt.Fprintf(w, "\tm.mu.Lock()\n\tm.calls = append(m.calls, Call{Method: %q, Args: []interface{}{%s}})\n\tm.mu.Unlock()\n", m.name, strings.Join(m.args, ", "))
+		fmt.Fprintf(w, "\tif m.%sFunc == nil {\n\t\tpanic(\"svixmock: %s.%s called but %sFunc is not set\")\n\t}\n", m.name, name, m.name, m.name)
+		ret := "return "
+		if m.results == "" {
+			ret = ""
+		}
+		fmt.Fprintf(w, "\t%sm.%sFunc(%s)\n}\n\n", ret, m.name, strings.Join(m.args, ", "))
+	}
+}
+
+// typeString prints a type expression from the svix package as seen from
+// another package, qualifying the svix identifiers it contains.
+func typeString(expr ast.Expr, imports map[string]bool) string {
+	expr = qualify(expr, imports)
+	var buf bytes.Buffer
+	// A fresh FileSet drops the source positions, which would otherwise
+	// make the printer break lines inside qualified identifiers.
+	if err := printer.Fprint(&buf, token.NewFileSet(), expr); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
+
+func qualify(expr ast.Expr, imports map[string]bool) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(e.Name) {
+			return &ast.SelectorExpr{X: ast.NewIdent("svix"), Sel: e}
+		}
+		return e
+	case *ast.SelectorExpr:
+		imports[e.X.(*ast.Ident).Name] = true
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(e.X, imports)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: qualify(e.Elt, imports)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualify(e.Key, imports), Value: qualify(e.Value, imports)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualify(e.Elt, imports)}
+	}
+	log.Fatalf("unsupported type expression %T", expr)
+	return nil
+}
@@ -222,9 +222,26 @@ func (c *APIClient) callAPI(request *http.Request) (*http.Response, error) {
 		if try >= NumTries - 1 {
 			return resp, err
 		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		// The body was consumed by the previous try.
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
 		retryCount++
 		request.Header.Set("svix-retry-count", strconv.Itoa(retryCount))
-		time.Sleep(sleepTime)
+		timer := time.NewTimer(sleepTime)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
 		sleepTime = sleepTime * 2
 	}
 
@@ -238,6 +255,46 @@ func (c *APIClient) callAPI(request *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
+// CallRaw sends a request whose JSON body is already encoded, such as one
+// with a payload that must be sent unchanged, and returns the body of the
+// response. Like the generated operations, it is built by prepareRequest and
+// sent by callAPI.
+func (c *APIClient) CallRaw(ctx context.Context, operation string, method string, path string, headerParams map[string]string, body []byte) ([]byte, *http.Response, error) {
+	basePath, err := c.cfg.ServerURLWithContext(ctx, operation)
+	if err != nil {
+		return nil, nil, GenericOpenAPIError{error: err.Error()}
+	}
+	params := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+	for k, v := range headerParams {
+		params[k] = v
+	}
+	var postBody interface{}
+	if body != nil {
+		postBody = body
+	}
+	req, err := c.prepareRequest(ctx, basePath+path, method, postBody, params, url.Values{}, url.Values{}, "", "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := c.callAPI(req)
+	if err != nil || res == nil {
+		return nil, res, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewBuffer(resBody))
+	if err != nil {
+		return nil, res, err
+	}
+	if res.StatusCode >= 300 {
+		return nil, res, GenericOpenAPIError{body: resBody, error: res.Status}
+	}
+	return resBody, res, nil
+}
+
 // Allow modification of underlying config for alternate implementations and testing
 // Caution: modifying the configuration while live can cause data races and potentially unwanted behavior
 func (c *APIClient) GetConfig() *Configuration {
@@ -0,0 +1,67 @@
+// Package outboxutil holds the behaviour shared by svix.Outbox and the
+// sqloutbox relay, so that both send and retry messages the same way.
+package outboxutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey returns the idempotency key outboxes send a message with,
+// so that retries of the message are deduplicated by Svix.
+func IdempotencyKey(appId string, eventId string) string {
+	return fmt.Sprintf("outbox:%s:%s", appId, eventId)
+}
+
+// Backoff is the default backoff of outboxes: exponential, starting at 1
+// second and capped at 5 minutes.
+func Backoff(attempt int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempt && delay < 5*time.Minute; i++ {
+		delay *= 2
+	}
+	if delay > 5*time.Minute {
+		delay = 5 * time.Minute
+	}
+	return delay
+}
+
+// statusError is implemented by the errors of the API, *svix.Error.
+type statusError interface {
+	error
+	Status() int
+}
+
+// IsConflict reports whether sending a message failed because a message
+// with the same `eventId` already exists, i.e. a previous attempt went
+// through even though its response was lost.
+func IsConflict(err error) bool {
+	var apiErr statusError
+	return errors.As(err, &apiErr) && apiErr.Status() == http.StatusConflict
+}
+
+// IsRetryable reports whether sending a message may succeed when retried:
+// on network errors, timeouts, rate limiting and server errors. Errors
+// raised before the request, such as invalid payloads, must be checked by
+// the caller.
+func IsRetryable(err error) bool {
+	var apiErr statusError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	status := apiErr.Status()
+	return status == 0 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+}
+
+// NewId returns a random identifier for outbox entries.
+func NewId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
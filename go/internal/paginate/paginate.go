@@ -0,0 +1,38 @@
+// Package paginate walks through the pages of the list operations of the
+// API.
+package paginate
+
+// PageSize is the number of items requested per page, the most the API
+// returns at once.
+const PageSize = 250
+
+// Each calls fetch for every page, starting with a nil iterator, until the
+// last page. fetch returns the iterator of the next page and whether the
+// page was the last one; returning true stops early.
+func Each(fetch func(iterator *string) (next *string, done bool, err error)) error {
+	var iterator *string
+	for {
+		next, done, err := fetch(iterator)
+		if err != nil {
+			return err
+		}
+		if done || next == nil {
+			return nil
+		}
+		iterator = next
+	}
+}
+
+// All returns the items of every page.
+func All[T any](fetch func(iterator *string) (data []T, next *string, done bool, err error)) ([]T, error) {
+	var all []T
+	err := Each(func(iterator *string) (*string, bool, error) {
+		data, next, done, err := fetch(iterator)
+		all = append(all, data...)
+		return next, done, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,50 @@
+package paginate
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// pages fetches three pages of two items.
+func pages(iterator *string) ([]int, *string, bool, error) {
+	page := 0
+	if iterator != nil {
+		page, _ = strconv.Atoi(*iterator)
+	}
+	next := strconv.Itoa(page + 1)
+	return []int{2 * page, 2*page + 1}, &next, page == 2, nil
+}
+
+func TestAll(t *testing.T) {
+	all, err := All(pages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(all, []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("unexpected items %v", all)
+	}
+
+	fail := errors.New("fail")
+	_, err = All(func(iterator *string) ([]int, *string, bool, error) {
+		if iterator != nil {
+			return nil, nil, false, fail
+		}
+		return pages(iterator)
+	})
+	if err != fail {
+		t.Errorf("expected the error of the second page, got %v", err)
+	}
+}
+
+func TestEachStopsWithoutIterator(t *testing.T) {
+	calls := 0
+	err := Each(func(iterator *string) (*string, bool, error) {
+		calls++
+		return nil, false, nil
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("expected a single page, got %d and %v", calls, err)
+	}
+}
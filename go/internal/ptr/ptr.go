@@ -0,0 +1,21 @@
+// Package ptr reads and writes the optional fields of the API.
+package ptr
+
+import "github.com/svix/svix-webhooks/go/internal/openapi"
+
+// Value returns the value p points to, or the zero value if p is nil.
+func Value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+// NullableString returns s as a nullable string, null when it's empty.
+func NullableString(s string) *openapi.NullableString {
+	if s == "" {
+		return openapi.NewNullableString(nil)
+	}
+	return openapi.NewNullableString(&s)
+}
@@ -0,0 +1,28 @@
+// Package signature parses the signature headers of webhooks, so that the
+// svix command diagnoses signatures the way Webhook.Verify checks them.
+package signature
+
+import "strings"
+
+// Signature is an entry of a signature header.
+type Signature struct {
+	Version string
+	Value   string
+}
+
+// Parse returns the space-separated entries of a signature header, each a
+// version and a signature separated by a comma. Entries without a comma
+// are returned in malformed, and empty ones are skipped.
+func Parse(header string) (signatures []Signature, malformed []string) {
+	for _, entry := range strings.Split(header, " ") {
+		parts := strings.Split(entry, ",")
+		switch {
+		case entry == "":
+		case len(parts) < 2:
+			malformed = append(malformed, entry)
+		default:
+			signatures = append(signatures, Signature{Version: parts[0], Value: parts[1]})
+		}
+	}
+	return signatures, malformed
+}
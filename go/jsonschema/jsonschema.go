@@ -0,0 +1,436 @@
+// Package jsonschema validates JSON values against JSON Schema draft 7.
+//
+// Schemas are compiled once and can then validate any number of values:
+//
+//	schema, err := jsonschema.Compile(eventType.Schemas["1"])
+//	if err := schema.Validate(payload); err != nil {
+//		for _, e := range err.(*jsonschema.ValidationError).Errors {
+//			fmt.Println(e.InstancePath, e.Message)
+//		}
+//	}
+//
+// All the keywords of draft 7 are supported. References must point inside
+// the schema (`#/definitions/...`, `#` or an `$id` of the schema): remote
+// references are not resolved. Patterns use the RE2 syntax of the regexp
+// package, which covers the ECMA 262 regular expressions commonly used in
+// schemas. The date-time, date, time, email, hostname, ipv4, ipv6, uri,
+// uri-reference, uuid and regex formats are checked; other formats are
+// ignored.
+package jsonschema
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/svix/svix-webhooks/go/internal/jsonvalue"
+)
+
+// Schema is a compiled schema. It is safe for concurrent use.
+type Schema struct {
+	root *node
+}
+
+type node struct {
+	// The JSON pointer of the schema in the root schema.
+	path string
+	// Set for the true and false schemas.
+	always *bool
+	// Set for a $ref, which replaces all the other keywords in draft 7.
+	ref *node
+
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constVal interface{}
+
+	multipleOf                         *big.Rat
+	minimum, maximum                   *big.Rat
+	exclusiveMinimum, exclusiveMaximum *big.Rat
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	format               string
+
+	items           *node
+	itemsList       []*node
+	additionalItems *node
+	minItems        *int
+	maxItems        *int
+	uniqueItems     bool
+	contains        *node
+
+	required             []string
+	properties           map[string]*node
+	patternProperties    []patternProperty
+	additionalProperties *node
+	dependencies         map[string]dependency
+	propertyNames        *node
+	minProperties        *int
+	maxProperties        *int
+
+	ifSchema, thenSchema, elseSchema *node
+	allOf, anyOf, oneOf              []*node
+	not                              *node
+}
+
+type patternProperty struct {
+	re     *regexp.Regexp
+	schema *node
+}
+
+// dependency is either a list of required properties or a schema.
+type dependency struct {
+	required []string
+	schema   *node
+}
+
+// Compile compiles a schema given as a decoded JSON value, typically a
+// map[string]interface{} or a bool. Other values are converted to JSON
+// first.
+func Compile(schema interface{}) (*Schema, error) {
+	doc, err := jsonvalue.Normalize(schema)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid schema: %w", err)
+	}
+	c := &compiler{doc: doc, nodes: map[string]*node{}, ids: map[string]string{}}
+	if m, ok := doc.(map[string]interface{}); ok {
+		if id, ok := m["$id"].(string); ok {
+			c.base = strings.TrimSuffix(id, "#")
+		}
+	}
+	c.collectIds(doc, "")
+	root, err := c.compile("")
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	if err := c.checkCycles(); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return &Schema{root: root}, nil
+}
+
+// checkCycles refuses references that lead back to a schema applied to the
+// same value, such as {"$ref": "#"}, as validation would never end. Cycles
+// through keywords applied to the children of the value, such as
+// properties, are fine.
+func (c *compiler) checkCycles() error {
+	paths := make([]string, 0, len(c.nodes))
+	for path := range c.nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	// true for the nodes being checked, false for those already checked.
+	visiting := map[*node]bool{}
+	var check func(n *node) error
+	check = func(n *node) error {
+		if v, ok := visiting[n]; ok {
+			if v {
+				return fmt.Errorf("%s: circular reference", displayPath(n.path))
+			}
+			return nil
+		}
+		visiting[n] = true
+		for _, next := range n.inPlace() {
+			if err := check(next); err != nil {
+				return err
+			}
+		}
+		visiting[n] = false
+		return nil
+	}
+	for _, path := range paths {
+		if err := check(c.nodes[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inPlace returns the subschemas applied to the same value as n.
+func (n *node) inPlace() []*node {
+	var nodes []*node
+	add := func(children ...*node) {
+		for _, child := range children {
+			if child != nil {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+	add(n.ref, n.ifSchema, n.thenSchema, n.elseSchema, n.not)
+	add(n.allOf...)
+	add(n.anyOf...)
+	add(n.oneOf...)
+	for _, dep := range n.dependencies {
+		add(dep.schema)
+	}
+	return nodes
+}
+
+type compiler struct {
+	doc   interface{}
+	base  string
+	nodes map[string]*node
+	// The JSON pointers of the subschemas with a plain-name $id, such as
+	// "#address".
+	ids map[string]string
+}
+
+func (c *compiler) collectIds(v interface{}, path string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if id, ok := v["$id"].(string); ok && strings.HasPrefix(id, "#") && path != "" {
+			c.ids[id] = path
+		}
+		for k, sub := range v {
+			if k == "enum" || k == "const" {
+				continue
+			}
+			c.collectIds(sub, path+"/"+jsonvalue.EscapePointer(k))
+		}
+	case []interface{}:
+		for i, sub := range v {
+			c.collectIds(sub, path+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// compile compiles the subschema at the JSON pointer path. Nodes are
+// registered before their keywords are compiled, so that recursive
+// references work.
+func (c *compiler) compile(path string) (*node, error) {
+	if n, ok := c.nodes[path]; ok {
+		return n, nil
+	}
+	v, err := jsonvalue.Lookup(c.doc, path)
+	if err != nil {
+		return nil, err
+	}
+	n := &node{path: path}
+	c.nodes[path] = n
+	switch s := v.(type) {
+	case bool:
+		n.always = &s
+		return n, nil
+	case map[string]interface{}:
+		if err := c.compileKeywords(n, s); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("schema at %q must be an object or a boolean", displayPath(path))
+}
+
+func (c *compiler) compileKeywords(n *node, s map[string]interface{}) error {
+	p := n.path
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := c.resolveRef(ref)
+		if err != nil {
+			return fmt.Errorf("%s/$ref: %w", displayPath(p), err)
+		}
+		n.ref, err = c.compile(target)
+		return err
+	}
+
+	var err error
+	sub := func(keyword string) *node {
+		if err != nil {
+			return nil
+		}
+		if _, ok := s[keyword]; !ok {
+			return nil
+		}
+		var child *node
+		child, err = c.compile(p + "/" + keyword)
+		return child
+	}
+	list := func(keyword string) []*node {
+		items, ok := s[keyword].([]interface{})
+		if !ok || err != nil {
+			return nil
+		}
+		nodes := make([]*node, len(items))
+		for i := range items {
+			if nodes[i], err = c.compile(p + "/" + keyword + "/" + strconv.Itoa(i)); err != nil {
+				return nil
+			}
+		}
+		return nodes
+	}
+	number := func(keyword string) *big.Rat {
+		v, ok := s[keyword]
+		if !ok || err != nil {
+			return nil
+		}
+		r, ok := jsonvalue.Rat(v)
+		if !ok {
+			err = fmt.Errorf("%s/%s must be a number", displayPath(p), keyword)
+		}
+		return r
+	}
+	count := func(keyword string) *int {
+		r := number(keyword)
+		if r == nil {
+			return nil
+		}
+		if !r.IsInt() || r.Sign() < 0 || !r.Num().IsInt64() {
+			err = fmt.Errorf("%s/%s must be a non-negative integer", displayPath(p), keyword)
+			return nil
+		}
+		i := int(r.Num().Int64())
+		return &i
+	}
+	strs := func(keyword string, v interface{}) []string {
+		items, ok := v.([]interface{})
+		if !ok {
+			err = fmt.Errorf("%s/%s must be an array of strings", displayPath(p), keyword)
+			return nil
+		}
+		out := make([]string, len(items))
+		for i, item := range items {
+			if out[i], ok = item.(string); !ok {
+				err = fmt.Errorf("%s/%s must be an array of strings", displayPath(p), keyword)
+				return nil
+			}
+		}
+		return out
+	}
+
+	switch t := s["type"].(type) {
+	case nil:
+	case string:
+		n.types = []string{t}
+	default:
+		n.types = strs("type", t)
+	}
+	for _, t := range n.types {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return fmt.Errorf("%s/type: unknown type %q", displayPath(p), t)
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		n.enum = enum
+	}
+	n.constVal, n.hasConst = s["const"]
+
+	n.multipleOf = number("multipleOf")
+	if n.multipleOf != nil && n.multipleOf.Sign() <= 0 {
+		return fmt.Errorf("%s/multipleOf must be positive", displayPath(p))
+	}
+	n.minimum = number("minimum")
+	n.maximum = number("maximum")
+	n.exclusiveMinimum = number("exclusiveMinimum")
+	n.exclusiveMaximum = number("exclusiveMaximum")
+
+	n.minLength = count("minLength")
+	n.maxLength = count("maxLength")
+	if pattern, ok := s["pattern"].(string); ok {
+		if n.pattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s/pattern: %w", displayPath(p), err)
+		}
+	}
+	n.format, _ = s["format"].(string)
+
+	if _, ok := s["items"].([]interface{}); ok {
+		n.itemsList = list("items")
+		n.additionalItems = sub("additionalItems")
+	} else {
+		n.items = sub("items")
+	}
+	n.minItems = count("minItems")
+	n.maxItems = count("maxItems")
+	n.uniqueItems, _ = s["uniqueItems"].(bool)
+	n.contains = sub("contains")
+
+	if required, ok := s["required"]; ok {
+		n.required = strs("required", required)
+	}
+	if props, ok := s["properties"].(map[string]interface{}); ok && err == nil {
+		n.properties = map[string]*node{}
+		for name := range props {
+			if n.properties[name], err = c.compile(p + "/properties/" + jsonvalue.EscapePointer(name)); err != nil {
+				return err
+			}
+		}
+	}
+	if props, ok := s["patternProperties"].(map[string]interface{}); ok && err == nil {
+		patterns := make([]string, 0, len(props))
+		for pattern := range props {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s/patternProperties: %w", displayPath(p), err)
+			}
+			child, err := c.compile(p + "/patternProperties/" + jsonvalue.EscapePointer(pattern))
+			if err != nil {
+				return err
+			}
+			n.patternProperties = append(n.patternProperties, patternProperty{re, child})
+		}
+	}
+	n.additionalProperties = sub("additionalProperties")
+	if deps, ok := s["dependencies"].(map[string]interface{}); ok && err == nil {
+		n.dependencies = map[string]dependency{}
+		for name, dep := range deps {
+			if _, ok := dep.([]interface{}); ok {
+				n.dependencies[name] = dependency{required: strs("dependencies", dep)}
+				continue
+			}
+			child, err := c.compile(p + "/dependencies/" + jsonvalue.EscapePointer(name))
+			if err != nil {
+				return err
+			}
+			n.dependencies[name] = dependency{schema: child}
+		}
+	}
+	n.propertyNames = sub("propertyNames")
+	n.minProperties = count("minProperties")
+	n.maxProperties = count("maxProperties")
+
+	n.ifSchema = sub("if")
+	n.thenSchema = sub("then")
+	n.elseSchema = sub("else")
+	n.allOf = list("allOf")
+	n.anyOf = list("anyOf")
+	n.oneOf = list("oneOf")
+	n.not = sub("not")
+	return err
+}
+
+// resolveRef returns the JSON pointer of the subschema a reference points
+// to.
+func (c *compiler) resolveRef(ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	fragment := u.Fragment
+	u.Fragment = ""
+	if base := u.String(); base != "" && base != c.base {
+		return "", fmt.Errorf("remote reference %q is not supported", ref)
+	}
+	if fragment == "" || strings.HasPrefix(fragment, "/") {
+		if _, err := jsonvalue.Lookup(c.doc, fragment); err != nil {
+			return "", err
+		}
+		return fragment, nil
+	}
+	if path, ok := c.ids["#"+fragment]; ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("unknown reference %q", ref)
+}
+
+// displayPath shows the empty pointer of the root as "#".
+func displayPath(path string) string {
+	return "#" + path
+}
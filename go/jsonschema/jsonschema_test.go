@@ -0,0 +1,134 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/svix/svix-webhooks/go/jsonschema"
+)
+
+func compile(t *testing.T, schema string) *jsonschema.Schema {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal([]byte(schema), &doc); err != nil {
+		t.Fatal(err)
+	}
+	s, err := jsonschema.Compile(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema string
+		valid  []string
+		errors []string
+	}{
+		{"type", `{"type": ["integer", "null"]}`, []string{`1`, `1.0`, `null`}, []string{`1.5`, `"1"`}},
+		{"number", `{"type": "number", "minimum": 1, "exclusiveMaximum": 3, "multipleOf": 0.1}`, []string{`1`, `2.9`}, []string{`0.9`, `3`, `1.05`}},
+		{"enum", `{"enum": ["a", 1, {"b": [2]}]}`, []string{`"a"`, `1.0`, `{"b": [2]}`}, []string{`"b"`, `{"b": [3]}`}},
+		{"const", `{"const": null}`, []string{`null`}, []string{`false`}},
+		{"string", `{"type": "string", "minLength": 2, "maxLength": 3, "pattern": "^[a-zé]+$"}`, []string{`"éé"`, `"abc"`}, []string{`"a"`, `"abcd"`, `"AB"`}},
+		{"format", `{"format": "date-time"}`, []string{`"2024-01-02T03:04:05Z"`, `"2024-01-02t03:04:05.123+01:00"`, `12`}, []string{`"2024-01-02"`}},
+		{"unknown format", `{"format": "phone"}`, []string{`"anything"`}, nil},
+		{"email", `{"format": "email"}`, []string{`"a@example.com"`}, []string{`"a"`, `"A <a@example.com>"`}},
+		{"array", `{"items": {"type": "integer"}, "minItems": 1, "uniqueItems": true, "contains": {"minimum": 5}}`, []string{`[1, 5]`}, []string{`[]`, `[5, 5]`, `[1, "5"]`, `[1, 2]`}},
+		{"tuple", `{"items": [{"type": "string"}], "additionalItems": false}`, []string{`["a"]`, `[]`}, []string{`["a", 1]`, `[1]`}},
+		{"object", `{"required": ["a"], "properties": {"a": {"type": "string"}}, "patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": false}`, []string{`{"a": "", "x-b": 1}`}, []string{`{}`, `{"a": "", "b": 1}`, `{"a": "", "x-b": "1"}`}},
+		{"dependencies", `{"dependencies": {"card": ["address"], "bank": {"required": ["iban"]}}}`, []string{`{"card": 1, "address": 1}`, `{"bank": 1, "iban": 1}`}, []string{`{"card": 1}`, `{"bank": 1}`}},
+		{"property names", `{"propertyNames": {"maxLength": 2}, "maxProperties": 2}`, []string{`{"ab": 1}`}, []string{`{"abc": 1}`, `{"a": 1, "b": 1, "c": 1}`}},
+		{"combinators", `{"anyOf": [{"type": "string"}, {"type": "integer"}], "oneOf": [{"minimum": 0}, {"maximum": 10}], "not": {"const": "x"}}`, []string{`-1`, `11`}, []string{`"x"`, `5`, `true`}},
+		{"if", `{"if": {"required": ["kind"], "properties": {"kind": {"const": "card"}}}, "then": {"required": ["last4"]}, "else": {"required": ["iban"]}}`, []string{`{"kind": "card", "last4": "1234"}`, `{"iban": "x"}`}, []string{`{"kind": "card"}`, `{}`}},
+		{"boolean", `{"properties": {"a": true, "b": false}}`, []string{`{"a": 1}`}, []string{`{"b": 1}`}},
+		{"ref", `{"$id": "https://example.com/s.json", "definitions": {"node": {"$id": "#node", "properties": {"children": {"items": {"$ref": "#node"}}, "value": {"$ref": "https://example.com/s.json#/definitions/value"}}}, "value": {"type": "integer"}}, "$ref": "#/definitions/node"}`, []string{`{"value": 1, "children": [{"value": 2, "children": []}]}`}, []string{`{"children": [{"value": "2"}]}`}},
+		{"ref ignores siblings", `{"definitions": {"a": {}}, "$ref": "#/definitions/a", "type": "string"}`, []string{`1`}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := compile(t, tc.schema)
+			for _, v := range tc.valid {
+				var instance interface{}
+				if err := json.Unmarshal([]byte(v), &instance); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.Validate(instance); err != nil {
+					t.Errorf("expected %s to be valid, got %v", v, err)
+				}
+			}
+			for _, v := range tc.errors {
+				var instance interface{}
+				if err := json.Unmarshal([]byte(v), &instance); err != nil {
+					t.Fatal(err)
+				}
+				var validationErr *jsonschema.ValidationError
+				if err := s.Validate(instance); !errors.As(err, &validationErr) {
+					t.Errorf("expected %s to be invalid, got %v", v, err)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorPaths(t *testing.T) {
+	s := compile(t, `{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}},
+			"a/b": {"type": "string"}
+		},
+		"definitions": {"line": {"properties": {"quantity": {"type": "integer", "minimum": 1}}}}
+	}`)
+	// Go values are converted to JSON first.
+	err := s.Validate(map[string]interface{}{
+		"lines": []map[string]int{{"quantity": 1}, {"quantity": 0}},
+		"a/b":   3,
+	})
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	expected := []jsonschema.Error{
+		{InstancePath: "", KeywordPath: "/required", Message: `missing required property "id"`},
+		{InstancePath: "/a~1b", KeywordPath: "/properties/a~1b/type", Message: "expected string, got integer"},
+		{InstancePath: "/lines/1/quantity", KeywordPath: "/definitions/line/properties/quantity/minimum", Message: "must be at least 1"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+	for i, e := range validationErr.Errors {
+		if *e != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], *e)
+		}
+	}
+	if err.Error() != `jsonschema: (root): missing required property "id" (and 2 more errors)` {
+		t.Errorf("unexpected message: %s", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, schema := range []string{
+		`{"type": "float"}`,
+		`{"pattern": "("}`,
+		`{"$ref": "https://example.com/other.json"}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"minLength": -1}`,
+		`{"properties": {"a": 1}}`,
+		`{"multipleOf": 0}`,
+		`{"$ref": "#"}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"$ref": "#/definitions/a"}}, "properties": {"x": {"$ref": "#/definitions/a"}}}`,
+		`{"anyOf": [{"type": "string"}, {"$ref": "#"}]}`,
+	} {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(schema), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := jsonschema.Compile(doc); err == nil {
+			t.Errorf("expected %s to be refused", schema)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/svix/svix-webhooks/go/internal/jsonvalue"
+)
+
+// ValidationError lists why a value doesn't match a schema.
+type ValidationError struct {
+	Errors []*Error
+}
+
+func (e *ValidationError) Error() string {
+	msg := "jsonschema: " + e.Errors[0].Error()
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more errors)", len(e.Errors)-1)
+	}
+	return msg
+}
+
+// Error is a single reason why a value doesn't match a schema.
+type Error struct {
+	// The JSON pointer of the invalid value, such as /lines/0/quantity, or
+	// the empty string for the whole value.
+	InstancePath string
+	// The JSON pointer of the keyword that failed in the schema, such as
+	// /properties/lines/items/properties/quantity/minimum.
+	KeywordPath string
+	Message     string
+}
+
+func (e *Error) Error() string {
+	path := e.InstancePath
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + e.Message
+}
+
+// Validate checks v, a decoded JSON value or any value that can be
+// converted to JSON, against the schema. It returns a *ValidationError
+// listing all the errors found, or an error if v can't be converted to
+// JSON.
+func (s *Schema) Validate(v interface{}) error {
+	instance, err := jsonvalue.Normalize(v)
+	if err != nil {
+		return fmt.Errorf("jsonschema: invalid value: %w", err)
+	}
+	var errs []*Error
+	validate(s.root, instance, "", &errs)
+	if len(errs) != 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// matches reports whether v is valid against n, without recording errors.
+func matches(n *node, v interface{}, path string) bool {
+	var errs []*Error
+	validate(n, v, path, &errs)
+	return len(errs) == 0
+}
+
+func validate(n *node, v interface{}, path string, errs *[]*Error) {
+	fail := func(keyword string, format string, args ...interface{}) {
+		*errs = append(*errs, &Error{
+			InstancePath: path,
+			KeywordPath:  n.path + "/" + keyword,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+	if n.always != nil {
+		if !*n.always {
+			*errs = append(*errs, &Error{InstancePath: path, KeywordPath: n.path, Message: "no value is allowed"})
+		}
+		return
+	}
+	if n.ref != nil {
+		validate(n.ref, v, path, errs)
+		return
+	}
+
+	if len(n.types) != 0 {
+		actual := typeOf(v)
+		ok := false
+		for _, t := range n.types {
+			if t == actual || t == "number" && actual == "integer" {
+				ok = true
+			}
+		}
+		if !ok {
+			fail("type", "expected %s, got %s", strings.Join(n.types, " or "), actual)
+			// The other keywords would only report the same problem.
+			return
+		}
+	}
+	if n.enum != nil {
+		ok := false
+		for _, e := range n.enum {
+			if jsonvalue.Equal(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("enum", "value must be one of %s", jsonvalue.DescribeList(n.enum))
+		}
+	}
+	if n.hasConst && !jsonvalue.Equal(v, n.constVal) {
+		fail("const", "value must be %s", jsonvalue.Describe(n.constVal))
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		validateNumber(n, v, fail)
+	case string:
+		validateString(n, v, fail)
+	case []interface{}:
+		validateArray(n, v, path, errs, fail)
+	case map[string]interface{}:
+		validateObject(n, v, path, errs, fail)
+	}
+
+	if n.ifSchema != nil {
+		if matches(n.ifSchema, v, path) {
+			if n.thenSchema != nil {
+				validate(n.thenSchema, v, path, errs)
+			}
+		} else if n.elseSchema != nil {
+			validate(n.elseSchema, v, path, errs)
+		}
+	}
+	for _, sub := range n.allOf {
+		validate(sub, v, path, errs)
+	}
+	if n.anyOf != nil {
+		ok := false
+		for _, sub := range n.anyOf {
+			if matches(sub, v, path) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("anyOf", "value doesn't match any of the allowed schemas")
+		}
+	}
+	if n.oneOf != nil {
+		matched := 0
+		for _, sub := range n.oneOf {
+			if matches(sub, v, path) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("oneOf", "value must match exactly one of the allowed schemas, but matches %d", matched)
+		}
+	}
+	if n.not != nil && matches(n.not, v, path) {
+		fail("not", "value matches a disallowed schema")
+	}
+}
+
+type failFunc func(keyword string, format string, args ...interface{})
+
+func validateNumber(n *node, v json.Number, fail failFunc) {
+	r, ok := jsonvalue.Rat(v)
+	if !ok {
+		fail("type", "invalid number %s", v)
+		return
+	}
+	if n.multipleOf != nil && !new(big.Rat).Quo(r, n.multipleOf).IsInt() {
+		fail("multipleOf", "must be a multiple of %s", jsonvalue.RatString(n.multipleOf))
+	}
+	if n.minimum != nil && r.Cmp(n.minimum) < 0 {
+		fail("minimum", "must be at least %s", jsonvalue.RatString(n.minimum))
+	}
+	if n.maximum != nil && r.Cmp(n.maximum) > 0 {
+		fail("maximum", "must be at most %s", jsonvalue.RatString(n.maximum))
+	}
+	if n.exclusiveMinimum != nil && r.Cmp(n.exclusiveMinimum) <= 0 {
+		fail("exclusiveMinimum", "must be greater than %s", jsonvalue.RatString(n.exclusiveMinimum))
+	}
+	if n.exclusiveMaximum != nil && r.Cmp(n.exclusiveMaximum) >= 0 {
+		fail("exclusiveMaximum", "must be less than %s", jsonvalue.RatString(n.exclusiveMaximum))
+	}
+}
+
+func validateString(n *node, v string, fail failFunc) {
+	length := utf8.RuneCountInString(v)
+	if n.minLength != nil && length < *n.minLength {
+		fail("minLength", "must be at least %d characters long", *n.minLength)
+	}
+	if n.maxLength != nil && length > *n.maxLength {
+		fail("maxLength", "must be at most %d characters long", *n.maxLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(v) {
+		fail("pattern", "must match the pattern %q", n.pattern.String())
+	}
+	if check, ok := formats[n.format]; ok && !check(v) {
+		fail("format", "must be a valid %s", n.format)
+	}
+}
+
+func validateArray(n *node, v []interface{}, path string, errs *[]*Error, fail failFunc) {
+	if n.minItems != nil && len(v) < *n.minItems {
+		fail("minItems", "must have at least %d items", *n.minItems)
+	}
+	if n.maxItems != nil && len(v) > *n.maxItems {
+		fail("maxItems", "must have at most %d items", *n.maxItems)
+	}
+	if n.uniqueItems {
+	unique:
+		for i := range v {
+			for j := 0; j < i; j++ {
+				if jsonvalue.Equal(v[i], v[j]) {
+					fail("uniqueItems", "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+	for i, item := range v {
+		itemPath := path + "/" + strconv.Itoa(i)
+		switch {
+		case n.items != nil:
+			validate(n.items, item, itemPath, errs)
+		case i < len(n.itemsList):
+			validate(n.itemsList[i], item, itemPath, errs)
+		case n.additionalItems != nil:
+			validate(n.additionalItems, item, itemPath, errs)
+		}
+	}
+	if n.contains != nil {
+		ok := false
+		for i, item := range v {
+			if matches(n.contains, item, path+"/"+strconv.Itoa(i)) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("contains", "must contain an item matching the schema")
+		}
+	}
+}
+
+func validateObject(n *node, v map[string]interface{}, path string, errs *[]*Error, fail failFunc) {
+	if n.minProperties != nil && len(v) < *n.minProperties {
+		fail("minProperties", "must have at least %d properties", *n.minProperties)
+	}
+	if n.maxProperties != nil && len(v) > *n.maxProperties {
+		fail("maxProperties", "must have at most %d properties", *n.maxProperties)
+	}
+	for _, name := range n.required {
+		if _, ok := v[name]; !ok {
+			fail("required", "missing required property %q", name)
+		}
+	}
+
+	// Properties are checked in order, for stable errors.
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := v[name]
+		propPath := path + "/" + jsonvalue.EscapePointer(name)
+		if n.propertyNames != nil && !matches(n.propertyNames, name, propPath) {
+			fail("propertyNames", "invalid property name %q", name)
+		}
+		matched := false
+		if sub, ok := n.properties[name]; ok {
+			matched = true
+			validate(sub, value, propPath, errs)
+		}
+		for _, pp := range n.patternProperties {
+			if pp.re.MatchString(name) {
+				matched = true
+				validate(pp.schema, value, propPath, errs)
+			}
+		}
+		if !matched && n.additionalProperties != nil {
+			if a := n.additionalProperties; a.always != nil && !*a.always {
+				*errs = append(*errs, &Error{InstancePath: propPath, KeywordPath: n.path + "/additionalProperties", Message: "property is not allowed"})
+			} else {
+				validate(a, value, propPath, errs)
+			}
+		}
+		if dep, ok := n.dependencies[name]; ok {
+			for _, required := range dep.required {
+				if _, ok := v[required]; !ok {
+					fail("dependencies", "property %q requires property %q", name, required)
+				}
+			}
+			if dep.schema != nil {
+				validate(dep.schema, v, path, errs)
+			}
+		}
+	}
+}
+
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if r, ok := jsonvalue.Rat(v); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
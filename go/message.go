@@ -2,13 +2,15 @@ package svix
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/svix/svix-webhooks/go/internal/openapi"
 )
 
 type Message struct {
-	api *openapi.APIClient
+	api       *openapi.APIClient
+	validator *schemaValidator
 }
 
 type (
@@ -61,6 +63,22 @@ func (m *Message) Create(ctx context.Context, appId string, messageIn *MessageIn
 }
 
 func (m *Message) CreateWithOptions(ctx context.Context, appId string, messageIn *MessageIn, options *PostOptions) (*MessageOut, error) {
+	out, res, err := m.create(ctx, appId, messageIn, options)
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	ret := MessageOut(out)
+	return &ret, nil
+}
+
+// create validates and creates a message, returning the response for the
+// typed functions to decode it again.
+func (m *Message) create(ctx context.Context, appId string, messageIn *MessageIn, options *PostOptions) (openapi.MessageOut, *http.Response, error) {
+	if m.validator != nil {
+		if err := m.validator.validate(ctx, appId, messageIn); err != nil {
+			return openapi.MessageOut{}, nil, err
+		}
+	}
 	req := m.api.MessageApi.V1MessageCreate(ctx, appId)
 	req = req.MessageIn(openapi.MessageIn(*messageIn))
 	if options != nil {
@@ -68,12 +86,7 @@ func (m *Message) CreateWithOptions(ctx context.Context, appId string, messageIn
 			req = req.IdempotencyKey(*options.IdempotencyKey)
 		}
 	}
-	out, res, err := req.Execute()
-	if err != nil {
-		return nil, wrapError(err, res)
-	}
-	ret := MessageOut(out)
-	return &ret, nil
+	return req.Execute()
 }
 
 func (m *Message) Get(ctx context.Context, appId string, msgId string) (*MessageOut, error) {
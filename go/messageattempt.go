@@ -2,6 +2,7 @@ package svix
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/svix/svix-webhooks/go/internal/openapi"
@@ -23,6 +24,7 @@ type (
 	MessageEndpointOut                    openapi.MessageEndpointOut
 	ListResponseMessageAttemptEndpointOut openapi.ListResponseMessageAttemptEndpointOut
 	MessageAttemptEndpointOut             openapi.MessageAttemptEndpointOut
+	MessageAttemptHeadersOut              openapi.MessageAttemptHeadersOut
 )
 
 type MessageAttemptListOptions struct {
@@ -65,7 +67,7 @@ func (m *MessageAttempt) ListByMsg(ctx context.Context, appId string, msgId stri
 			req = req.After(*options.After)
 		}
 		if options.StatusCodeClass != nil {
-			req.StatusCodeClass(openapi.StatusCodeClass(*options.StatusCodeClass))
+			req = req.StatusCodeClass(openapi.StatusCodeClass(*options.StatusCodeClass))
 		}
 		if options.Channel != nil {
 			req = req.Channel(*options.Channel)
@@ -107,7 +109,7 @@ func (m *MessageAttempt) ListByEndpoint(ctx context.Context, appId string, endpo
 			req = req.After(*options.After)
 		}
 		if options.StatusCodeClass != nil {
-			req.StatusCodeClass(openapi.StatusCodeClass(*options.StatusCodeClass))
+			req = req.StatusCodeClass(openapi.StatusCodeClass(*options.StatusCodeClass))
 		}
 		if options.Channel != nil {
 			req = req.Channel(*options.Channel)
@@ -134,6 +136,18 @@ func (m *MessageAttempt) Get(ctx context.Context, appId string, msgId string, at
 	return &ret, nil
 }
 
+// GetHeaders gets the headers sent with an attempt. The values of sensitive
+// headers aren't returned, only their names.
+func (m *MessageAttempt) GetHeaders(ctx context.Context, appId string, msgId string, attemptId string) (*MessageAttemptHeadersOut, error) {
+	req := m.api.MessageAttemptApi.V1MessageAttemptGetHeaders(ctx, appId, msgId, attemptId)
+	out, res, err := req.Execute()
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	ret := MessageAttemptHeadersOut(out)
+	return &ret, nil
+}
+
 func (m *MessageAttempt) Resend(ctx context.Context, appId string, msgId string, endpointId string) error {
 	return m.ResendWithOptions(ctx, appId, msgId, endpointId, nil)
 }
@@ -150,6 +164,15 @@ func (m *MessageAttempt) ResendWithOptions(ctx context.Context, appId string, ms
 }
 
 func (m *MessageAttempt) ListAttemptedMessages(ctx context.Context, appId string, endpointId string, options *MessageAttemptListOptions) (*ListResponseEndpointMessageOut, error) {
+	out, res, err := m.listAttemptedMessages(ctx, appId, endpointId, options)
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	ret := ListResponseEndpointMessageOut(out)
+	return &ret, nil
+}
+
+func (m *MessageAttempt) listAttemptedMessages(ctx context.Context, appId string, endpointId string, options *MessageAttemptListOptions) (openapi.ListResponseEndpointMessageOut, *http.Response, error) {
 	req := m.api.MessageAttemptApi.V1MessageAttemptListAttemptedMessages(ctx, appId, endpointId)
 	if options != nil {
 		if options.Iterator != nil {
@@ -177,12 +200,7 @@ func (m *MessageAttempt) ListAttemptedMessages(ctx context.Context, appId string
 			req = req.EventTypes(*options.EventTypes)
 		}
 	}
-	out, res, err := req.Execute()
-	if err != nil {
-		return nil, wrapError(err, res)
-	}
-	ret := ListResponseEndpointMessageOut(out)
-	return &ret, nil
+	return req.Execute()
 }
 
 func (m *MessageAttempt) ListAttemptedDestinations(ctx context.Context, appId string, msgId string, options *MessageAttemptListOptions) (*ListResponseMessageEndpointOut, error) {
@@ -0,0 +1,419 @@
+// Package monitor watches the health of endpoints and raises alerts.
+//
+// A Monitor periodically polls the delivery statistics of the endpoints of
+// the selected applications, and computes their failure rates over sliding
+// windows. An endpoint becomes unhealthy when its failure rate exceeds
+// FailureThreshold in any window, and healthy again once it is below
+// RecoveryThreshold in all of them; a change is only made after it has been
+// observed on ConsecutivePolls polls in a row, so that alerts don't flap.
+//
+// Svix disables endpoints that have been failing for several days. The
+// monitor also warns about endpoints whose deliveries have been failing for
+// longer than DisableWarningAfter, counted from their first failed attempt
+// after the last successful one.
+//
+//	m := monitor.New(client, &monitor.Options{
+//		Notifiers: []monitor.Notifier{monitor.LogNotifier(nil)},
+//	})
+//	err := m.Run(ctx)
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+)
+
+type Options struct {
+	// The applications to watch, by id or uid. All of them when empty.
+	Apps []string
+	// How often endpoints are polled. Defaults to 1 minute.
+	Interval time.Duration
+	// The windows over which failure rates are computed. Defaults to 15
+	// minutes and 1 hour.
+	Windows []time.Duration
+	// Failure rate, between 0 and 1, at or above which an endpoint is
+	// unhealthy. Defaults to 0.5.
+	FailureThreshold float64
+	// Failure rate below which an unhealthy endpoint recovers. Defaults to
+	// 0.2.
+	RecoveryThreshold float64
+	// Minimum number of completed deliveries in a window for its failure
+	// rate to be considered. Defaults to 10.
+	MinDeliveries int64
+	// Number of consecutive polls a change must be observed on before the
+	// status of an endpoint changes. Defaults to 2.
+	ConsecutivePolls int
+	// How long an endpoint must have been failing before a
+	// AlertDisableRisk is raised. Defaults to 3 days, Svix disabling
+	// endpoints after 5.
+	DisableWarningAfter time.Duration
+	Notifiers           []Notifier
+	// Called with the errors of polls and notifiers. The monitor keeps
+	// running after errors.
+	OnError func(error)
+}
+
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// WindowStats are the delivery statistics of an endpoint over a window.
+type WindowStats struct {
+	Window  time.Duration `json:"window"`
+	Success int64         `json:"success"`
+	Fail    int64         `json:"fail"`
+	Pending int64         `json:"pending"`
+	Sending int64         `json:"sending"`
+}
+
+// FailureRate is the share of the completed deliveries that failed, or 0
+// when there were none.
+func (w WindowStats) FailureRate() float64 {
+	if w.Success+w.Fail == 0 {
+		return 0
+	}
+	return float64(w.Fail) / float64(w.Success+w.Fail)
+}
+
+func (w WindowStats) MarshalJSON() ([]byte, error) {
+	type stats WindowStats
+	return json.Marshal(struct {
+		stats
+		Window      string  `json:"window"`
+		FailureRate float64 `json:"failureRate"`
+	}{stats(w), w.Window.String(), w.FailureRate()})
+}
+
+// EndpointHealth is the last known health of an endpoint.
+type EndpointHealth struct {
+	AppId      string        `json:"appId"`
+	EndpointId string        `json:"endpointId"`
+	Url        string        `json:"url"`
+	Status     Status        `json:"status"`
+	Windows    []WindowStats `json:"windows"`
+	// When the deliveries started failing without a success since, or
+	// zero if the last delivery succeeded.
+	FailingSince time.Time `json:"failingSince"`
+	CheckedAt    time.Time `json:"checkedAt"`
+}
+
+type endpointState struct {
+	health EndpointHealth
+	// Number of consecutive polls on which the opposite status was
+	// observed.
+	streak int
+	// Whether the current failing streak was reported as a disable risk.
+	disableRiskAlerted bool
+	failures           failureHistory
+}
+
+// failureHistory caches the start of the failing streak of an endpoint, so
+// that its attempts are only paged through again once it succeeded since.
+type failureHistory struct {
+	since         time.Time
+	lastSuccessId string
+	lastFailureId string
+}
+
+// Monitor watches endpoints. It must be created with New.
+type Monitor struct {
+	client *svix.Svix
+	opts   Options
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointState
+}
+
+func New(client *svix.Svix, options *Options) *Monitor {
+	m := &Monitor{client: client, endpoints: map[string]*endpointState{}}
+	if options != nil {
+		m.opts = *options
+	}
+	if m.opts.Interval <= 0 {
+		m.opts.Interval = time.Minute
+	}
+	if len(m.opts.Windows) == 0 {
+		m.opts.Windows = []time.Duration{15 * time.Minute, time.Hour}
+	}
+	if m.opts.FailureThreshold <= 0 {
+		m.opts.FailureThreshold = 0.5
+	}
+	if m.opts.RecoveryThreshold <= 0 {
+		m.opts.RecoveryThreshold = 0.2
+	}
+	if m.opts.MinDeliveries <= 0 {
+		m.opts.MinDeliveries = 10
+	}
+	if m.opts.ConsecutivePolls <= 0 {
+		m.opts.ConsecutivePolls = 2
+	}
+	if m.opts.DisableWarningAfter <= 0 {
+		m.opts.DisableWarningAfter = 3 * 24 * time.Hour
+	}
+	return m
+}
+
+// Run polls the endpoints every Interval until ctx is done, and returns
+// ctx.Err().
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Poll(ctx); err != nil && ctx.Err() == nil {
+			m.reportError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health returns the last known health of the endpoints, sorted by
+// application and endpoint.
+func (m *Monitor) Health() []EndpointHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]EndpointHealth, 0, len(m.endpoints))
+	for _, s := range m.endpoints {
+		out = append(out, s.health)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].AppId != out[j].AppId {
+			return out[i].AppId < out[j].AppId
+		}
+		return out[i].EndpointId < out[j].EndpointId
+	})
+	return out
+}
+
+func (m *Monitor) reportError(err error) {
+	if m.opts.OnError != nil {
+		m.opts.OnError(err)
+	}
+}
+
+// Poll checks every endpoint once, notifying of the changes. Errors for
+// single endpoints are reported to OnError; the returned error is about
+// listing the endpoints.
+func (m *Monitor) Poll(ctx context.Context) error {
+	apps := m.opts.Apps
+	if len(apps) == 0 {
+		err := paginate.Each(func(iterator *string) (*string, bool, error) {
+			out, err := m.client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+			if err != nil {
+				return nil, false, err
+			}
+			for _, app := range out.Data {
+				apps = append(apps, app.Id)
+			}
+			return out.Iterator.Get(), out.Done, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, appId := range apps {
+		err := paginate.Each(func(iterator *string) (*string, bool, error) {
+			out, err := m.client.Endpoint.List(ctx, appId, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+			if err != nil {
+				return nil, false, err
+			}
+			for _, ep := range out.Data {
+				if ep.Disabled != nil && *ep.Disabled {
+					continue
+				}
+				seen[appId+"/"+ep.Id] = true
+				if err := m.check(ctx, appId, ep); err != nil {
+					m.reportError(fmt.Errorf("endpoint %s of %s: %w", ep.Id, appId, err))
+				}
+			}
+			return out.Iterator.Get(), out.Done, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Forget the endpoints that were deleted or disabled.
+	m.mu.Lock()
+	for key := range m.endpoints {
+		if !seen[key] {
+			delete(m.endpoints, key)
+		}
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Monitor) check(ctx context.Context, appId string, ep openapi.EndpointOut) error {
+	now := time.Now()
+	health := EndpointHealth{AppId: appId, EndpointId: ep.Id, Url: ep.Url, CheckedAt: now}
+	for _, window := range m.opts.Windows {
+		since := now.Add(-window)
+		stats, err := m.client.Endpoint.GetStatsWithOptions(ctx, appId, ep.Id, svix.EndpointStatsOptions{Since: &since})
+		if err != nil {
+			return err
+		}
+		health.Windows = append(health.Windows, WindowStats{
+			Window:  window,
+			Success: stats.Success,
+			Fail:    stats.Fail,
+			Pending: stats.Pending,
+			Sending: stats.Sending,
+		})
+	}
+	key := appId + "/" + ep.Id
+	var cached failureHistory
+	m.mu.Lock()
+	if state, ok := m.endpoints[key]; ok {
+		cached = state.failures
+	}
+	m.mu.Unlock()
+	failures, err := m.failingSince(ctx, appId, ep, cached)
+	if err != nil {
+		return err
+	}
+	failingSince := failures.since
+	health.FailingSince = failingSince
+
+	failing, recovered := false, true
+	for _, w := range health.Windows {
+		if w.Success+w.Fail < m.opts.MinDeliveries {
+			continue
+		}
+		rate := w.FailureRate()
+		failing = failing || rate >= m.opts.FailureThreshold
+		recovered = recovered && rate < m.opts.RecoveryThreshold
+	}
+
+	m.mu.Lock()
+	state, ok := m.endpoints[key]
+	if !ok {
+		state = &endpointState{health: EndpointHealth{Status: StatusHealthy}}
+		m.endpoints[key] = state
+	}
+	health.Status = state.health.Status
+	var alerts []*Alert
+	switch {
+	case health.Status == StatusHealthy && failing, health.Status == StatusUnhealthy && recovered:
+		state.streak++
+	default:
+		state.streak = 0
+	}
+	if state.streak >= m.opts.ConsecutivePolls {
+		state.streak = 0
+		kind := AlertUnhealthy
+		health.Status = StatusUnhealthy
+		if state.health.Status == StatusUnhealthy {
+			kind = AlertRecovered
+			health.Status = StatusHealthy
+		}
+		alerts = append(alerts, newAlert(kind, &health))
+	}
+	if failingSince.IsZero() {
+		state.disableRiskAlerted = false
+	} else if now.Sub(failingSince) >= m.opts.DisableWarningAfter && !state.disableRiskAlerted {
+		state.disableRiskAlerted = true
+		alerts = append(alerts, newAlert(AlertDisableRisk, &health))
+	}
+	state.health = health
+	state.failures = failures
+	m.mu.Unlock()
+
+	for _, alert := range alerts {
+		m.notify(ctx, alert)
+	}
+	return nil
+}
+
+// failingSince returns when the endpoint started failing, according to its
+// attempts: the time of the first failure after the last success, like
+// Svix counts it. It is zero if the last attempt succeeded.
+//
+// The failures since the last success are only paged through when it
+// changed from the cached one, since the start of the streak can't have
+// changed otherwise.
+func (m *Monitor) failingSince(ctx context.Context, appId string, ep openapi.EndpointOut, cached failureHistory) (failureHistory, error) {
+	last := func(status openapi.MessageStatus) (*openapi.MessageAttemptOut, error) {
+		s := svix.MessageStatus(status)
+		out, err := m.client.MessageAttempt.ListByEndpoint(ctx, appId, ep.Id, &svix.MessageAttemptListOptions{
+			Limit:  svix.Int32(1),
+			Status: &s,
+		})
+		if err != nil || len(out.Data) == 0 {
+			return nil, err
+		}
+		return &out.Data[0], nil
+	}
+	failure, err := last(openapi.MESSAGESTATUS_Fail)
+	if err != nil || failure == nil {
+		return failureHistory{}, err
+	}
+	success, err := last(openapi.MESSAGESTATUS_Success)
+	if err != nil {
+		return failureHistory{}, err
+	}
+	history := failureHistory{lastFailureId: failure.Id}
+	if success != nil {
+		history.lastSuccessId = success.Id
+		if success.Timestamp.After(failure.Timestamp) {
+			return history, nil
+		}
+	}
+	if cached.lastFailureId != "" && !cached.since.IsZero() && cached.lastSuccessId == history.lastSuccessId {
+		history.since = cached.since
+		return history, nil
+	}
+
+	// Attempts are listed most recent first.
+	status := svix.MessageStatus(openapi.MESSAGESTATUS_Fail)
+	options := &svix.MessageAttemptListOptions{Limit: svix.Int32(paginate.PageSize), Status: &status}
+	if success != nil {
+		options.After = &success.Timestamp
+	}
+	history.since = failure.Timestamp
+	err = paginate.Each(func(iterator *string) (*string, bool, error) {
+		options.Iterator = iterator
+		out, err := m.client.MessageAttempt.ListByEndpoint(ctx, appId, ep.Id, options)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, attempt := range out.Data {
+			// After is sent with a precision of a second.
+			if success != nil && !attempt.Timestamp.After(success.Timestamp) {
+				return nil, true, nil
+			}
+			if attempt.Timestamp.Before(history.since) {
+				history.since = attempt.Timestamp
+			}
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return failureHistory{}, err
+	}
+	return history, nil
+}
+
+func (m *Monitor) notify(ctx context.Context, alert *Alert) {
+	for _, n := range m.opts.Notifiers {
+		if err := n.Notify(ctx, alert); err != nil {
+			m.reportError(fmt.Errorf("notifying of %s: %w", alert, err))
+		}
+	}
+}
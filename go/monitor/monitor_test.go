@@ -0,0 +1,224 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/monitor"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestMonitor(t *testing.T) {
+	var healthy atomic.Bool
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			if healthy.Load() {
+				return 200, ""
+			}
+			return 500, ""
+		},
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(n int) {
+		for i := 0; i < n; i++ {
+			if _, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{EventType: "user.signup", Payload: map[string]interface{}{}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		srv.WaitForDeliveries()
+	}
+
+	// attempts returns the attempts of the endpoint, most recent first.
+	attempts := func() *svix.ListResponseMessageAttemptOut {
+		t.Helper()
+		out, err := client.MessageAttempt.ListByEndpoint(ctx, app.Id, ep.Id, &svix.MessageAttemptListOptions{Limit: svix.Int32(250)})
+		if err != nil || len(out.Data) == 0 {
+			t.Fatalf("unexpected attempts %+v (%v)", out, err)
+		}
+		return out
+	}
+
+	var alerts []*monitor.Alert
+	m := monitor.New(client, &monitor.Options{
+		Windows:             []time.Duration{time.Hour},
+		MinDeliveries:       4,
+		DisableWarningAfter: time.Nanosecond,
+		Notifiers: []monitor.Notifier{monitor.NotifierFunc(func(ctx context.Context, alert *monitor.Alert) error {
+			alerts = append(alerts, alert)
+			return nil
+		})},
+		OnError: func(err error) { t.Error(err) },
+	})
+	poll := func(expected ...monitor.AlertKind) {
+		t.Helper()
+		alerts = nil
+		if err := m.Poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var kinds []monitor.AlertKind
+		for _, a := range alerts {
+			kinds = append(kinds, a.Kind)
+		}
+		if len(kinds) != len(expected) {
+			t.Fatalf("expected alerts %v, got %v", expected, kinds)
+		}
+		for i := range kinds {
+			if kinds[i] != expected[i] {
+				t.Fatalf("expected alerts %v, got %v", expected, kinds)
+			}
+		}
+	}
+
+	// Too few deliveries to judge.
+	send(2)
+	poll(monitor.AlertDisableRisk)
+	send(2)
+	// The endpoint only becomes unhealthy on the second poll, and is only
+	// reported once.
+	poll()
+	poll(monitor.AlertUnhealthy)
+	if alerts[0].EndpointId != ep.Id || alerts[0].Windows[0].Fail != 4 || !alerts[0].FailingSince.Equal(attempts().Data[3].Timestamp) {
+		t.Errorf("unexpected alert: %+v", alerts[0])
+	}
+	poll()
+	if h := m.Health(); len(h) != 1 || h[0].Status != monitor.StatusUnhealthy {
+		t.Fatalf("unexpected health: %+v", h)
+	}
+
+	// A rate between the thresholds isn't a recovery.
+	healthy.Store(true)
+	send(4)
+	poll()
+	poll()
+	send(16)
+	poll()
+	poll(monitor.AlertRecovered)
+	if h := m.Health(); h[0].Status != monitor.StatusHealthy || !h[0].FailingSince.IsZero() {
+		t.Fatalf("unexpected health: %+v", h)
+	}
+
+	// A new failing streak is reported again, from its first failure.
+	healthy.Store(false)
+	send(1)
+	poll(monitor.AlertDisableRisk)
+	failure := attempts().Data[0]
+	send(1)
+	poll()
+	if h := m.Health(); !h[0].FailingSince.Equal(failure.Timestamp) {
+		t.Fatalf("expected the endpoint to fail since %s, got %+v", failure.Timestamp, h)
+	}
+
+	if err := client.Endpoint.Delete(ctx, app.Id, ep.Id); err != nil {
+		t.Fatal(err)
+	}
+	poll()
+	if h := m.Health(); len(h) != 0 {
+		t.Fatalf("expected deleted endpoints to be forgotten, got %+v", h)
+	}
+}
+
+type attemptCountingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *attemptCountingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if strings.Contains(r.URL.Path, "/attempt/endpoint/") {
+		t.requests.Add(1)
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestMonitorCachesFailingSince(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) { return 500, "" },
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	send := func() {
+		if _, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{EventType: "user.signup", Payload: map[string]interface{}{}}); err != nil {
+			t.Fatal(err)
+		}
+		srv.WaitForDeliveries()
+	}
+
+	transport := &attemptCountingTransport{}
+	counted := svix.New("testsk_monitor", &svix.SvixOptions{ServerUrl: srv.URL(), HTTPClient: &http.Client{Transport: transport}})
+	m := monitor.New(counted, &monitor.Options{OnError: func(err error) { t.Error(err) }})
+	send()
+	if err := m.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The last failure and success, and a page of failures.
+	if n := transport.requests.Swap(0); n != 3 {
+		t.Fatalf("expected 3 attempt listings on the first poll, got %d", n)
+	}
+	since := m.Health()[0].FailingSince
+	for i := 0; i < 3; i++ {
+		send()
+		if err := m.Poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if n := transport.requests.Swap(0); n != 2 {
+			t.Fatalf("expected only the last failure and success to be listed, got %d listings", n)
+		}
+		if h := m.Health(); !h[0].FailingSince.Equal(since) {
+			t.Fatalf("expected the endpoint to fail since %s, got %+v", since, h)
+		}
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]interface{}
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	n := monitor.WebhookNotifier(srv.URL, nil)
+	alert := &monitor.Alert{Kind: monitor.AlertUnhealthy, EndpointHealth: monitor.EndpointHealth{
+		AppId:      "app_1",
+		EndpointId: "ep_1",
+		Windows:    []monitor.WindowStats{{Window: 15 * time.Minute, Success: 1, Fail: 3}},
+	}}
+	if err := n.Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	window := received["windows"].([]interface{})[0].(map[string]interface{})
+	if received["kind"] != "unhealthy" || received["endpointId"] != "ep_1" || window["window"] != "15m0s" || window["failureRate"] != 0.75 {
+		t.Errorf("unexpected body: %v", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := n.Notify(context.Background(), alert); err == nil {
+		t.Error("expected an error status to be reported")
+	}
+}
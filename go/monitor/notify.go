@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AlertKind string
+
+const (
+	// The failure rate of the endpoint went above FailureThreshold.
+	AlertUnhealthy AlertKind = "unhealthy"
+	// The failure rate of an unhealthy endpoint went back below
+	// RecoveryThreshold.
+	AlertRecovered AlertKind = "recovered"
+	// The endpoint has been failing for longer than DisableWarningAfter,
+	// and may soon be disabled by Svix.
+	AlertDisableRisk AlertKind = "disable-risk"
+)
+
+type Alert struct {
+	Kind AlertKind `json:"kind"`
+	EndpointHealth
+}
+
+func newAlert(kind AlertKind, health *EndpointHealth) *Alert {
+	return &Alert{Kind: kind, EndpointHealth: *health}
+}
+
+// String describes the alert in a line, e.g. `endpoint ep_... (https://...)
+// of app_... is unhealthy: 62% failed over 15m0s (31/50)`.
+func (a *Alert) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "endpoint %s (%s) of %s ", a.EndpointId, a.Url, a.AppId)
+	switch a.Kind {
+	case AlertDisableRisk:
+		fmt.Fprintf(&b, "has been failing for %s and may be disabled", a.CheckedAt.Sub(a.FailingSince).Round(time.Minute))
+		return b.String()
+	case AlertRecovered:
+		b.WriteString("recovered")
+	default:
+		b.WriteString("is unhealthy")
+	}
+	for i, w := range a.Windows {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%.0f%% failed over %s (%d/%d)", 100*w.FailureRate(), w.Window, w.Fail, w.Success+w.Fail)
+	}
+	return b.String()
+}
+
+// Notifier is told about the alerts raised by a Monitor.
+type Notifier interface {
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+// NotifierFunc is a function used as a Notifier.
+type NotifierFunc func(ctx context.Context, alert *Alert) error
+
+func (f NotifierFunc) Notify(ctx context.Context, alert *Alert) error {
+	return f(ctx, alert)
+}
+
+// LogNotifier logs the alerts with logger, or the standard logger if nil.
+func LogNotifier(logger *log.Logger) Notifier {
+	return NotifierFunc(func(ctx context.Context, alert *Alert) error {
+		if logger == nil {
+			log.Print(alert)
+		} else {
+			logger.Print(alert)
+		}
+		return nil
+	})
+}
+
+// WebhookNotifier posts the alerts as JSON to url, using client or
+// http.DefaultClient if nil. Responses with a status other than 2xx are
+// errors.
+func WebhookNotifier(url string, client *http.Client) Notifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return NotifierFunc(func(ctx context.Context, alert *Alert) error {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("monitor: webhook responded with %s", resp.Status)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,315 @@
+package svix
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/outboxutil"
+)
+
+var ErrOutboxClosed = errors.New("outbox is closed")
+
+// Outbox accepts messages into a durable store and sends them to Svix in the
+// background, retrying on failure.
+//
+// Every message is sent with an idempotency key derived from its `eventId`
+// (one is generated when the message doesn't have one), so a message that is
+// retried after an ambiguous failure is never delivered twice.
+type Outbox struct {
+	client  *Svix
+	store   OutboxStore
+	options OutboxOptions
+
+	jobs chan *OutboxEntry
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+
+	// Cancels the requests in flight when Close gives up on flushing.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	closed   bool
+	progress chan struct{}
+}
+
+type OutboxOptions struct {
+	// Number of goroutines sending messages concurrently. Defaults to 4.
+	Workers int
+	// Maximum number of entries claimed from the store at once. Defaults to 100.
+	BatchSize int
+	// How often the store is polled for due entries when idle. Defaults to 1 second.
+	PollInterval time.Duration
+	// Number of attempts before an entry is given up on. Zero means retry forever.
+	MaxAttempts int
+	// Returns the delay before the given (1-based) retry attempt.
+	// Defaults to exponential backoff starting at 1 second and capped at 5 minutes.
+	Backoff func(attempt int) time.Duration
+	// Called after an entry was sent successfully. out is nil when Svix
+	// already had the message, i.e. when a previous attempt went through
+	// even though its response was lost.
+	OnSent func(entry *OutboxEntry, out *MessageOut)
+	// Called when an entry is dropped, either because the error isn't
+	// retryable or because MaxAttempts was reached.
+	OnFailure func(entry *OutboxEntry, err error)
+	// Called when the store fails to remove or update an entry after an
+	// attempt. The claim on the entry is released, so it is attempted again.
+	// Also called with a nil entry when the store fails to claim entries,
+	// which is retried after PollInterval.
+	OnStoreError func(entry *OutboxEntry, err error)
+}
+
+// OutboxEntry is a message waiting in an OutboxStore.
+type OutboxEntry struct {
+	Id          string
+	AppId       string
+	Message     MessageIn
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+	CreatedAt   time.Time
+}
+
+// IdempotencyKey returns the key used when sending the entry to Svix.
+func (e *OutboxEntry) IdempotencyKey() string {
+	eventId := ""
+	if e.Message.EventId.Get() != nil {
+		eventId = *e.Message.EventId.Get()
+	}
+	return outboxutil.IdempotencyKey(e.AppId, eventId)
+}
+
+func NewOutbox(client *Svix, store OutboxStore, options *OutboxOptions) *Outbox {
+	o := &Outbox{
+		client:   client,
+		store:    store,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		progress: make(chan struct{}),
+	}
+	o.ctx, o.cancel = context.WithCancel(context.Background())
+	if options != nil {
+		o.options = *options
+	}
+	if o.options.Workers <= 0 {
+		o.options.Workers = 4
+	}
+	if o.options.BatchSize <= 0 {
+		o.options.BatchSize = 100
+	}
+	if o.options.PollInterval <= 0 {
+		o.options.PollInterval = time.Second
+	}
+	if o.options.Backoff == nil {
+		o.options.Backoff = outboxutil.Backoff
+	}
+	o.jobs = make(chan *OutboxEntry)
+
+	go o.run()
+	return o
+}
+
+// Enqueue persists the message in the outbox store and returns its `eventId`.
+//
+// The message is sent asynchronously; a nil error only means it was stored.
+func (o *Outbox) Enqueue(ctx context.Context, appId string, messageIn *MessageIn) (string, error) {
+	o.mu.Lock()
+	closed := o.closed
+	o.mu.Unlock()
+	if closed {
+		return "", ErrOutboxClosed
+	}
+
+	msg := *messageIn
+	if msg.EventId.Get() == nil || *msg.EventId.Get() == "" {
+		msg.EventId = *NullableString(String("evt_" + outboxutil.NewId()))
+	}
+	now := time.Now()
+	entry := &OutboxEntry{
+		Id:          outboxutil.NewId(),
+		AppId:       appId,
+		Message:     msg,
+		NextAttempt: now,
+		CreatedAt:   now,
+	}
+	if err := o.store.Put(ctx, entry); err != nil {
+		return "", err
+	}
+	o.notify()
+	return *msg.EventId.Get(), nil
+}
+
+// Flush blocks until every entry in the store has been sent or dropped, or
+// until ctx is done. Entries waiting for a retry are waited for, not forced.
+func (o *Outbox) Flush(ctx context.Context) error {
+	for {
+		n, err := o.store.Len(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		o.mu.Lock()
+		progress := o.progress
+		o.mu.Unlock()
+		o.notify()
+		select {
+		case <-progress:
+		case <-o.done:
+			return ErrOutboxClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close stops accepting new messages, flushes the store and stops the
+// workers. If ctx is done before the flush completes, the requests in flight
+// are cancelled and the workers are stopped anyway; entries still in the
+// store are picked up by the next Outbox opened on it.
+func (o *Outbox) Close(ctx context.Context) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return ErrOutboxClosed
+	}
+	o.closed = true
+	o.mu.Unlock()
+
+	err := o.Flush(ctx)
+	o.cancel()
+	close(o.stop)
+	<-o.done
+	return err
+}
+
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (o *Outbox) run() {
+	defer close(o.done)
+
+	var workers sync.WaitGroup
+	var batch sync.WaitGroup
+	for i := 0; i < o.options.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for entry := range o.jobs {
+				o.send(entry)
+				batch.Done()
+			}
+		}()
+	}
+	defer func() {
+		close(o.jobs)
+		workers.Wait()
+	}()
+
+	ticker := time.NewTicker(o.options.PollInterval)
+	defer ticker.Stop()
+	ctx := context.Background()
+	for {
+		entries, err := o.store.Claim(ctx, o.options.BatchSize, time.Now())
+		if err != nil {
+			o.storeError(nil, err)
+		} else if len(entries) > 0 {
+			batch.Add(len(entries))
+			for _, entry := range entries {
+				o.jobs <- entry
+			}
+			batch.Wait()
+
+			o.mu.Lock()
+			close(o.progress)
+			o.progress = make(chan struct{})
+			o.mu.Unlock()
+
+			select {
+			case <-o.stop:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-o.stop:
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Outbox) send(entry *OutboxEntry) {
+	out, err := o.client.Message.CreateWithOptions(o.ctx, entry.AppId, &entry.Message, &PostOptions{
+		IdempotencyKey: String(entry.IdempotencyKey()),
+	})
+	if err != nil && o.ctx.Err() != nil {
+		// Cancelled by Close: the attempt doesn't count.
+		o.nack(entry)
+		return
+	}
+	entry.Attempts++
+	if err == nil || outboxutil.IsConflict(err) {
+		if o.ack(entry) && o.options.OnSent != nil {
+			o.options.OnSent(entry, out)
+		}
+		return
+	}
+
+	entry.LastError = err.Error()
+	if !isOutboxRetryable(err) || (o.options.MaxAttempts > 0 && entry.Attempts >= o.options.MaxAttempts) {
+		if o.ack(entry) && o.options.OnFailure != nil {
+			o.options.OnFailure(entry, err)
+		}
+		return
+	}
+	entry.NextAttempt = time.Now().Add(o.options.Backoff(entry.Attempts))
+	o.nack(entry)
+}
+
+// ack removes the entry from the store. When that fails the entry is
+// released to be attempted again later, and false is returned.
+//
+// The store is updated with a background context: the outcome of a request
+// that completed must be recorded even when Close is giving up.
+func (o *Outbox) ack(entry *OutboxEntry) bool {
+	err := o.store.Ack(context.Background(), entry.Id)
+	if err == nil {
+		return true
+	}
+	o.storeError(entry, err)
+	entry.NextAttempt = time.Now().Add(o.options.Backoff(entry.Attempts))
+	o.nack(entry)
+	return false
+}
+
+func (o *Outbox) nack(entry *OutboxEntry) {
+	if err := o.store.Nack(context.Background(), entry); err != nil {
+		o.storeError(entry, err)
+	}
+}
+
+func (o *Outbox) storeError(entry *OutboxEntry, err error) {
+	if o.options.OnStoreError != nil {
+		o.options.OnStoreError(entry, err)
+	}
+}
+
+// isOutboxRetryable reports whether sending a message may succeed when
+// retried. Payloads rejected by SchemaValidation aren't retried.
+func isOutboxRetryable(err error) bool {
+	var invalid *PayloadValidationError
+	return !errors.As(err, &invalid) && outboxutil.IsRetryable(err)
+}
@@ -0,0 +1,248 @@
+package svix
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+// OutboxStore persists the entries of an Outbox until they are sent.
+//
+// Implementations must be safe for concurrent use.
+type OutboxStore interface {
+	// Put adds a new entry to the store.
+	Put(ctx context.Context, entry *OutboxEntry) error
+	// Claim returns up to n entries whose NextAttempt is not after now, oldest
+	// first. Claimed entries are not returned again until they are released
+	// with Nack.
+	Claim(ctx context.Context, n int, now time.Time) ([]*OutboxEntry, error)
+	// Ack removes a claimed entry from the store. The claim is released even
+	// when removing the entry fails.
+	Ack(ctx context.Context, id string) error
+	// Nack saves the updated entry and releases the claim on it. The claim is
+	// released even when saving the entry fails.
+	Nack(ctx context.Context, entry *OutboxEntry) error
+	// Len returns the number of entries in the store, claimed or not.
+	Len(ctx context.Context) (int, error)
+}
+
+// MemoryOutboxStore is an OutboxStore that keeps entries in memory.
+// Entries are lost when the process exits.
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries []*OutboxEntry
+	claimed map[string]bool
+}
+
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{
+		claimed: make(map[string]bool),
+	}
+}
+
+func (s *MemoryOutboxStore) Put(ctx context.Context, entry *OutboxEntry) error {
+	e := *entry
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &e)
+	return nil
+}
+
+func (s *MemoryOutboxStore) Claim(ctx context.Context, n int, now time.Time) ([]*OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret []*OutboxEntry
+	for _, entry := range s.entries {
+		if len(ret) >= n {
+			break
+		}
+		if s.claimed[entry.Id] || entry.NextAttempt.After(now) {
+			continue
+		}
+		s.claimed[entry.Id] = true
+		e := *entry
+		ret = append(ret, &e)
+	}
+	return ret, nil
+}
+
+func (s *MemoryOutboxStore) Ack(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, entry := range s.entries {
+		if entry.Id == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	delete(s.claimed, id)
+	return nil
+}
+
+func (s *MemoryOutboxStore) Nack(ctx context.Context, entry *OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.entries {
+		if existing.Id == entry.Id {
+			e := *entry
+			s.entries[i] = &e
+			break
+		}
+	}
+	delete(s.claimed, entry.Id)
+	return nil
+}
+
+// release releases the claim on an entry without updating it.
+func (s *MemoryOutboxStore) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claimed, id)
+}
+
+func (s *MemoryOutboxStore) Len(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries), nil
+}
+
+// FileOutboxStore is an OutboxStore that keeps one JSON file per entry in a
+// directory, so pending messages survive restarts. Claims are held in memory:
+// entries that were being sent when the process died are sent again on the
+// next start, under the same idempotency key.
+type FileOutboxStore struct {
+	dir string
+	mem *MemoryOutboxStore
+}
+
+type outboxEntryFile struct {
+	Id          string            `json:"id"`
+	AppId       string            `json:"appId"`
+	Message     openapi.MessageIn `json:"message"`
+	Attempts    int               `json:"attempts"`
+	NextAttempt time.Time         `json:"nextAttempt"`
+	LastError   string            `json:"lastError,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+const outboxFileSuffix = ".json"
+
+// NewFileOutboxStore opens (creating it if needed) a store in dir and loads
+// the entries left there by previous runs.
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileOutboxStore{
+		dir: dir,
+		mem: NewMemoryOutboxStore(),
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), outboxFileSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var f outboxEntryFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, err
+		}
+		s.mem.entries = append(s.mem.entries, &OutboxEntry{
+			Id:          f.Id,
+			AppId:       f.AppId,
+			Message:     MessageIn(f.Message),
+			Attempts:    f.Attempts,
+			NextAttempt: f.NextAttempt,
+			LastError:   f.LastError,
+			CreatedAt:   f.CreatedAt,
+		})
+	}
+	sort.SliceStable(s.mem.entries, func(i, j int) bool {
+		return s.mem.entries[i].CreatedAt.Before(s.mem.entries[j].CreatedAt)
+	})
+	return s, nil
+}
+
+func (s *FileOutboxStore) Put(ctx context.Context, entry *OutboxEntry) error {
+	if err := s.write(entry); err != nil {
+		return err
+	}
+	return s.mem.Put(ctx, entry)
+}
+
+func (s *FileOutboxStore) Claim(ctx context.Context, n int, now time.Time) ([]*OutboxEntry, error) {
+	return s.mem.Claim(ctx, n, now)
+}
+
+func (s *FileOutboxStore) Ack(ctx context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		s.mem.release(id)
+		return err
+	}
+	return s.mem.Ack(ctx, id)
+}
+
+func (s *FileOutboxStore) Nack(ctx context.Context, entry *OutboxEntry) error {
+	if err := s.write(entry); err != nil {
+		s.mem.release(entry.Id)
+		return err
+	}
+	return s.mem.Nack(ctx, entry)
+}
+
+func (s *FileOutboxStore) Len(ctx context.Context) (int, error) {
+	return s.mem.Len(ctx)
+}
+
+func (s *FileOutboxStore) path(id string) string {
+	return filepath.Join(s.dir, id+outboxFileSuffix)
+}
+
+// write atomically replaces the file of an entry.
+func (s *FileOutboxStore) write(entry *OutboxEntry) error {
+	data, err := json.Marshal(outboxEntryFile{
+		Id:          entry.Id,
+		AppId:       entry.AppId,
+		Message:     openapi.MessageIn(entry.Message),
+		Attempts:    entry.Attempts,
+		NextAttempt: entry.NextAttempt,
+		LastError:   entry.LastError,
+		CreatedAt:   entry.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, "."+entry.Id+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(entry.Id))
+}
@@ -0,0 +1,331 @@
+package svix_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+type outboxTestServer struct {
+	mu       sync.Mutex
+	failures int
+	// The status of failures, 429 by default.
+	status   int
+	attempts int
+	keys     []string
+	eventIds []string
+}
+
+func (s *outboxTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.failures > 0 {
+		s.failures--
+		status := s.status
+		if status == 0 {
+			status = http.StatusTooManyRequests
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":"failed","detail":"failed"}`))
+		return
+	}
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	s.keys = append(s.keys, r.Header.Get("idempotency-key"))
+	s.eventIds = append(s.eventIds, body["eventId"].(string))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        "msg_1",
+		"eventType": body["eventType"],
+		"eventId":   body["eventId"],
+		"payload":   body["payload"],
+		"timestamp": time.Now(),
+	})
+}
+
+func newOutboxTestClient(t *testing.T, handler http.Handler) *svix.Svix {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	serverUrl, _ := url.Parse(srv.URL)
+	return svix.New("test_token", &svix.SvixOptions{ServerUrl: serverUrl})
+}
+
+func TestOutboxRetriesWithStableIdempotencyKey(t *testing.T) {
+	srv := &outboxTestServer{failures: 2}
+	client := newOutboxTestClient(t, srv)
+
+	outbox := svix.NewOutbox(client, svix.NewMemoryOutboxStore(), &svix.OutboxOptions{
+		Backoff: func(int) time.Duration { return 0 },
+	})
+	eventId, err := outbox.Enqueue(context.Background(), "app_1", &svix.MessageIn{
+		EventType: "user.signup",
+		Payload:   map[string]interface{}{"id": "u_1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(srv.eventIds) != 1 || srv.eventIds[0] != eventId {
+		t.Fatalf("expected a single delivery of %s, got %v", eventId, srv.eventIds)
+	}
+	if srv.keys[0] != "outbox:app_1:"+eventId {
+		t.Errorf("unexpected idempotency key %q", srv.keys[0])
+	}
+	if _, err := outbox.Enqueue(context.Background(), "app_1", &svix.MessageIn{}); err != svix.ErrOutboxClosed {
+		t.Errorf("expected ErrOutboxClosed, got %v", err)
+	}
+}
+
+func TestFileOutboxStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := svix.NewFileOutboxStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &svix.OutboxEntry{
+		Id:    "entry_1",
+		AppId: "app_1",
+		Message: svix.MessageIn{
+			EventType: "user.signup",
+			EventId:   *svix.NullableString(svix.String("evt_1")),
+			Payload:   map[string]interface{}{"id": "u_1"},
+		},
+		CreatedAt: time.Now(),
+	}
+	if err := store.Put(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	claimed, _ := store.Claim(ctx, 10, time.Now())
+	if len(claimed) != 1 {
+		t.Fatalf("expected one claimed entry, got %d", len(claimed))
+	}
+
+	reopened, err := svix.NewFileOutboxStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, _ = reopened.Claim(ctx, 10, time.Now())
+	if len(claimed) != 1 || claimed[0].IdempotencyKey() != "outbox:app_1:evt_1" {
+		t.Fatalf("entry was not restored: %+v", claimed)
+	}
+	if err := reopened.Ack(ctx, "entry_1"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := reopened.Len(ctx); n != 0 {
+		t.Errorf("expected empty store, got %d entries", n)
+	}
+}
+
+type outboxResult struct {
+	mu      sync.Mutex
+	sent    int
+	dropped []*svix.OutboxEntry
+}
+
+func (r *outboxResult) options(o *svix.OutboxOptions) *svix.OutboxOptions {
+	o.Backoff = func(int) time.Duration { return 0 }
+	o.OnSent = func(*svix.OutboxEntry, *svix.MessageOut) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sent++
+	}
+	o.OnFailure = func(entry *svix.OutboxEntry, err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.dropped = append(r.dropped, entry)
+	}
+	return o
+}
+
+func enqueueOutboxTestMessage(t *testing.T, outbox *svix.Outbox) {
+	t.Helper()
+	_, err := outbox.Enqueue(context.Background(), "app_1", &svix.MessageIn{
+		EventType: "user.signup",
+		Payload:   map[string]interface{}{"id": "u_1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxMaxAttempts(t *testing.T) {
+	srv := &outboxTestServer{failures: 100, status: http.StatusServiceUnavailable}
+	client := newOutboxTestClient(t, srv)
+	var result outboxResult
+	store := svix.NewMemoryOutboxStore()
+	outbox := svix.NewOutbox(client, store, result.options(&svix.OutboxOptions{MaxAttempts: 3}))
+	enqueueOutboxTestMessage(t, outbox)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if result.sent != 0 || len(result.dropped) != 1 || result.dropped[0].Attempts != 3 || result.dropped[0].LastError == "" {
+		t.Fatalf("expected the entry to be dropped after 3 attempts, got %d sent and %+v", result.sent, result.dropped)
+	}
+	if n, _ := store.Len(ctx); n != 0 {
+		t.Errorf("expected empty store, got %d entries", n)
+	}
+}
+
+func TestOutboxDropsNonRetryableErrors(t *testing.T) {
+	srv := &outboxTestServer{failures: 1, status: http.StatusUnprocessableEntity}
+	client := newOutboxTestClient(t, srv)
+	var result outboxResult
+	outbox := svix.NewOutbox(client, svix.NewMemoryOutboxStore(), result.options(&svix.OutboxOptions{}))
+	enqueueOutboxTestMessage(t, outbox)
+	enqueueOutboxTestMessage(t, outbox)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.attempts != 2 || result.sent != 1 || len(result.dropped) != 1 || result.dropped[0].Attempts != 1 {
+		t.Fatalf("expected one entry to be dropped without retrying, got %d attempts, %d sent and %+v", srv.attempts, result.sent, result.dropped)
+	}
+}
+
+// failingAckStore fails to remove the first entry acked.
+type failingAckStore struct {
+	*svix.MemoryOutboxStore
+	mu     sync.Mutex
+	failed bool
+}
+
+func (s *failingAckStore) Ack(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.failed {
+		s.failed = true
+		// Release the claim, as the contract of OutboxStore requires.
+		s.MemoryOutboxStore.Nack(ctx, &svix.OutboxEntry{Id: id})
+		return errors.New("disk full")
+	}
+	return s.MemoryOutboxStore.Ack(ctx, id)
+}
+
+func TestOutboxFlush(t *testing.T) {
+	srv := &outboxTestServer{failures: 2}
+	client := newOutboxTestClient(t, srv)
+	var result outboxResult
+	var storeErrors []error
+	store := &failingAckStore{MemoryOutboxStore: svix.NewMemoryOutboxStore()}
+	options := result.options(&svix.OutboxOptions{})
+	options.OnStoreError = func(entry *svix.OutboxEntry, err error) {
+		storeErrors = append(storeErrors, err)
+	}
+	outbox := svix.NewOutbox(client, store, options)
+	for i := 0; i < 3; i++ {
+		enqueueOutboxTestMessage(t, outbox)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := outbox.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := store.Len(ctx); n != 0 {
+		t.Errorf("expected empty store, got %d entries", n)
+	}
+	if result.sent != 3 || len(storeErrors) != 1 {
+		t.Errorf("expected 3 entries sent and one store error, got %d and %v", result.sent, storeErrors)
+	}
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingClaimStore fails to claim entries the first times.
+type failingClaimStore struct {
+	*svix.MemoryOutboxStore
+	mu       sync.Mutex
+	failures int
+}
+
+func (s *failingClaimStore) Claim(ctx context.Context, n int, now time.Time) ([]*svix.OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return nil, errors.New("connection refused")
+	}
+	return s.MemoryOutboxStore.Claim(ctx, n, now)
+}
+
+func TestOutboxReportsClaimErrors(t *testing.T) {
+	client := newOutboxTestClient(t, &outboxTestServer{})
+	var result outboxResult
+	var mu sync.Mutex
+	var storeErrors []error
+	store := &failingClaimStore{MemoryOutboxStore: svix.NewMemoryOutboxStore(), failures: 3}
+	options := result.options(&svix.OutboxOptions{PollInterval: 10 * time.Millisecond})
+	options.OnStoreError = func(entry *svix.OutboxEntry, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if entry != nil {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		storeErrors = append(storeErrors, err)
+	}
+	outbox := svix.NewOutbox(client, store, options)
+	enqueueOutboxTestMessage(t, outbox)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if result.sent != 1 || len(storeErrors) != 3 {
+		t.Errorf("expected the entry to be sent after 3 store errors, got %d sent and %v", result.sent, storeErrors)
+	}
+}
+
+func TestOutboxCloseCancelsRequests(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client := newOutboxTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	store := svix.NewMemoryOutboxStore()
+	outbox := svix.NewOutbox(client, store, &svix.OutboxOptions{})
+	enqueueOutboxTestMessage(t, outbox)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := outbox.Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the flush to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close waited %s for the request in flight", elapsed)
+	}
+	claimed, _ := store.Claim(context.Background(), 10, time.Now())
+	if len(claimed) != 1 || claimed[0].Attempts != 0 {
+		t.Errorf("expected the entry to be released without counting the attempt, got %+v", claimed)
+	}
+}
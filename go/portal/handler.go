@@ -0,0 +1,213 @@
+package portal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+var (
+	// ErrUnauthenticated is returned by a Resolver when the request isn't
+	// authenticated. The handler responds with a 401.
+	ErrUnauthenticated = errors.New("the request isn't authenticated")
+	// ErrForbidden is returned by a Resolver when the user doesn't have
+	// access to the application portal. The handler responds with a 403.
+	ErrForbidden = errors.New("the user doesn't have access to the application portal")
+)
+
+// Access is the application portal access of the user of a request.
+type Access struct {
+	// Identifies the user, for rate limiting and caching.
+	UserId string
+	// The uid of the application of the user.
+	AppUid string
+	// The name of the application, used when it's created. Defaults to
+	// AppUid.
+	AppName string
+	// The feature flags of the session, in addition to the default ones of
+	// the manager.
+	FeatureFlags []string
+}
+
+// Resolver authenticates the user of a request, typically from a session
+// cookie, and returns their access. It returns an error wrapping
+// ErrUnauthenticated or ErrForbidden to refuse the request.
+type Resolver func(r *http.Request) (*Access, error)
+
+type HandlerOptions struct {
+	// Whether the application of a user is created with
+	// Application.GetOrCreate when it doesn't exist yet.
+	CreateApps bool
+	// How many requests a user can make per minute. Defaults to 10; a
+	// negative value disables the limit.
+	RateLimit int
+	// Logs the errors returned by Svix and the resolver. Defaults to the
+	// standard logger.
+	ErrorLog *log.Logger
+}
+
+// HandlerResponse is the body of the successful responses of the handler.
+type HandlerResponse struct {
+	Url       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type handler struct {
+	m       *Manager
+	resolve Resolver
+	options HandlerOptions
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is the token bucket limiting the requests of a user.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Handler returns an http.Handler that responds to GET and POST requests
+// with a JSON HandlerResponse holding the magic link to the application
+// portal of the user of the request, as resolved by resolve.
+//
+// Sessions are cached per user, and revoked along with the others by
+// RevokeAll.
+func (m *Manager) Handler(resolve Resolver, options *HandlerOptions) http.Handler {
+	h := &handler{
+		m:       m,
+		resolve: resolve,
+		buckets: make(map[string]*bucket),
+	}
+	if options != nil {
+		h.options = *options
+	}
+	if h.options.RateLimit == 0 {
+		h.options.RateLimit = 10
+	}
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	access, err := h.resolve(r)
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	case err != nil:
+		h.logf("portal: resolving the access of the request: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	case access == nil || access.AppUid == "":
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if wait := h.limit(access.UserId); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	ctx := r.Context()
+	session, err := h.session(ctx, access)
+	var svixErr *svix.Error
+	if errors.As(err, &svixErr) && svixErr.Status() == http.StatusNotFound {
+		// The application may have been deleted since its id was resolved.
+		h.m.forgetAppId(access.AppUid)
+		session, err = h.session(ctx, access)
+	}
+	if err != nil {
+		h.logf("portal: creating a session for application %s: %v", access.AppUid, err)
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(HandlerResponse{Url: session.Url, ExpiresAt: session.ExpiresAt})
+}
+
+// session returns the session of the user. Sessions are cached by the id
+// of the application, so that RevokeAll drops them given its id.
+func (h *handler) session(ctx context.Context, access *Access) (*Session, error) {
+	appId, err := h.m.appId(access.AppUid, func() (*svix.ApplicationOut, error) {
+		app, err := h.m.client.Application.Get(ctx, access.AppUid)
+		var svixErr *svix.Error
+		if h.options.CreateApps && errors.As(err, &svixErr) && svixErr.Status() == http.StatusNotFound {
+			name := access.AppName
+			if name == "" {
+				name = access.AppUid
+			}
+			app, err = h.m.client.Application.GetOrCreate(ctx, &svix.ApplicationIn{Name: name, Uid: *svix.NullableString(&access.AppUid)})
+		}
+		return app, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h.m.session(ctx, appId, "user\x00"+access.UserId, access.FeatureFlags)
+}
+
+// limit takes a token from the bucket of the user, returning how long to
+// wait for one when it's empty.
+func (h *handler) limit(userId string) time.Duration {
+	if h.options.RateLimit < 0 {
+		return 0
+	}
+	capacity := float64(h.options.RateLimit)
+	perSecond := capacity / 60
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.buckets[userId]
+	if !ok {
+		h.prune(now, capacity, perSecond)
+		b = &bucket{tokens: capacity, updated: now}
+		h.buckets[userId] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// prune drops the buckets that have refilled, which behave like new ones.
+// Callers must hold h.mu.
+func (h *handler) prune(now time.Time, capacity float64, perSecond float64) {
+	if len(h.buckets) < 1024 {
+		return
+	}
+	for userId, b := range h.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*perSecond >= capacity {
+			delete(h.buckets, userId)
+		}
+	}
+}
+
+func (h *handler) logf(format string, args ...interface{}) {
+	if h.options.ErrorLog == nil {
+		log.Printf(format, args...)
+	} else {
+		h.options.ErrorLog.Printf(format, args...)
+	}
+}
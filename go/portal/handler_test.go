@@ -0,0 +1,140 @@
+package portal_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/portal"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestHandler(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	m := portal.New(client, nil)
+	users := map[string]string{"alice": "team_1", "bob": "team_1", "carol": "team_2"}
+	handler := m.Handler(func(r *http.Request) (*portal.Access, error) {
+		user := r.Header.Get("X-User")
+		if user == "" {
+			return nil, portal.ErrUnauthenticated
+		}
+		team, ok := users[user]
+		if !ok {
+			return nil, fmt.Errorf("unknown user %s: %w", user, portal.ErrForbidden)
+		}
+		return &portal.Access{UserId: user, AppUid: team, AppName: "Team " + team, FeatureFlags: []string{"beta"}}, nil
+	}, &portal.HandlerOptions{CreateApps: true, RateLimit: 2, ErrorLog: log.New(io.Discard, "", 0)})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	request := func(method string, user string) (int, *portal.HandlerResponse) {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return res.StatusCode, nil
+		}
+		var out portal.HandlerResponse
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, &out
+	}
+
+	for _, tc := range []struct {
+		method string
+		user   string
+		status int
+	}{
+		{http.MethodGet, "", http.StatusUnauthorized},
+		{http.MethodGet, "mallory", http.StatusForbidden},
+		{http.MethodDelete, "alice", http.StatusMethodNotAllowed},
+	} {
+		if status, _ := request(tc.method, tc.user); status != tc.status {
+			t.Errorf("%s by %q: expected %d, got %d", tc.method, tc.user, tc.status, status)
+		}
+	}
+
+	status, first := request(http.MethodGet, "alice")
+	if status != http.StatusOK || first.Url == "" {
+		t.Fatalf("unexpected response %d %+v", status, first)
+	}
+	app, err := client.Application.Get(context.Background(), "team_1")
+	if err != nil {
+		t.Fatalf("expected the application to be created: %v", err)
+	}
+	if app.Name != "Team team_1" {
+		t.Errorf("unexpected application %+v", app)
+	}
+	if _, again := request(http.MethodPost, "alice"); again == nil || again.Url != first.Url {
+		t.Error("expected the session of the user to be cached")
+	}
+	if _, other := request(http.MethodGet, "bob"); other == nil || other.Url == first.Url {
+		t.Error("expected sessions not to be shared between users")
+	}
+
+	if status, _ := request(http.MethodGet, "alice"); status != http.StatusTooManyRequests {
+		t.Errorf("expected the user to be rate limited, got %d", status)
+	}
+	if status, _ := request(http.MethodGet, "carol"); status != http.StatusOK {
+		t.Errorf("expected other users not to be rate limited, got %d", status)
+	}
+
+	if err := m.RevokeAll(context.Background(), "team_1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, renewed := request(http.MethodGet, "bob"); renewed == nil || renewed.Url == first.Url {
+		t.Error("expected a new session after the revocation")
+	}
+}
+
+func TestHandlerRevokeById(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "Team", Uid: *svix.NullableString(svix.String("team_1"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := portal.New(client, nil)
+	handler := m.Handler(func(r *http.Request) (*portal.Access, error) {
+		return &portal.Access{UserId: "alice", AppUid: "team_1"}, nil
+	}, &portal.HandlerOptions{RateLimit: -1})
+	url := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		var out portal.HandlerResponse
+		if err := json.NewDecoder(rec.Body).Decode(&out); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("unexpected response %d (%v)", rec.Code, err)
+		}
+		return out.Url
+	}
+
+	first := url()
+	if url() != first {
+		t.Fatal("expected the session to be cached")
+	}
+	if err := m.RevokeAll(ctx, app.Id, 0); err != nil {
+		t.Fatal(err)
+	}
+	if url() == first {
+		t.Error("expected a new session after revoking by the id of the application")
+	}
+}
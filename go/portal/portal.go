@@ -0,0 +1,292 @@
+// Package portal issues application portal sessions.
+//
+// A Manager requests app portal tokens from Svix with the feature flags of
+// each session, and caches them until shortly before they expire, so that
+// pages embedding the portal don't create a token on every load. On a
+// security event (a compromised account, a user removed from a team, ...)
+// RevokeAll expires every token of an application and drops the cached
+// sessions.
+//
+// Handler serves the magic links of the sessions to the users of a web
+// application, given a Resolver mapping their requests to their
+// applications.
+//
+//	m := portal.New(client, nil)
+//	session, err := m.Session(ctx, appId, "beta-dashboard")
+//	// Redirect to, or embed, session.Url.
+//	err = m.RevokeAll(ctx, appId, 0)
+//
+//	http.Handle("/portal-session", m.Handler(resolveUser, nil))
+package portal
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+type Options struct {
+	// How long the tokens issued by Svix stay valid. Defaults to 1 hour,
+	// which is conservative: set it to the lifetime configured for the
+	// environment to cache sessions longer.
+	TokenLifetime time.Duration
+	// How long before their expiry cached sessions are replaced with new
+	// ones, so that a session handed out is still usable for a while.
+	// Defaults to 5 minutes.
+	RefreshBefore time.Duration
+	// The feature flags of every session, in addition to those given to
+	// Session.
+	FeatureFlags []string
+	// How long issuing a token may take. Defaults to 30 seconds.
+	RequestTimeout time.Duration
+}
+
+// Session is an app portal token, with the magic link that logs into the
+// portal with it.
+type Session struct {
+	AppId        string
+	Token        string
+	Url          string
+	FeatureFlags []string
+	IssuedAt     time.Time
+	// When the token is expected to expire, based on Options.TokenLifetime.
+	ExpiresAt time.Time
+}
+
+// Manager issues and caches app portal sessions. It's safe for concurrent
+// use.
+type Manager struct {
+	client  *svix.Svix
+	options Options
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+	pending  map[string]*pendingSession
+	// Incremented by Forget, so that sessions issued concurrently with a
+	// revocation aren't cached.
+	generations map[string]int
+	// The ids of the applications resolved from their uids, so that Forget
+	// drops their sessions given either.
+	appIds map[string]string
+}
+
+type pendingSession struct {
+	done    chan struct{}
+	session *Session
+	err     error
+}
+
+func New(client *svix.Svix, options *Options) *Manager {
+	m := &Manager{
+		client:      client,
+		sessions:    make(map[string]*Session),
+		pending:     make(map[string]*pendingSession),
+		generations: make(map[string]int),
+		appIds:      make(map[string]string),
+	}
+	if options != nil {
+		m.options = *options
+	}
+	if m.options.TokenLifetime <= 0 {
+		m.options.TokenLifetime = time.Hour
+	}
+	if m.options.RefreshBefore <= 0 {
+		m.options.RefreshBefore = 5 * time.Minute
+	}
+	if m.options.RequestTimeout <= 0 {
+		m.options.RequestTimeout = 30 * time.Second
+	}
+	if m.options.RefreshBefore >= m.options.TokenLifetime {
+		m.options.RefreshBefore = m.options.TokenLifetime / 2
+	}
+	return m
+}
+
+// Session returns a session for the application portal of appId with the
+// given feature flags, reusing a cached one unless it's about to expire.
+// Concurrent calls for the same application and flags share one request,
+// which isn't canceled with ctx: callers that give up don't fail the others.
+func (m *Manager) Session(ctx context.Context, appId string, featureFlags ...string) (*Session, error) {
+	return m.session(ctx, appId, "", featureFlags)
+}
+
+// session is Session with the cached sessions partitioned by scope, so that
+// sessions can be cached per user rather than shared.
+func (m *Manager) session(ctx context.Context, appId string, scope string, featureFlags []string) (*Session, error) {
+	flags := m.featureFlags(featureFlags)
+	key := appId + "\x00" + scope + "\x00" + strings.Join(flags, "\x00")
+
+	m.mu.Lock()
+	if s, ok := m.sessions[key]; ok && time.Now().Before(s.ExpiresAt.Add(-m.options.RefreshBefore)) {
+		m.mu.Unlock()
+		return s, nil
+	}
+	p, ok := m.pending[key]
+	if !ok {
+		p = &pendingSession{done: make(chan struct{})}
+		m.pending[key] = p
+		go m.issuePending(detach(ctx), key, p, appId, m.generations[appId], flags)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return p.session, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// issuePending issues the session shared by the callers waiting on p, and
+// caches it unless the application's sessions were forgotten meanwhile.
+func (m *Manager) issuePending(ctx context.Context, key string, p *pendingSession, appId string, generation int, flags []string) {
+	ctx, cancel := context.WithTimeout(ctx, m.options.RequestTimeout)
+	defer cancel()
+	p.session, p.err = m.issue(ctx, appId, flags)
+
+	m.mu.Lock()
+	delete(m.pending, key)
+	if p.err == nil && m.generations[appId] == generation {
+		m.prune()
+		m.sessions[key] = p.session
+	}
+	m.mu.Unlock()
+	close(p.done)
+}
+
+// detachedContext keeps the values of its parent, but not its deadline or
+// cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (m *Manager) issue(ctx context.Context, appId string, flags []string) (*Session, error) {
+	in := &svix.AppPortalAccessIn{}
+	if len(flags) > 0 {
+		in.FeatureFlags = &flags
+	}
+	issuedAt := time.Now()
+	out, err := m.client.Authentication.AppPortalAccess(ctx, appId, in)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AppId:        appId,
+		Token:        out.Token,
+		Url:          out.Url,
+		FeatureFlags: flags,
+		IssuedAt:     issuedAt,
+		ExpiresAt:    issuedAt.Add(m.options.TokenLifetime),
+	}, nil
+}
+
+// featureFlags returns the default and given flags, sorted and without
+// duplicates, so that they can be used in cache keys.
+func (m *Manager) featureFlags(flags []string) []string {
+	seen := make(map[string]bool)
+	var ret []string
+	for _, list := range [][]string{m.options.FeatureFlags, flags} {
+		for _, f := range list {
+			if f != "" && !seen[f] {
+				seen[f] = true
+				ret = append(ret, f)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// prune drops the cached sessions that have expired. Callers must hold m.mu.
+func (m *Manager) prune() {
+	now := time.Now()
+	for key, s := range m.sessions {
+		if !now.Before(s.ExpiresAt) {
+			delete(m.sessions, key)
+		}
+	}
+}
+
+// appId returns the id of the application with uid, resolving it with get
+// the first time.
+func (m *Manager) appId(uid string, get func() (*svix.ApplicationOut, error)) (string, error) {
+	m.mu.Lock()
+	id, ok := m.appIds[uid]
+	m.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+	app, err := get()
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	m.appIds[uid] = app.Id
+	m.mu.Unlock()
+	return app.Id, nil
+}
+
+// forgetAppId drops the id resolved for uid, e.g. because the application
+// was deleted.
+func (m *Manager) forgetAppId(uid string) {
+	m.mu.Lock()
+	delete(m.appIds, uid)
+	m.mu.Unlock()
+}
+
+// RevokeAll expires every token of the application, including those not
+// issued by this manager, and drops its cached sessions. The tokens stop
+// working after grace, which is rounded down to the second; zero expires
+// them immediately.
+//
+// Sessions of other processes sharing the application are revoked by Svix,
+// but stay in their caches: they should be told to call Forget.
+func (m *Manager) RevokeAll(ctx context.Context, appId string, grace time.Duration) error {
+	m.Forget(appId)
+	expiry := int64(grace / time.Second)
+	err := m.client.Authentication.ExpireAll(ctx, appId, &svix.ApplicationTokenExpireIn{
+		Expiry: *svix.NullableInt64(&expiry),
+	})
+	// Sessions issued before the tokens were expired may have been cached
+	// in the meantime.
+	m.Forget(appId)
+	return err
+}
+
+// Forget drops the cached sessions of the application without revoking
+// them, so that the next calls to Session issue new tokens. The sessions
+// served by Handler are dropped given either the id or the uid of the
+// application.
+func (m *Manager) Forget(appId string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := []string{appId}
+	for uid, id := range m.appIds {
+		if uid == appId {
+			names = append(names, id)
+		} else if id == appId {
+			names = append(names, uid)
+		}
+	}
+	for _, name := range names {
+		m.generations[name]++
+		prefix := name + "\x00"
+		for key := range m.sessions {
+			if strings.HasPrefix(key, prefix) {
+				delete(m.sessions, key)
+			}
+		}
+	}
+}
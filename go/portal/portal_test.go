@@ -0,0 +1,186 @@
+package portal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/portal"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestManager(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{Token: "testsk_admin"})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := portal.New(client, &portal.Options{FeatureFlags: []string{"beta"}})
+
+	first, err := m.Session(ctx, app.Id, "export", "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(first.FeatureFlags, ",") != "beta,export" || first.AppId != app.Id {
+		t.Errorf("unexpected session: %+v", first)
+	}
+	again, err := m.Session(ctx, app.Id, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Token != first.Token {
+		t.Error("expected the cached session to be reused")
+	}
+	other, err := m.Session(ctx, app.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Token == first.Token {
+		t.Error("expected other feature flags to get another session")
+	}
+
+	// The magic link logs in with the token once.
+	oneTimeToken := first.Url[strings.Index(first.Url, "#key=")+len("#key="):]
+	exchanged, err := client.Authentication.ExchangeOneTimeToken(ctx, &svix.OneTimeTokenIn{OneTimeToken: oneTimeToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exchanged.Token != first.Token {
+		t.Errorf("unexpected token %q", exchanged.Token)
+	}
+	if _, err := client.Authentication.ExchangeOneTimeToken(ctx, &svix.OneTimeTokenIn{OneTimeToken: oneTimeToken}); err == nil {
+		t.Error("expected the one-time token to be refused the second time")
+	}
+
+	if _, err := client.WithToken(first.Token).Endpoint.List(ctx, app.Id, nil); err != nil {
+		t.Fatalf("expected the token to work: %v", err)
+	}
+	if err := m.RevokeAll(ctx, app.Id, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*portal.Session{first, other} {
+		_, err := client.WithToken(s.Token).Endpoint.List(ctx, app.Id, nil)
+		if !hasStatus(err, http.StatusUnauthorized) {
+			t.Errorf("expected the token to be expired, got %v", err)
+		}
+	}
+	renewed, err := m.Session(ctx, app.Id, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.Token == first.Token {
+		t.Error("expected a new session after the revocation")
+	}
+	if _, err := client.WithToken(renewed.Token).Endpoint.List(ctx, app.Id, nil); err != nil {
+		t.Errorf("expected the new token to work: %v", err)
+	}
+
+	if _, err := m.Session(ctx, "app_missing"); !hasStatus(err, http.StatusNotFound) {
+		t.Errorf("expected a 404, got %v", err)
+	}
+}
+
+func TestManagerRefreshesBeforeExpiry(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := portal.New(client, &portal.Options{TokenLifetime: 300 * time.Millisecond, RefreshBefore: 200 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 8)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := m.Session(ctx, app.Id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			tokens[i] = s.Token
+		}(i)
+	}
+	wg.Wait()
+	for _, token := range tokens {
+		if token != tokens[0] {
+			t.Fatalf("expected concurrent calls to share a session, got %v", tokens)
+		}
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	s, err := m.Session(ctx, app.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Token == tokens[0] {
+		t.Error("expected the session to be replaced before its expiry")
+	}
+}
+
+// slowTransport holds requests until release is closed.
+type slowTransport struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (t *slowTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.started <- struct{}{}
+	<-t.release
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestManagerSharedRequestOutlivesFirstCaller(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+	app, err := srv.Client().Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &slowTransport{started: make(chan struct{}, 1), release: make(chan struct{})}
+	client := svix.New("testsk_portal", &svix.SvixOptions{ServerUrl: srv.URL(), HTTPClient: &http.Client{Transport: transport}})
+	m := portal.New(client, nil)
+
+	firstCtx, cancel := context.WithCancel(ctx)
+	first := make(chan error, 1)
+	go func() {
+		_, err := m.Session(firstCtx, app.Id)
+		first <- err
+	}()
+	<-transport.started
+	second := make(chan *portal.Session, 1)
+	go func() {
+		s, err := m.Session(ctx, app.Id)
+		if err != nil {
+			t.Error(err)
+		}
+		second <- s
+	}()
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the first caller to give up, got %v", err)
+	}
+	close(transport.release)
+	if s := <-second; s == nil || s.Token == "" {
+		t.Errorf("expected the second caller to get a session, got %+v", s)
+	}
+}
+
+func hasStatus(err error, status int) bool {
+	var svixErr *svix.Error
+	return errors.As(err, &svixErr) && svixErr.Status() == status
+}
@@ -0,0 +1,116 @@
+package svix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type (
+	// RawMessageOut is a MessageOut with the payload as received, so that
+	// its numbers keep their precision.
+	RawMessageOut = TypedMessageOut[json.RawMessage]
+	// RawEndpointMessageOut is an EndpointMessageOut with the payload as
+	// received.
+	RawEndpointMessageOut = TypedEndpointMessageOut[json.RawMessage]
+	// ListResponseRawEndpointMessageOut is a ListResponseEndpointMessageOut
+	// with the payloads as received.
+	ListResponseRawEndpointMessageOut = TypedListResponseEndpointMessageOut[json.RawMessage]
+)
+
+// RawMessageIn is a MessageIn with a payload given as encoded JSON, which
+// is sent unchanged.
+type RawMessageIn struct {
+	Channels               []string        `json:"channels,omitempty"`
+	EventId                *string         `json:"eventId,omitempty"`
+	EventType              string          `json:"eventType"`
+	Payload                json.RawMessage `json:"-"`
+	PayloadRetentionPeriod *int64          `json:"payloadRetentionPeriod,omitempty"`
+}
+
+// encode encodes the message, with the payload appended byte for byte
+// rather than compacted by encoding/json.
+func (m *RawMessageIn) encode() ([]byte, error) {
+	payload := bytes.TrimSpace(m.Payload)
+	if len(payload) == 0 || payload[0] != '{' || !json.Valid(payload) {
+		return nil, errors.New("the payload must be a JSON object")
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	body = append(body[:len(body)-1], `,"payload":`...)
+	body = append(body, m.Payload...)
+	return append(body, '}'), nil
+}
+
+// CreateRaw creates a message whose payload is already encoded, sending it
+// unchanged. The payload of the created message is returned as received.
+func (m *Message) CreateRaw(ctx context.Context, appId string, messageIn *RawMessageIn, options *PostOptions) (*RawMessageOut, error) {
+	return createRaw[json.RawMessage](ctx, m, appId, messageIn, options)
+}
+
+func createRawMessage[T any](ctx context.Context, m *Message, appId string, eventType string, payload json.RawMessage, options *CreateMessageOptions) (*TypedMessageOut[T], error) {
+	messageIn := &RawMessageIn{EventType: eventType, Payload: payload}
+	var postOptions *PostOptions
+	if options != nil {
+		messageIn.Channels = options.Channels
+		messageIn.EventId = options.EventId
+		messageIn.PayloadRetentionPeriod = options.PayloadRetentionPeriod
+		postOptions = &PostOptions{IdempotencyKey: options.IdempotencyKey}
+	}
+	return createRaw[T](ctx, m, appId, messageIn, postOptions)
+}
+
+func createRaw[T any](ctx context.Context, m *Message, appId string, messageIn *RawMessageIn, options *PostOptions) (*TypedMessageOut[T], error) {
+	body, err := messageIn.encode()
+	if err != nil {
+		return nil, err
+	}
+	if m.validator != nil {
+		var payload map[string]interface{}
+		if err := decodeJSON(messageIn.Payload, &payload); err != nil {
+			return nil, err
+		}
+		if err := m.validator.validate(ctx, appId, &MessageIn{EventType: messageIn.EventType, Payload: payload}); err != nil {
+			return nil, err
+		}
+	}
+	headers := map[string]string{}
+	if options != nil && options.IdempotencyKey != nil {
+		headers["idempotency-key"] = *options.IdempotencyKey
+	}
+	var ret TypedMessageOut[T]
+	path := "/api/v1/app/" + url.PathEscape(appId) + "/msg/"
+	if err := rawRequest(ctx, m.api, "MessageApiService.V1MessageCreate", http.MethodPost, path, headers, body, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetRaw gets a message, with the payload as received.
+func (m *Message) GetRaw(ctx context.Context, appId string, msgId string) (*RawMessageOut, error) {
+	return getMessage[json.RawMessage](ctx, m, appId, msgId)
+}
+
+// ListAttemptedMessagesRaw is ListAttemptedMessages with the payloads as
+// received.
+func (m *MessageAttempt) ListAttemptedMessagesRaw(ctx context.Context, appId string, endpointId string, options *MessageAttemptListOptions) (*ListResponseRawEndpointMessageOut, error) {
+	return listAttemptedMessages[json.RawMessage](ctx, m, appId, endpointId, options)
+}
+
+// rawRequest sends a request whose body is already encoded with the
+// generated client, and decodes the response into out without losing the
+// precision of its numbers.
+func rawRequest(ctx context.Context, api *openapi.APIClient, operation string, method string, path string, headers map[string]string, body []byte, out interface{}) error {
+	data, res, err := api.CallRaw(ctx, operation, method, path, headers, body)
+	if err != nil {
+		return wrapError(err, res)
+	}
+	return decodeJSON(data, out)
+}
@@ -0,0 +1,160 @@
+package svix_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestCreateRawSendsPayloadUnchanged(t *testing.T) {
+	payload := `{"id": 9223372036854775807, "amounts": [18446744073709551615, -9223372036854775808],
+		"nested": {"z": 1, "a": 2}}`
+	var body []byte
+	client := newOutboxTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		if r.Header.Get("idempotency-key") != "key" {
+			t.Errorf("unexpected idempotency key %q", r.Header.Get("idempotency-key"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"id": "msg_1", "eventType": "invoice.paid", "payload": `+payload+`, "timestamp": "2024-01-02T03:04:05Z"}`)
+	}))
+
+	msg, err := client.Message.CreateRaw(context.Background(), "app_1", &svix.RawMessageIn{
+		EventType: "invoice.paid",
+		EventId:   svix.String("evt_1"),
+		Payload:   json.RawMessage(payload),
+	}, &svix.PostOptions{IdempotencyKey: svix.String("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(body, []byte(`"payload":`+payload)) || !bytes.Contains(body, []byte(`"eventId":"evt_1"`)) {
+		t.Errorf("unexpected request body: %s", body)
+	}
+	if string(msg.Payload) != payload {
+		t.Errorf("unexpected payload: %s", msg.Payload)
+	}
+
+	for _, invalid := range []string{``, `[1]`, `{"a": }`} {
+		_, err := client.Message.CreateRaw(context.Background(), "app_1", &svix.RawMessageIn{EventType: "invoice.paid", Payload: json.RawMessage(invalid)}, nil)
+		if err == nil {
+			t.Errorf("expected %q to be refused", invalid)
+		}
+	}
+}
+
+func TestRawPayloadsKeep64BitIntegers(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/webhook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := client.Message.CreateRaw(ctx, app.Id, &svix.RawMessageIn{
+		EventType: "invoice.paid",
+		Payload:   json.RawMessage(`{"id": 9007199254740993, "cents": -9223372036854775808}`),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.WaitForDeliveries()
+
+	check := func(name string, payload json.RawMessage) {
+		t.Helper()
+		var decoded struct {
+			Id    uint64 `json:"id"`
+			Cents int64  `json:"cents"`
+		}
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if decoded.Id != 9007199254740993 || decoded.Cents != -9223372036854775808 {
+			t.Errorf("%s: unexpected payload %s", name, payload)
+		}
+	}
+	check("CreateRaw", msg.Payload)
+	got, err := client.Message.GetRaw(ctx, app.Id, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("GetRaw", got.Payload)
+	withContent := true
+	list, err := client.MessageAttempt.ListAttemptedMessagesRaw(ctx, app.Id, ep.Id, &svix.MessageAttemptListOptions{WithContent: &withContent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 {
+		t.Fatalf("unexpected messages: %+v", list)
+	}
+	check("ListAttemptedMessagesRaw", list.Data[0].Payload)
+
+	// The generic functions send raw payloads unchanged too.
+	typed, err := svix.CreateMessage(ctx, client, app.Id, "invoice.paid", json.RawMessage(`{"id": 9007199254740993, "cents": 0}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(typed.Payload), "9007199254740993") {
+		t.Errorf("unexpected payload: %s", typed.Payload)
+	}
+}
+
+func TestCreateRawRetries(t *testing.T) {
+	var ids, retries, bodies []string
+	client := newOutboxTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		ids = append(ids, r.Header.Get("svix-req-id"))
+		retries = append(retries, r.Header.Get("svix-retry-count"))
+		if len(ids) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"id": "msg_1", "eventType": "invoice.paid", "payload": {}, "timestamp": "2024-01-02T03:04:05Z"}`)
+	}))
+	if _, err := client.Message.CreateRaw(context.Background(), "app_1", &svix.RawMessageIn{EventType: "invoice.paid", Payload: json.RawMessage(`{}`)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] == "" || ids[1] != ids[0] || ids[2] != ids[0] {
+		t.Errorf("expected the request id to be kept across retries, got %q", ids)
+	}
+	if strings.Join(retries, ",") != ",1,2" {
+		t.Errorf("unexpected retry counts %q", retries)
+	}
+	for _, body := range bodies {
+		if body != bodies[0] || !strings.Contains(body, `"payload":{}`) {
+			t.Errorf("expected every try to send the body, got %q", bodies)
+			break
+		}
+	}
+}
+
+func TestCreateRawStopsRetryingWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := 0
+	client := newOutboxTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	_, err := client.Message.CreateRaw(ctx, "app_1", &svix.RawMessageIn{EventType: "invoice.paid", Payload: json.RawMessage(`{}`)}, nil)
+	if !errors.Is(err, context.Canceled) || requests != 1 {
+		t.Errorf("expected the retries to stop after 1 request, got %d (%v)", requests, err)
+	}
+}
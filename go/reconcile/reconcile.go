@@ -0,0 +1,620 @@
+// Package reconcile manages event types, applications and endpoints
+// declaratively.
+//
+// The desired State, typically kept in version control, is compared with the
+// live configuration to produce a Plan, listing the objects to create,
+// update and (optionally) delete. The plan can be reviewed before it is
+// applied:
+//
+//	state, err := reconcile.LoadFile("svix.yaml")
+//	plan, err := reconcile.NewPlan(ctx, client, state, &reconcile.Options{Prune: true})
+//	fmt.Print(plan)
+//	err = plan.Apply(ctx, client)
+//
+// Objects that aren't part of the state are left alone unless Prune is set.
+// Event types are never removed for good: pruning archives them.
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/internal/ptr"
+)
+
+// The value shown in plans instead of header values, which often hold
+// credentials.
+const redacted = "(redacted)"
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+type Kind string
+
+const (
+	KindEventType   Kind = "event-type"
+	KindApplication Kind = "application"
+	KindEndpoint    Kind = "endpoint"
+)
+
+// Change is a step of a Plan.
+type Change struct {
+	Action Action `json:"action"`
+	Kind   Kind   `json:"kind"`
+	// The event type name, the application uid, or `<app uid>/<endpoint
+	// uid>`. Unmanaged objects without a uid are named by their id.
+	Name string `json:"name"`
+	// The fields that differ, for updates.
+	Fields []FieldChange `json:"fields,omitempty"`
+
+	apply func(ctx context.Context, client *svix.Svix) error
+}
+
+// FieldChange is the change of a field of an object. Unset values are nil.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Plan is the list of changes bringing the live configuration to the
+// desired state, in the order they are applied: event types, applications
+// and endpoints are created or updated first, and deletions follow in
+// reverse order.
+type Plan struct {
+	Changes []*Change `json:"changes"`
+}
+
+type Options struct {
+	// Prune deletes the applications and event types that aren't in the
+	// state, as well as the endpoints of managed applications that aren't.
+	Prune bool
+}
+
+type planner struct {
+	client  *svix.Svix
+	opts    Options
+	changes []*Change
+	// Deletions, applied after the other changes.
+	endpointDeletes  []*Change
+	appDeletes       []*Change
+	eventTypeDeletes []*Change
+}
+
+// NewPlan compares the live configuration with state.
+func NewPlan(ctx context.Context, client *svix.Svix, state *State, options *Options) (*Plan, error) {
+	p := &planner{client: client}
+	if options != nil {
+		p.opts = *options
+	}
+	if err := p.eventTypes(ctx, state.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := p.applications(ctx, state.Applications); err != nil {
+		return nil, err
+	}
+	plan := &Plan{Changes: p.changes}
+	plan.Changes = append(plan.Changes, p.endpointDeletes...)
+	plan.Changes = append(plan.Changes, p.appDeletes...)
+	plan.Changes = append(plan.Changes, p.eventTypeDeletes...)
+	return plan, nil
+}
+
+// Apply applies the changes in order, stopping at the first failure. Only
+// plans returned by NewPlan can be applied.
+func (p *Plan) Apply(ctx context.Context, client *svix.Svix) error {
+	for _, c := range p.Changes {
+		if c.apply == nil {
+			return fmt.Errorf("%s %s %s: the plan wasn't created by NewPlan", c.Action, c.Kind, c.Name)
+		}
+		if err := c.apply(ctx, client); err != nil {
+			return fmt.Errorf("%s %s %s: %w", c.Action, c.Kind, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// String formats the plan for review.
+func (p *Plan) String() string {
+	if len(p.Changes) == 0 {
+		return "No changes.\n"
+	}
+	var b strings.Builder
+	counts := map[Action]int{}
+	for _, c := range p.Changes {
+		counts[c.Action]++
+		symbol := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+		fmt.Fprintf(&b, "%s %s %s\n", symbol, c.Kind, c.Name)
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", f.Field, formatValue(f.Old), formatValue(f.New))
+		}
+	}
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete.\n", counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete])
+	return b.String()
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	if s, ok := v.(string); ok && s == redacted {
+		return s
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if s := []rune(string(raw)); len(s) > 60 {
+		return string(s[:57]) + "..."
+	}
+	return string(raw)
+}
+
+// normalize converts v to its JSON representation, so that values decoded
+// from the state file compare equal to those returned by the API. Empty
+// values are unset.
+func normalize(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	switch x := out.(type) {
+	case map[string]interface{}:
+		if len(x) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			return nil
+		}
+	case string:
+		if x == "" {
+			return nil
+		}
+	case bool:
+		if !x {
+			return nil
+		}
+	}
+	return out
+}
+
+type fieldDiff []FieldChange
+
+func (d *fieldDiff) compare(field string, old, new interface{}) {
+	old, new = normalize(old), normalize(new)
+	if !reflect.DeepEqual(old, new) {
+		*d = append(*d, FieldChange{Field: field, Old: old, New: new})
+	}
+}
+
+func sorted(s []string) []string {
+	s = append([]string(nil), s...)
+	sort.Strings(s)
+	return s
+}
+
+func (p *planner) eventTypes(ctx context.Context, desired []EventType) error {
+	live := map[string]openapi.EventTypeOut{}
+	yes := true
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := p.client.EventType.List(ctx, &svix.EventTypeListOptions{
+			Iterator:        iterator,
+			Limit:           svix.Int32(paginate.PageSize),
+			WithContent:     &yes,
+			IncludeArchived: &yes,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, et := range out.Data {
+			live[et.Name] = et
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, want := range desired {
+		want := want
+		have, ok := live[want.Name]
+		delete(live, want.Name)
+		if !ok {
+			p.changes = append(p.changes, &Change{
+				Action: ActionCreate,
+				Kind:   KindEventType,
+				Name:   want.Name,
+				apply: func(ctx context.Context, client *svix.Svix) error {
+					_, err := client.EventType.Create(ctx, &svix.EventTypeIn{
+						Name:        want.Name,
+						Description: want.Description,
+						Archived:    &want.Archived,
+						FeatureFlag: *ptr.NullableString(want.FeatureFlag),
+						Schemas:     want.Schemas,
+					})
+					return err
+				},
+			})
+			continue
+		}
+		var d fieldDiff
+		d.compare("description", have.Description, want.Description)
+		d.compare("featureFlag", ptr.Value(have.FeatureFlag.Get()), want.FeatureFlag)
+		d.compare("archived", ptr.Value(have.Archived), want.Archived)
+		d.compare("schemas", have.Schemas, want.Schemas)
+		if len(d) == 0 {
+			continue
+		}
+		p.changes = append(p.changes, &Change{
+			Action: ActionUpdate,
+			Kind:   KindEventType,
+			Name:   want.Name,
+			Fields: d,
+			apply: func(ctx context.Context, client *svix.Svix) error {
+				_, err := client.EventType.Update(ctx, want.Name, &svix.EventTypeUpdate{
+					Description: want.Description,
+					Archived:    &want.Archived,
+					FeatureFlag: *ptr.NullableString(want.FeatureFlag),
+					Schemas:     want.Schemas,
+				})
+				return err
+			},
+		})
+	}
+
+	if !p.opts.Prune {
+		return nil
+	}
+	var names []string
+	for name, et := range live {
+		if !ptr.Value(et.Archived) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		name := name
+		p.eventTypeDeletes = append(p.eventTypeDeletes, &Change{
+			Action: ActionDelete,
+			Kind:   KindEventType,
+			Name:   name,
+			apply: func(ctx context.Context, client *svix.Svix) error {
+				return client.EventType.Delete(ctx, name)
+			},
+		})
+	}
+	return nil
+}
+
+func (p *planner) applications(ctx context.Context, desired []Application) error {
+	live := map[string]openapi.ApplicationOut{}
+	var unmanaged []openapi.ApplicationOut
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := p.client.Application.List(ctx, &svix.ApplicationListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, app := range out.Data {
+			if uid := app.Uid.Get(); uid != nil {
+				live[*uid] = app
+			} else {
+				unmanaged = append(unmanaged, app)
+			}
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, want := range desired {
+		want := want
+		have, ok := live[want.Uid]
+		delete(live, want.Uid)
+		if !ok {
+			p.changes = append(p.changes, &Change{
+				Action: ActionCreate,
+				Kind:   KindApplication,
+				Name:   want.Uid,
+				apply: func(ctx context.Context, client *svix.Svix) error {
+					_, err := client.Application.Create(ctx, applicationIn(&want))
+					return err
+				},
+			})
+			// The application doesn't have an id yet, but the API
+			// accepts uids in its place.
+			for _, ep := range want.Endpoints {
+				p.createEndpoint(want.Uid, want.Uid, ep)
+			}
+			continue
+		}
+		var d fieldDiff
+		d.compare("name", have.Name, want.Name)
+		d.compare("metadata", have.Metadata, want.Metadata)
+		d.compare("rateLimit", have.RateLimit.Get(), want.RateLimit)
+		if len(d) != 0 {
+			appId := have.Id
+			p.changes = append(p.changes, &Change{
+				Action: ActionUpdate,
+				Kind:   KindApplication,
+				Name:   want.Uid,
+				Fields: d,
+				apply: func(ctx context.Context, client *svix.Svix) error {
+					_, err := client.Application.Update(ctx, appId, applicationIn(&want))
+					return err
+				},
+			})
+		}
+		if err := p.endpoints(ctx, have.Id, want); err != nil {
+			return fmt.Errorf("application %s: %w", want.Uid, err)
+		}
+	}
+
+	if !p.opts.Prune {
+		return nil
+	}
+	for _, app := range live {
+		unmanaged = append(unmanaged, app)
+	}
+	sort.Slice(unmanaged, func(i, j int) bool { return unmanaged[i].Id < unmanaged[j].Id })
+	for _, app := range unmanaged {
+		appId := app.Id
+		name := appId
+		if uid := app.Uid.Get(); uid != nil {
+			name = *uid
+		}
+		p.appDeletes = append(p.appDeletes, &Change{
+			Action: ActionDelete,
+			Kind:   KindApplication,
+			Name:   name,
+			apply: func(ctx context.Context, client *svix.Svix) error {
+				return client.Application.Delete(ctx, appId)
+			},
+		})
+	}
+	return nil
+}
+
+func applicationIn(app *Application) *svix.ApplicationIn {
+	in := &svix.ApplicationIn{
+		Name:      app.Name,
+		Uid:       *svix.NullableString(&app.Uid),
+		RateLimit: *svix.NullableInt32(app.RateLimit),
+	}
+	if app.Metadata != nil {
+		in.Metadata = &app.Metadata
+	}
+	return in
+}
+
+func (p *planner) endpoints(ctx context.Context, appId string, app Application) error {
+	live := map[string]openapi.EndpointOut{}
+	var unmanaged []openapi.EndpointOut
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := p.client.Endpoint.List(ctx, appId, &svix.EndpointListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, ep := range out.Data {
+			if uid := ep.Uid.Get(); uid != nil {
+				live[*uid] = ep
+			} else {
+				unmanaged = append(unmanaged, ep)
+			}
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, want := range app.Endpoints {
+		want := want
+		have, ok := live[want.Uid]
+		delete(live, want.Uid)
+		if !ok {
+			p.createEndpoint(appId, app.Uid, want)
+			continue
+		}
+
+		var d fieldDiff
+		d.compare("url", have.Url, want.Url)
+		d.compare("description", have.Description, want.Description)
+		d.compare("disabled", ptr.Value(have.Disabled), want.Disabled)
+		d.compare("filterTypes", sorted(have.FilterTypes), sorted(want.FilterTypes))
+		d.compare("channels", sorted(have.Channels), sorted(want.Channels))
+		d.compare("metadata", have.Metadata, want.Metadata)
+		d.compare("rateLimit", have.RateLimit.Get(), want.RateLimit)
+		updateEndpoint := len(d) != 0
+
+		updateHeaders := false
+		if want.Headers != nil {
+			headers, err := p.client.Endpoint.GetHeaders(ctx, appId, have.Id)
+			if err != nil {
+				return fmt.Errorf("endpoint %s: %w", want.Uid, err)
+			}
+			n := len(d)
+			diffHeaders(&d, headers, want.Headers)
+			updateHeaders = len(d) != n
+		}
+
+		updateTransformation := false
+		if want.Transformation != nil {
+			transformation, err := p.client.Endpoint.TransformationGet(ctx, appId, have.Id)
+			if err != nil {
+				return fmt.Errorf("endpoint %s: %w", want.Uid, err)
+			}
+			n := len(d)
+			d.compare("transformation.code", ptr.Value(transformation.Code.Get()), want.Transformation.Code)
+			d.compare("transformation.enabled", ptr.Value(transformation.Enabled), want.Transformation.Enabled)
+			updateTransformation = len(d) != n
+		}
+
+		if len(d) == 0 {
+			continue
+		}
+		endpointId := have.Id
+		p.changes = append(p.changes, &Change{
+			Action: ActionUpdate,
+			Kind:   KindEndpoint,
+			Name:   app.Uid + "/" + want.Uid,
+			Fields: d,
+			apply: func(ctx context.Context, client *svix.Svix) error {
+				if updateEndpoint {
+					if _, err := client.Endpoint.Update(ctx, appId, endpointId, endpointUpdate(&want)); err != nil {
+						return err
+					}
+				}
+				if updateHeaders {
+					if err := client.Endpoint.UpdateHeaders(ctx, appId, endpointId, &svix.EndpointHeadersIn{Headers: want.Headers}); err != nil {
+						return err
+					}
+				}
+				if updateTransformation {
+					return updateTransformationOf(ctx, client, appId, endpointId, want.Transformation)
+				}
+				return nil
+			},
+		})
+	}
+
+	if !p.opts.Prune {
+		return nil
+	}
+	for _, ep := range live {
+		unmanaged = append(unmanaged, ep)
+	}
+	sort.Slice(unmanaged, func(i, j int) bool { return unmanaged[i].Id < unmanaged[j].Id })
+	for _, ep := range unmanaged {
+		endpointId := ep.Id
+		name := endpointId
+		if uid := ep.Uid.Get(); uid != nil {
+			name = *uid
+		}
+		p.endpointDeletes = append(p.endpointDeletes, &Change{
+			Action: ActionDelete,
+			Kind:   KindEndpoint,
+			Name:   app.Uid + "/" + name,
+			apply: func(ctx context.Context, client *svix.Svix) error {
+				return client.Endpoint.Delete(ctx, appId, endpointId)
+			},
+		})
+	}
+	return nil
+}
+
+// diffHeaders compares the headers of an endpoint. The API doesn't return
+// the values of sensitive headers, so those are only compared by name.
+func diffHeaders(d *fieldDiff, have *svix.EndpointHeadersOut, want map[string]string) {
+	live := map[string]*string{}
+	names := map[string]string{}
+	for name, value := range have.Headers {
+		value := value
+		live[strings.ToLower(name)] = &value
+		names[strings.ToLower(name)] = name
+	}
+	for _, name := range have.Sensitive {
+		live[strings.ToLower(name)] = nil
+		names[strings.ToLower(name)] = name
+	}
+
+	var keys []string
+	for name := range want {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		value, ok := live[strings.ToLower(name)]
+		delete(names, strings.ToLower(name))
+		switch {
+		case !ok:
+			*d = append(*d, FieldChange{Field: "headers." + name, New: redacted})
+		case value != nil && *value != want[name]:
+			*d = append(*d, FieldChange{Field: "headers." + name, Old: redacted, New: redacted})
+		}
+	}
+	var removed []string
+	for _, name := range names {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		*d = append(*d, FieldChange{Field: "headers." + name, Old: redacted})
+	}
+}
+
+func (p *planner) createEndpoint(appId string, appUid string, want Endpoint) {
+	p.changes = append(p.changes, &Change{
+		Action: ActionCreate,
+		Kind:   KindEndpoint,
+		Name:   appUid + "/" + want.Uid,
+		apply: func(ctx context.Context, client *svix.Svix) error {
+			in := &svix.EndpointIn{
+				Url:         want.Url,
+				Uid:         *svix.NullableString(&want.Uid),
+				Description: &want.Description,
+				Disabled:    &want.Disabled,
+				FilterTypes: want.FilterTypes,
+				Channels:    want.Channels,
+				RateLimit:   *svix.NullableInt32(want.RateLimit),
+				Secret:      *ptr.NullableString(want.Secret),
+			}
+			if want.Metadata != nil {
+				in.Metadata = &want.Metadata
+			}
+			ep, err := client.Endpoint.Create(ctx, appId, in)
+			if err != nil {
+				return err
+			}
+			if len(want.Headers) != 0 {
+				if err := client.Endpoint.UpdateHeaders(ctx, appId, ep.Id, &svix.EndpointHeadersIn{Headers: want.Headers}); err != nil {
+					return err
+				}
+			}
+			if want.Transformation != nil {
+				return updateTransformationOf(ctx, client, appId, ep.Id, want.Transformation)
+			}
+			return nil
+		},
+	})
+}
+
+func endpointUpdate(ep *Endpoint) *svix.EndpointUpdate {
+	update := &svix.EndpointUpdate{
+		Url:         ep.Url,
+		Uid:         *svix.NullableString(&ep.Uid),
+		Description: &ep.Description,
+		Disabled:    &ep.Disabled,
+		FilterTypes: ep.FilterTypes,
+		Channels:    ep.Channels,
+		RateLimit:   *svix.NullableInt32(ep.RateLimit),
+	}
+	if ep.Metadata != nil {
+		update.Metadata = &ep.Metadata
+	}
+	return update
+}
+
+func updateTransformationOf(ctx context.Context, client *svix.Svix, appId string, endpointId string, t *Transformation) error {
+	return client.Endpoint.TransformatioPartialUpdate(ctx, appId, endpointId, &svix.EndpointTransformationIn{
+		Code:    *svix.NullableString(&t.Code),
+		Enabled: &t.Enabled,
+	})
+}
@@ -0,0 +1,202 @@
+package reconcile_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/reconcile"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+const stateYAML = `
+eventTypes:
+  - name: user.signup
+    description: A user signed up
+    schemas:
+      "1":
+        type: object
+        properties:
+          id: {type: string}
+  - name: user.deleted
+    description: A user was deleted
+applications:
+  - uid: customer-1
+    name: Customer 1
+    rateLimit: 100
+    metadata:
+      tier: gold
+    endpoints:
+      - uid: main
+        url: https://example.com/webhooks
+        filterTypes: [user.signup, user.deleted]
+        channels: [eu]
+        headers:
+          X-Tenant: customer-1
+          Authorization: Bearer secret
+        transformation:
+          code: "function handler(webhook) { return webhook }"
+          enabled: true
+`
+
+func mustLoad(t *testing.T, data string) *reconcile.State {
+	t.Helper()
+	state, err := reconcile.Load(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func plan(t *testing.T, client *svix.Svix, state *reconcile.State, prune bool) *reconcile.Plan {
+	t.Helper()
+	p, err := reconcile.NewPlan(context.Background(), client, state, &reconcile.Options{Prune: prune})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func summary(p *reconcile.Plan) []string {
+	var out []string
+	for _, c := range p.Changes {
+		out = append(out, string(c.Action)+" "+string(c.Kind)+" "+c.Name)
+	}
+	return out
+}
+
+func TestReconcile(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	state := mustLoad(t, stateYAML)
+	p := plan(t, client, state, false)
+	want := []string{
+		"create event-type user.signup",
+		"create event-type user.deleted",
+		"create application customer-1",
+		"create endpoint customer-1/main",
+	}
+	if got := summary(p); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected plan:\n%s", p)
+	}
+	if err := p.Apply(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	// Applying the state again is a no-op, even though the Authorization
+	// header can't be read back.
+	if p := plan(t, client, state, false); len(p.Changes) != 0 {
+		t.Fatalf("expected no changes, got:\n%s", p)
+	}
+
+	// Unmanaged objects are only removed when pruning.
+	unmanaged, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "unmanaged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Endpoint.Create(ctx, "customer-1", &svix.EndpointIn{Url: "https://example.com/other"}); err != nil {
+		t.Fatal(err)
+	}
+	if p := plan(t, client, state, false); len(p.Changes) != 0 {
+		t.Fatalf("expected no changes without pruning, got:\n%s", p)
+	}
+
+	changed := mustLoad(t, strings.NewReplacer(
+		"name: Customer 1", "name: Customer One",
+		"X-Tenant: customer-1", "X-Tenant: customer-one",
+		"filterTypes: [user.signup, user.deleted]", "filterTypes: [user.signup]",
+		"  - name: user.deleted\n    description: A user was deleted\n", "",
+	).Replace(stateYAML))
+	p = plan(t, client, changed, true)
+	want = []string{
+		"update application customer-1",
+		"update endpoint customer-1/main",
+		"delete endpoint customer-1/",
+		"delete application " + unmanaged.Id,
+		"delete event-type user.deleted",
+	}
+	got := summary(p)
+	if len(got) != len(want) {
+		t.Fatalf("unexpected plan:\n%s", p)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Fatalf("unexpected plan:\n%s", p)
+		}
+	}
+	text := p.String()
+	for _, line := range []string{
+		`    name: "Customer 1" -> "Customer One"`,
+		`    filterTypes: ["user.deleted","user.signup"] -> ["user.signup"]`,
+		`    headers.X-Tenant: (redacted) -> (redacted)`,
+		"Plan: 0 to create, 2 to update, 3 to delete.",
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("expected the plan to contain %q:\n%s", line, text)
+		}
+	}
+	if strings.Contains(text, "customer-one") || strings.Contains(text, "Bearer") {
+		t.Errorf("header values leaked in the plan:\n%s", text)
+	}
+	if err := p.Apply(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if p := plan(t, client, changed, true); len(p.Changes) != 0 {
+		t.Fatalf("expected no changes after applying, got:\n%s", p)
+	}
+
+	headers, err := client.Endpoint.GetHeaders(ctx, "customer-1", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers.Headers["X-Tenant"] != "customer-one" || len(headers.Sensitive) != 1 {
+		t.Errorf("unexpected headers: %+v", headers)
+	}
+	et, err := client.EventType.Get(ctx, "user.deleted")
+	if err != nil || et.Archived == nil || !*et.Archived {
+		t.Errorf("expected user.deleted to be archived, got %+v (%v)", et, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "json",
+			data: `{"applications": [{"uid": "a", "name": "A", "endpoints": [{"uid": "e", "url": "https://example.com"}]}]}`,
+		},
+		{
+			name: "unknown field",
+			data: "applications:\n  - uid: a\n    nme: A\n",
+			err:  "field nme not found",
+		},
+		{
+			name: "missing uid",
+			data: "applications:\n  - name: A\n",
+			err:  `application "A" has no uid`,
+		},
+		{
+			name: "duplicate endpoint",
+			data: "applications:\n  - uid: a\n    endpoints:\n      - {uid: e, url: 'https://a'}\n      - {uid: e, url: 'https://b'}\n",
+			err:  `duplicate endpoint "e" in application "a"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := reconcile.Load(strings.NewReader(tc.data))
+			if tc.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package reconcile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// State is the desired configuration of an environment.
+//
+// Event types are identified by their name, applications by their uid, and
+// endpoints by their uid within their application. A state file is written
+// in YAML or JSON:
+//
+//	eventTypes:
+//	  - name: user.signup
+//	    description: A user signed up
+//	    schemas:
+//	      "1":
+//	        type: object
+//	        properties:
+//	          id: {type: string}
+//	applications:
+//	  - uid: customer-1
+//	    name: Customer 1
+//	    rateLimit: 100
+//	    endpoints:
+//	      - uid: main
+//	        url: https://example.com/webhooks
+//	        filterTypes: [user.signup]
+//	        headers:
+//	          X-Tenant: customer-1
+type State struct {
+	EventTypes   []EventType   `yaml:"eventTypes"`
+	Applications []Application `yaml:"applications"`
+}
+
+type EventType struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	FeatureFlag string `yaml:"featureFlag"`
+	Archived    bool   `yaml:"archived"`
+	// The JSON schemas of the payload, by version.
+	Schemas map[string]map[string]interface{} `yaml:"schemas"`
+}
+
+type Application struct {
+	Uid       string            `yaml:"uid"`
+	Name      string            `yaml:"name"`
+	Metadata  map[string]string `yaml:"metadata"`
+	RateLimit *int32            `yaml:"rateLimit"`
+	Endpoints []Endpoint        `yaml:"endpoints"`
+}
+
+type Endpoint struct {
+	Uid         string            `yaml:"uid"`
+	Url         string            `yaml:"url"`
+	Description string            `yaml:"description"`
+	Disabled    bool              `yaml:"disabled"`
+	FilterTypes []string          `yaml:"filterTypes"`
+	Channels    []string          `yaml:"channels"`
+	Metadata    map[string]string `yaml:"metadata"`
+	RateLimit   *int32            `yaml:"rateLimit"`
+	// The signing secret of the endpoint, only used when it is created.
+	Secret string `yaml:"secret"`
+	// The headers sent to the endpoint. When nil, the headers aren't
+	// managed. The values of sensitive headers (such as Authorization) can't
+	// be read back from the API, so they are only compared by name.
+	Headers map[string]string `yaml:"headers"`
+	// The transformation of the endpoint. When nil, it isn't managed.
+	Transformation *Transformation `yaml:"transformation"`
+}
+
+type Transformation struct {
+	Code    string `yaml:"code"`
+	Enabled bool   `yaml:"enabled"`
+}
+
+// Load parses and validates a state written in YAML or JSON.
+func Load(r io.Reader) (*State, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var state State
+	if err := dec.Decode(&state); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// LoadFile parses and validates the state file at path.
+func LoadFile(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	state, err := Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Validate checks that every object has an identifier, and that identifiers
+// are unique.
+func (s *State) Validate() error {
+	eventTypes := map[string]bool{}
+	for _, et := range s.EventTypes {
+		if et.Name == "" {
+			return errors.New("event type without a name")
+		}
+		if eventTypes[et.Name] {
+			return fmt.Errorf("duplicate event type %q", et.Name)
+		}
+		eventTypes[et.Name] = true
+	}
+	apps := map[string]bool{}
+	for _, app := range s.Applications {
+		if app.Uid == "" {
+			return fmt.Errorf("application %q has no uid", app.Name)
+		}
+		if apps[app.Uid] {
+			return fmt.Errorf("duplicate application %q", app.Uid)
+		}
+		apps[app.Uid] = true
+		endpoints := map[string]bool{}
+		for _, ep := range app.Endpoints {
+			if ep.Uid == "" {
+				return fmt.Errorf("endpoint %q of application %q has no uid", ep.Url, app.Uid)
+			}
+			if endpoints[ep.Uid] {
+				return fmt.Errorf("duplicate endpoint %q in application %q", ep.Uid, app.Uid)
+			}
+			if ep.Url == "" {
+				return fmt.Errorf("endpoint %q of application %q has no url", ep.Uid, app.Uid)
+			}
+			endpoints[ep.Uid] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+module github.com/svix/svix-webhooks/go/relay
+
+go 1.20
+
+require golang.org/x/net v0.7.0
@@ -0,0 +1,275 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Listener is a connection to a relay Server, receiving the requests sent to
+// its public URL.
+type Listener struct {
+	ws    *websocket.Conn
+	token string
+	key   string
+	url   string
+
+	sendMu sync.Mutex
+}
+
+// Dial connects to the relay server at relayURL (ws:// or wss://). If token
+// is not empty, the listener takes over the public URL of a previous
+// session with that token, which requires the key of that session.
+func Dial(ctx context.Context, relayURL string, token string, key string) (*Listener, error) {
+	u, err := url.Parse(relayURL)
+	if err != nil {
+		return nil, err
+	}
+	origin := &url.URL{Host: u.Host}
+	switch u.Scheme {
+	case "ws":
+		origin.Scheme = "http"
+	case "wss":
+		origin.Scheme = "https"
+	default:
+		return nil, fmt.Errorf("relay: unsupported scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/listen"
+	config, err := websocket.NewConfig(u.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "wss" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+	var dialer net.Dialer
+	var conn net.Conn
+	if u.Scheme == "wss" {
+		tlsDialer := &tls.Dialer{NetDialer: &dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The handshake doesn't take a context, so give up on it when ctx is
+	// cancelled by closing the connection.
+	stop := closeOnDone(ctx, conn)
+	var started *startedData
+	ws, err := websocket.NewClient(config, conn)
+	if err == nil {
+		started, err = start(ws, token, key)
+	}
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Listener{ws: ws, token: started.Token, key: started.Key, url: started.URL}, nil
+}
+
+// start sends the start message and waits for the server's answer.
+func start(ws *websocket.Conn, token string, key string) (*startedData, error) {
+	msg, err := newMessage("start", startData{Version: Version, Token: token, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if err := websocket.JSON.Send(ws, msg); err != nil {
+		return nil, err
+	}
+	if err := websocket.JSON.Receive(ws, msg); err != nil {
+		return nil, err
+	}
+	if err := checkError(msg); err != nil {
+		return nil, err
+	}
+	var started startedData
+	if msg.Type != "started" || json.Unmarshal(msg.Data, &started) != nil {
+		return nil, fmt.Errorf("relay: unexpected %q message", msg.Type)
+	}
+	return &started, nil
+}
+
+func checkError(msg *message) error {
+	if msg.Type != "error" {
+		return nil
+	}
+	var data struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(msg.Data, &data)
+	return fmt.Errorf("relay: %s", data.Message)
+}
+
+// closeOnDone closes conn if ctx is done before the returned function is
+// called. The function reports whether conn is still usable.
+func closeOnDone(ctx context.Context, conn io.Closer) func() bool {
+	done := make(chan struct{})
+	closed := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			closed <- true
+		case <-done:
+			closed <- false
+		}
+	}()
+	return func() bool {
+		close(done)
+		return !<-closed
+	}
+}
+
+// Token identifies the session; pass it to Dial along with Key to keep the
+// same URL.
+func (l *Listener) Token() string {
+	return l.token
+}
+
+// Key is the secret needed to resume the session. Unlike the token, it
+// isn't part of the URL and must be kept private.
+func (l *Listener) Key() string {
+	return l.key
+}
+
+// URL is the public URL whose requests are relayed to the listener.
+func (l *Listener) URL() string {
+	return l.url
+}
+
+// Close disconnects from the server.
+func (l *Listener) Close() error {
+	return l.ws.Close()
+}
+
+// Serve handles the relayed requests with handler until ctx is done or the
+// connection is lost. It closes the listener before returning.
+func (l *Listener) Serve(ctx context.Context, handler http.Handler) error {
+	stop := closeOnDone(ctx, l.ws)
+	defer stop()
+	defer l.ws.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		var msg message
+		if err := websocket.JSON.Receive(l.ws, &msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				return ErrClosed
+			}
+			return err
+		}
+		if err := checkError(&msg); err != nil {
+			return err
+		}
+		if msg.Type != "event" {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := handle(ctx, handler, &event)
+			l.sendMu.Lock()
+			defer l.sendMu.Unlock()
+			if msg, err := newMessage("response", res); err == nil {
+				websocket.JSON.Send(l.ws, msg)
+			}
+		}()
+	}
+}
+
+func handle(ctx context.Context, handler http.Handler, event *Event) *Response {
+	target := event.Path
+	if event.Query != "" {
+		target += "?" + event.Query
+	}
+	req, err := http.NewRequestWithContext(ctx, event.Method, target, bytes.NewReader(event.Body))
+	if err != nil {
+		return &Response{Id: event.Id, Status: http.StatusBadRequest, Body: []byte(err.Error())}
+	}
+	req.Header = event.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.ContentLength = int64(len(event.Body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return &Response{
+		Id:     event.Id,
+		Status: rec.Code,
+		Header: rec.Header(),
+		Body:   rec.Body.Bytes(),
+	}
+}
+
+// Forward returns a handler sending the requests to target, keeping their
+// path relative to it, and returning a 502 when target can't be reached.
+func Forward(target *url.URL, client *http.Client) http.Handler {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := *target
+		if r.URL.Path != "/" {
+			u.Path = strings.TrimSuffix(u.Path, "/") + r.URL.Path
+		}
+		if r.URL.RawQuery != "" {
+			if u.RawQuery != "" {
+				u.RawQuery += "&"
+			}
+			u.RawQuery += r.URL.RawQuery
+		}
+		req, err := http.NewRequestWithContext(r.Context(), r.Method, u.String(), r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		req.Header = cleanHeader(r.Header)
+		req.ContentLength = r.ContentLength
+		res, err := client.Do(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		for name, values := range cleanHeader(res.Header) {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(res.StatusCode)
+		io.Copy(w, res.Body)
+	})
+}
+
+// ErrClosed is returned by Serve when the server closed the connection.
+var ErrClosed = errors.New("relay: connection closed")
@@ -0,0 +1,99 @@
+// Package relay forwards webhooks sent to a public URL to a process that
+// can't be reached from the internet, such as a development server on a
+// laptop.
+//
+// A Server gives each connected Listener a public URL of the form
+// `<public url>/e/<token>`. Requests to that URL are sent to the listener
+// over a websocket, handled locally, and the local response is returned to
+// the caller:
+//
+//	l, err := relay.Dial(ctx, "wss://relay.example.com", "", "")
+//	fmt.Println("Webhooks sent to", l.URL(), "are forwarded to localhost:3000")
+//	err = l.Serve(ctx, relay.Forward(localURL, nil))
+//
+// The protocol is a sequence of JSON messages `{"type": ..., "data": ...}`.
+// The listener first sends a "start" message, optionally asking for the
+// token of a previous session so that its URL stays the same, and the
+// server answers with "started". The server then sends an "event" message
+// for every request, which the listener answers with a "response" message
+// with the same id.
+//
+// The token is part of the public URL, so it isn't enough to resume a
+// session: "started" also carries a secret key, which the listener must
+// present along with the token to take the session over.
+package relay
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Version is the version of the protocol spoken by this package.
+const Version = 1
+
+type message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type startData struct {
+	Version int    `json:"version"`
+	Token   string `json:"token,omitempty"`
+	Key     string `json:"key,omitempty"`
+}
+
+type startedData struct {
+	Token string `json:"token"`
+	Key   string `json:"key"`
+	URL   string `json:"url"`
+}
+
+// Event is a request received on a listener's public URL.
+type Event struct {
+	Id     string `json:"id"`
+	Method string `json:"method"`
+	// The path following the public URL, and the query string.
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Response is the local response to an Event.
+type Response struct {
+	Id     string      `json:"id"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+func newMessage(typ string, data interface{}) (*message, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &message{Type: typ, Data: raw}, nil
+}
+
+// Headers that only make sense for a single hop and aren't relayed.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Content-Length",
+}
+
+func cleanHeader(h http.Header) http.Header {
+	h = h.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+	return h
+}
@@ -0,0 +1,168 @@
+package relay_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/svix/svix-webhooks/go/relay"
+	"golang.org/x/net/websocket"
+)
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestRelay(t *testing.T) {
+	relaySrv := httptest.NewServer(&relay.Server{})
+	defer relaySrv.Close()
+
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.RequestURI())
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("got " + string(body) + " with " + r.Header.Get("Svix-Id")))
+	}))
+	defer local.Close()
+	target, _ := url.Parse(local.URL + "/hooks")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l, err := relay.Dial(ctx, wsURL(relaySrv), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(l.URL(), relaySrv.URL+"/e/") || l.Key() == "" || strings.Contains(l.URL(), l.Key()) {
+		t.Fatalf("unexpected public URL %s", l.URL())
+	}
+	served := make(chan error, 1)
+	go func() { served <- l.Serve(ctx, relay.Forward(target, nil)) }()
+
+	req, _ := http.NewRequest(http.MethodPost, l.URL()+"/svix?a=1", strings.NewReader(`{"x":1}`))
+	req.Header.Set("Svix-Id", "msg_1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusAccepted || string(body) != `got {"x":1} with msg_1` || res.Header.Get("X-Path") != "/hooks/svix?a=1" {
+		t.Errorf("unexpected response %d %q %v", res.StatusCode, body, res.Header)
+	}
+
+	// Knowing the URL isn't enough to take the session over.
+	for _, key := range []string{"", "wrongkey"} {
+		if _, err := relay.Dial(ctx, wsURL(relaySrv), l.Token(), key); err == nil {
+			t.Errorf("expected resuming with the key %q to fail", key)
+		}
+	}
+	res, err = http.Post(l.URL(), "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		t.Errorf("expected the listener to keep its session, got %d", res.StatusCode)
+	}
+
+	// Reconnecting with the token and key keeps the URL and takes over the
+	// session.
+	cancel()
+	if err := <-served; err != context.Canceled {
+		t.Errorf("Serve returned %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	l2, err := relay.Dial(ctx, wsURL(relaySrv), l.Token(), l.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l2.Close()
+	if l2.URL() != l.URL() || l2.Key() != l.Key() {
+		t.Errorf("expected the URL %s to be kept, got %s", l.URL(), l2.URL())
+	}
+
+	res, err = http.Post(relaySrv.URL+"/e/unknowntoken", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 without a listener, got %d", res.StatusCode)
+	}
+}
+
+func TestRelaySecret(t *testing.T) {
+	secret := []byte("relay secret")
+	relaySrv := httptest.NewServer(&relay.Server{Secret: secret})
+	ctx := context.Background()
+	l, err := relay.Dial(ctx, wsURL(relaySrv), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	// Tokens that weren't issued by the server can't be claimed.
+	if _, err := relay.Dial(ctx, wsURL(relaySrv), "madeuptoken", "madeupkey"); err == nil {
+		t.Error("expected claiming an unknown token to fail")
+	}
+	relaySrv.Close()
+
+	// Sessions can be resumed on a server restarted with the same secret.
+	relaySrv = httptest.NewServer(&relay.Server{Secret: secret})
+	defer relaySrv.Close()
+	l2, err := relay.Dial(ctx, wsURL(relaySrv), l.Token(), l.Key())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l2.Close()
+	if l2.Token() != l.Token() {
+		t.Errorf("expected the token %s to be kept, got %s", l.Token(), l2.Token())
+	}
+}
+
+func TestRelayInvalidStatus(t *testing.T) {
+	relaySrv := httptest.NewServer(&relay.Server{})
+	defer relaySrv.Close()
+
+	// A listener speaking the protocol directly, answering with a status
+	// net/http can't write.
+	ws, err := websocket.Dial(wsURL(relaySrv)+"/listen", "", relaySrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err := websocket.JSON.Send(ws, map[string]interface{}{"type": "start", "data": map[string]int{"version": relay.Version}}); err != nil {
+		t.Fatal(err)
+	}
+	var started struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	if err := websocket.JSON.Receive(ws, &started); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		var event struct {
+			Data relay.Event `json:"data"`
+		}
+		if err := websocket.JSON.Receive(ws, &event); err != nil {
+			return
+		}
+		websocket.JSON.Send(ws, map[string]interface{}{"type": "response", "data": relay.Response{Id: event.Data.Id, Status: 0}})
+	}()
+
+	res, err := http.Post(started.Data.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected a 502 for an invalid status, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,309 @@
+package relay
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// The largest request body relayed to a listener.
+const maxBodySize = 1 << 20
+
+// Server is the public side of the relay. Listeners connect to `/listen`,
+// and requests to `/e/<token>` are relayed to the listener holding token.
+//
+// The key of a session is an HMAC of its token, so the server keeps no
+// state for disconnected sessions, and a token can only be used by whoever
+// the server gave its key to.
+type Server struct {
+	// Base URL of the public URLs handed to listeners. Defaults to the
+	// scheme and host the listener connected to.
+	PublicURL string
+	// How long to wait for a listener's response before failing the request
+	// with a 504. Defaults to 30 seconds.
+	Timeout time.Duration
+	// The secret the keys of the sessions are derived from. Defaults to
+	// random bytes, in which case sessions can't be resumed once the server
+	// restarts.
+	Secret []byte
+
+	mu        sync.Mutex
+	listeners map[string]*serverConn
+	secret    []byte
+}
+
+type serverConn struct {
+	ws     *websocket.Conn
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan *Response
+	closed  bool
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/listen":
+		// Listeners aren't browsers, so the origin isn't checked.
+		ws := websocket.Server{
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler:   func(ws *websocket.Conn) { s.serveListener(ws, r) },
+		}
+		ws.ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/e/"):
+		s.relay(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveListener(ws *websocket.Conn, r *http.Request) {
+	defer ws.Close()
+	var msg message
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		return
+	}
+	var start startData
+	if msg.Type != "start" || json.Unmarshal(msg.Data, &start) != nil {
+		sendError(ws, "expected a start message")
+		return
+	}
+	if start.Version != Version {
+		sendError(ws, fmt.Sprintf("unsupported protocol version %d", start.Version))
+		return
+	}
+	token := start.Token
+	if token == "" {
+		token = newToken()
+	} else if !validToken(token) {
+		sendError(ws, "invalid token")
+		return
+	} else if start.Key == "" {
+		sendError(ws, "a key is required to resume a session")
+		return
+	}
+
+	conn := &serverConn{ws: ws, pending: make(map[string]chan *Response)}
+	s.mu.Lock()
+	if s.listeners == nil {
+		s.listeners = make(map[string]*serverConn)
+	}
+	key := s.key(token)
+	if start.Token != "" && subtle.ConstantTimeCompare([]byte(key), []byte(start.Key)) != 1 {
+		s.mu.Unlock()
+		sendError(ws, "invalid key for this token")
+		return
+	}
+	// A listener reconnecting with its token replaces its previous session.
+	previous := s.listeners[token]
+	s.listeners[token] = conn
+	s.mu.Unlock()
+	if previous != nil {
+		previous.ws.Close()
+	}
+	defer func() {
+		s.mu.Lock()
+		if s.listeners[token] == conn {
+			delete(s.listeners, token)
+		}
+		s.mu.Unlock()
+		conn.close()
+	}()
+
+	started, _ := newMessage("started", startedData{Token: token, Key: key, URL: s.publicURL(r) + "/e/" + token})
+	if err := conn.send(started); err != nil {
+		return
+	}
+	for {
+		var msg message
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+		if msg.Type != "response" {
+			continue
+		}
+		var res Response
+		if err := json.Unmarshal(msg.Data, &res); err != nil {
+			continue
+		}
+		conn.mu.Lock()
+		ch := conn.pending[res.Id]
+		delete(conn.pending, res.Id)
+		conn.mu.Unlock()
+		if ch != nil {
+			ch <- &res
+		}
+	}
+}
+
+// key returns the key of the session with token. s.mu must be held.
+func (s *Server) key(token string) string {
+	if s.secret == nil {
+		s.secret = s.Secret
+		if len(s.secret) == 0 {
+			s.secret = make([]byte, 32)
+			if _, err := rand.Read(s.secret); err != nil {
+				panic(err)
+			}
+		}
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) publicURL(r *http.Request) string {
+	if s.PublicURL != "" {
+		return strings.TrimSuffix(s.PublicURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func (s *Server) relay(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/e/")
+	token, path := rest, "/"
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		token, path = rest[:i], rest[i:]
+	}
+	s.mu.Lock()
+	conn := s.listeners[token]
+	s.mu.Unlock()
+	if conn == nil {
+		http.Error(w, "no listener is connected for this URL", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event := &Event{
+		Id:     newToken(),
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.RawQuery,
+		Header: cleanHeader(r.Header),
+		Body:   body,
+	}
+	ch := conn.register(event.Id)
+	if ch == nil {
+		http.Error(w, "the listener disconnected", http.StatusBadGateway)
+		return
+	}
+	msg, err := newMessage("event", event)
+	if err == nil {
+		err = conn.send(msg)
+	}
+	if err != nil {
+		conn.unregister(event.Id)
+		http.Error(w, "the listener disconnected", http.StatusBadGateway)
+		return
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			http.Error(w, "the listener disconnected", http.StatusBadGateway)
+			return
+		}
+		// WriteHeader panics on statuses outside 100-999, and informational
+		// ones aren't a response.
+		if res.Status < 200 || res.Status > 999 {
+			http.Error(w, fmt.Sprintf("the listener responded with the invalid status %d", res.Status), http.StatusBadGateway)
+			return
+		}
+		for name, values := range cleanHeader(res.Header) {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(res.Status)
+		w.Write(res.Body)
+	case <-timer.C:
+		conn.unregister(event.Id)
+		http.Error(w, "the listener didn't respond in time", http.StatusGatewayTimeout)
+	case <-r.Context().Done():
+		conn.unregister(event.Id)
+	}
+}
+
+func (c *serverConn) send(msg *message) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return websocket.JSON.Send(c.ws, msg)
+}
+
+// register returns the channel receiving the response to the event id, or
+// nil if the connection is closed.
+func (c *serverConn) register(id string) chan *Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	ch := make(chan *Response, 1)
+	c.pending[id] = ch
+	return ch
+}
+
+func (c *serverConn) unregister(id string) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// close fails the requests waiting for a response.
+func (c *serverConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+func sendError(ws *websocket.Conn, text string) {
+	msg, _ := newMessage("error", map[string]string{"message": text})
+	websocket.JSON.Send(ws, msg)
+}
+
+func newToken() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func validToken(token string) bool {
+	if len(token) < 8 || len(token) > 64 {
+		return false
+	}
+	for _, c := range token {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
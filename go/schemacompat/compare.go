@@ -0,0 +1,441 @@
+package schemacompat
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/svix/svix-webhooks/go/internal/jsonvalue"
+)
+
+// comparer walks an existing and a proposed schema side by side, recording
+// their differences.
+type comparer struct {
+	oldRoot, newRoot interface{}
+	// The pairs of subschemas already compared, to stop at recursive
+	// references.
+	visited map[[2]string]bool
+	changes []Change
+}
+
+func (c *comparer) add(kind Kind, path string, compatibility Compatibility, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{
+		Kind:          kind,
+		Path:          path,
+		Message:       fmt.Sprintf(format, args...),
+		Compatibility: compatibility,
+	})
+}
+
+func (c *comparer) compare(oldValue, newValue interface{}, path string) error {
+	if oldValue == nil && newValue == nil {
+		// Missing on both sides, such as the items of an object.
+		return nil
+	}
+	oldValue, oldRef, err := resolve(c.oldRoot, oldValue)
+	if err != nil {
+		return fmt.Errorf("existing schema at %s: %w", displayPath(path), err)
+	}
+	newValue, newRef, err := resolve(c.newRoot, newValue)
+	if err != nil {
+		return fmt.Errorf("proposed schema at %s: %w", displayPath(path), err)
+	}
+	if oldRef != "" || newRef != "" {
+		// Subschemas that aren't references can only be reached through
+		// their path, which is unique.
+		key := [2]string{"#" + oldRef, "#" + newRef}
+		if oldRef == "" {
+			key[0] = path
+		}
+		if newRef == "" {
+			key[1] = path
+		}
+		if c.visited[key] {
+			return nil
+		}
+		c.visited[key] = true
+	}
+
+	before, oldNever, err := asSchema(oldValue)
+	if err != nil {
+		return fmt.Errorf("existing schema at %s: %w", displayPath(path), err)
+	}
+	after, newNever, err := asSchema(newValue)
+	if err != nil {
+		return fmt.Errorf("proposed schema at %s: %w", displayPath(path), err)
+	}
+	if oldNever || newNever {
+		if newNever && !oldNever {
+			c.add(ConstraintTightened, path, Forward, "no value is allowed anymore")
+		} else if oldNever && !newNever {
+			c.add(ConstraintLoosened, path, Backward, "values are allowed again")
+		}
+		return nil
+	}
+
+	c.compareTypes(before, after, path)
+	c.compareValues(before, after, path)
+	c.compareBounds(before, after, path)
+	for _, keyword := range []string{
+		"multipleOf", "pattern", "format", "uniqueItems", "contains", "additionalItems",
+		"propertyNames", "patternProperties", "dependencies", "if", "then", "else", "not",
+	} {
+		c.compareExact(before, after, path, keyword)
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if err := c.compareList(before, after, path, keyword); err != nil {
+			return err
+		}
+	}
+	if err := c.compareItems(before, after, path); err != nil {
+		return err
+	}
+	return c.compareProperties(before, after, path)
+}
+
+func (c *comparer) compareTypes(before, after map[string]interface{}, path string) {
+	oldTypes, newTypes := types(before), types(after)
+	widened, narrowed := subset(oldTypes, newTypes), subset(newTypes, oldTypes)
+	switch {
+	case widened && narrowed:
+	case narrowed:
+		c.add(TypeNarrowed, path, Forward, "type narrowed from %s to %s", describeTypes(oldTypes), describeTypes(newTypes))
+	case widened:
+		c.add(TypeWidened, path, Backward, "type widened from %s to %s", describeTypes(oldTypes), describeTypes(newTypes))
+	default:
+		c.add(TypeChanged, path, Breaking, "type changed from %s to %s", describeTypes(oldTypes), describeTypes(newTypes))
+	}
+}
+
+// compareValues compares the enum and const keywords, which both restrict
+// the allowed values.
+func (c *comparer) compareValues(before, after map[string]interface{}, path string) {
+	oldValues, newValues := values(before), values(after)
+	switch {
+	case oldValues == nil && newValues == nil:
+	case oldValues == nil:
+		c.add(ConstraintTightened, path, Forward, "values restricted to %s", jsonvalue.DescribeList(newValues))
+	case newValues == nil:
+		c.add(ConstraintLoosened, path, Backward, "values no longer restricted to %s", jsonvalue.DescribeList(oldValues))
+	default:
+		for _, v := range oldValues {
+			if !contains(newValues, v) {
+				c.add(EnumValueRemoved, path, Forward, "enum value %s removed", jsonvalue.Describe(v))
+			}
+		}
+		for _, v := range newValues {
+			if !contains(oldValues, v) {
+				c.add(EnumValueAdded, path, Backward, "enum value %s added", jsonvalue.Describe(v))
+			}
+		}
+	}
+}
+
+var (
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+func (c *comparer) compareBounds(before, after map[string]interface{}, path string) {
+	compare := func(keyword string, lower bool) {
+		oldBound, inOld := jsonvalue.Rat(before[keyword])
+		newBound, inNew := jsonvalue.Rat(after[keyword])
+		switch {
+		case !inOld && !inNew:
+		case !inOld:
+			c.add(ConstraintTightened, path, Forward, "%s of %s added", keyword, jsonvalue.RatString(newBound))
+		case !inNew:
+			c.add(ConstraintLoosened, path, Backward, "%s of %s removed", keyword, jsonvalue.RatString(oldBound))
+		default:
+			cmp := newBound.Cmp(oldBound)
+			if cmp == 0 {
+				return
+			}
+			verb := "raised"
+			if cmp < 0 {
+				verb = "lowered"
+			}
+			if (cmp > 0) == lower {
+				c.add(ConstraintTightened, path, Forward, "%s %s from %s to %s", keyword, verb, jsonvalue.RatString(oldBound), jsonvalue.RatString(newBound))
+			} else {
+				c.add(ConstraintLoosened, path, Backward, "%s %s from %s to %s", keyword, verb, jsonvalue.RatString(oldBound), jsonvalue.RatString(newBound))
+			}
+		}
+	}
+	for _, keyword := range lowerBounds {
+		compare(keyword, true)
+	}
+	for _, keyword := range upperBounds {
+		compare(keyword, false)
+	}
+}
+
+// compareExact compares a keyword whose changes can't be classified beyond
+// being added or removed.
+func (c *comparer) compareExact(before, after map[string]interface{}, path string, keyword string) {
+	oldValue, inOld := before[keyword]
+	newValue, inNew := after[keyword]
+	if keyword == "uniqueItems" {
+		inOld = oldValue == true
+		inNew = newValue == true
+	}
+	switch {
+	case !inOld && !inNew:
+	case !inOld:
+		c.add(ConstraintTightened, path, Forward, "%s added", keyword)
+	case !inNew:
+		c.add(ConstraintLoosened, path, Backward, "%s removed", keyword)
+	case !jsonvalue.Equal(oldValue, newValue):
+		c.add(SchemaChanged, path, Breaking, "%s changed", keyword)
+	}
+}
+
+// compareList compares allOf, anyOf or oneOf, whose subschemas are compared
+// one by one when there are as many.
+func (c *comparer) compareList(before, after map[string]interface{}, path string, keyword string) error {
+	oldList, inOld := before[keyword].([]interface{})
+	newList, inNew := after[keyword].([]interface{})
+	switch {
+	case !inOld && !inNew:
+	case !inOld:
+		c.add(ConstraintTightened, path, Forward, "%s added", keyword)
+	case !inNew:
+		c.add(ConstraintLoosened, path, Backward, "%s removed", keyword)
+	case len(oldList) != len(newList):
+		c.add(SchemaChanged, path, Breaking, "%s changed from %d to %d subschemas", keyword, len(oldList), len(newList))
+	default:
+		for i := range oldList {
+			if err := c.compare(oldList[i], newList[i], path+"/"+keyword+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *comparer) compareItems(before, after map[string]interface{}, path string) error {
+	oldList, oldIsList := before["items"].([]interface{})
+	newList, newIsList := after["items"].([]interface{})
+	switch {
+	case oldIsList && newIsList && len(oldList) == len(newList):
+		for i := range oldList {
+			if err := c.compare(oldList[i], newList[i], path+"/items/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	case oldIsList || newIsList:
+		if !jsonvalue.Equal(before["items"], after["items"]) {
+			c.add(SchemaChanged, path, Breaking, "items changed")
+		}
+	default:
+		return c.compare(before["items"], after["items"], path+"/items")
+	}
+	return nil
+}
+
+func (c *comparer) compareProperties(before, after map[string]interface{}, path string) error {
+	oldProps, _ := before["properties"].(map[string]interface{})
+	newProps, _ := after["properties"].(map[string]interface{})
+	oldRequired, newRequired := stringSet(before["required"]), stringSet(after["required"])
+	oldClosed, newClosed := before["additionalProperties"] == false, after["additionalProperties"] == false
+
+	names := map[string]bool{}
+	for _, set := range []map[string]bool{oldRequired, newRequired} {
+		for name := range set {
+			names[name] = true
+		}
+	}
+	for _, props := range []map[string]interface{}{oldProps, newProps} {
+		for name := range props {
+			names[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		propPath := path + "/properties/" + jsonvalue.EscapePointer(name)
+		oldProp, inOld := oldProps[name]
+		newProp, inNew := newProps[name]
+		switch {
+		case inNew && !inOld:
+			if newRequired[name] {
+				c.add(PropertyAdded, propPath, newCompatibility(false, !oldClosed), "required property %q added", name)
+			} else {
+				c.add(PropertyAdded, propPath, newCompatibility(true, !oldClosed), "optional property %q added", name)
+			}
+			continue
+		case inOld && !inNew:
+			message := fmt.Sprintf("property %q removed", name)
+			if oldRequired[name] {
+				message += " (it was required)"
+			}
+			// The payloads of the proposed schema lack the property, which
+			// the existing one only accepts if it was optional.
+			c.add(PropertyRemoved, propPath, newCompatibility(!newClosed, !oldRequired[name]), "%s", message)
+			continue
+		case inOld && inNew:
+			if err := c.compare(oldProp, newProp, propPath); err != nil {
+				return err
+			}
+		}
+		if newRequired[name] && !oldRequired[name] {
+			c.add(RequiredAdded, propPath, Forward, "property %q is now required", name)
+		} else if oldRequired[name] && !newRequired[name] {
+			c.add(RequiredRemoved, propPath, Backward, "property %q is no longer required", name)
+		}
+	}
+
+	switch {
+	case !oldClosed && newClosed:
+		c.add(ConstraintTightened, path, Forward, "additional properties are no longer allowed")
+	case oldClosed && !newClosed:
+		c.add(ConstraintLoosened, path, Backward, "additional properties are now allowed")
+	case !oldClosed && !newClosed:
+		return c.compare(before["additionalProperties"], after["additionalProperties"], path+"/additionalProperties")
+	}
+	return nil
+}
+
+// resolve follows the $ref of a schema, if any, returning the referenced
+// schema and the JSON pointer of the last reference followed.
+func resolve(root interface{}, schema interface{}) (interface{}, string, error) {
+	pointer := ""
+	for i := 0; ; i++ {
+		m, ok := schema.(map[string]interface{})
+		if !ok {
+			return schema, pointer, nil
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return schema, pointer, nil
+		}
+		if i == 32 {
+			return nil, "", fmt.Errorf("too many nested references")
+		}
+		if !strings.HasPrefix(ref, "#") {
+			return nil, "", fmt.Errorf("unsupported reference %q: only references inside the schema are supported", ref)
+		}
+		var err error
+		pointer, err = url.PathUnescape(ref[1:])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid reference %q: %w", ref, err)
+		}
+		schema, err = jsonvalue.Lookup(root, pointer)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid reference %q: %w", ref, err)
+		}
+	}
+}
+
+// asSchema returns the keywords of a schema, or never if it is the false
+// schema. The true schema and a missing schema have no keywords.
+func asSchema(v interface{}) (keywords map[string]interface{}, never bool, err error) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]interface{}{}, false, nil
+	case bool:
+		return map[string]interface{}{}, !v, nil
+	case map[string]interface{}:
+		return v, false, nil
+	}
+	return nil, false, fmt.Errorf("a schema must be an object or a boolean")
+}
+
+// types returns the types allowed by a schema, or nil if any type is.
+func types(schema map[string]interface{}) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// subset reports whether the types of a are all allowed by b.
+func subset(a, b []string) bool {
+	if b == nil {
+		return true
+	}
+	if a == nil {
+		return false
+	}
+	for _, t := range a {
+		if !containsString(b, t) && !(t == "integer" && containsString(b, "number")) {
+			return false
+		}
+	}
+	return true
+}
+
+func describeTypes(types []string) string {
+	if types == nil {
+		return "any"
+	}
+	return strings.Join(types, " or ")
+}
+
+// values returns the values allowed by the enum or const keywords, or nil
+// if they are both missing.
+func values(schema map[string]interface{}) []interface{} {
+	var allowed []interface{}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		allowed = enum
+	}
+	if v, ok := schema["const"]; ok {
+		if allowed == nil || contains(allowed, v) {
+			allowed = []interface{}{v}
+		} else {
+			allowed = []interface{}{}
+		}
+	}
+	return allowed
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if jsonvalue.Equal(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func stringSet(v interface{}) map[string]bool {
+	set := map[string]bool{}
+	list, _ := v.([]interface{})
+	for _, s := range list {
+		if s, ok := s.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
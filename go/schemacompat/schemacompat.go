@@ -0,0 +1,204 @@
+// Package schemacompat checks whether a change to the JSON schema of an
+// event type can break the applications consuming it.
+//
+// Compare lists the differences between an existing schema and a proposed
+// one, such as added or removed properties, narrowed types or removed enum
+// values, and classifies each of them:
+//
+//   - a change is backward compatible when the payloads valid against the
+//     existing schema are still valid against the proposed one, so that
+//     messages sent before the change, when resent or replayed, still match
+//     the new schema;
+//   - it is forward compatible when the payloads valid against the proposed
+//     schema were already valid against the existing one, so that consumers
+//     written against the existing schema can handle the new messages.
+//
+// Consumers usually upgrade after the producer, so changes to webhook
+// payloads should at least be forward compatible.
+//
+// Properties that a schema doesn't describe are assumed to be absent from
+// its payloads: adding an optional property is compatible both ways unless
+// the existing schema forbids additional properties. Likewise, removing an
+// optional property is compatible both ways unless the proposed schema
+// forbids additional properties, while removing a required one is at most
+// backward compatible. Changes to keywords whose effect can't be compared,
+// such as a new pattern or a different anyOf, are reported as breaking.
+//
+// Both schemas must be valid JSON Schema draft 7 (see package jsonschema),
+// and their references must be JSON pointers inside the schema
+// (`#/definitions/...`).
+package schemacompat
+
+import (
+	"fmt"
+	"strings"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/jsonvalue"
+	"github.com/svix/svix-webhooks/go/jsonschema"
+)
+
+// Compatibility is the compatibility of a change, or of a set of changes.
+type Compatibility string
+
+const (
+	// Compatible both ways.
+	Full Compatibility = "full"
+	// Existing payloads are valid against the proposed schema.
+	Backward Compatibility = "backward"
+	// Proposed payloads are valid against the existing schema.
+	Forward Compatibility = "forward"
+	// Compatible neither way.
+	Breaking Compatibility = "breaking"
+)
+
+// ParseCompatibility parses full, backward, forward or breaking.
+func ParseCompatibility(s string) (Compatibility, error) {
+	switch c := Compatibility(s); c {
+	case Full, Backward, Forward, Breaking:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid compatibility %q: expected full, backward, forward or breaking", s)
+}
+
+// Satisfies reports whether c is at least the required compatibility.
+// Any compatibility satisfies Breaking.
+func (c Compatibility) Satisfies(required Compatibility) bool {
+	switch required {
+	case Full:
+		return c == Full
+	case Backward, Forward:
+		return c == Full || c == required
+	}
+	return true
+}
+
+func newCompatibility(backward bool, forward bool) Compatibility {
+	switch {
+	case backward && forward:
+		return Full
+	case backward:
+		return Backward
+	case forward:
+		return Forward
+	}
+	return Breaking
+}
+
+func (c Compatibility) backward() bool {
+	return c == Full || c == Backward
+}
+
+func (c Compatibility) forward() bool {
+	return c == Full || c == Forward
+}
+
+// Kind is the kind of a change.
+type Kind string
+
+const (
+	PropertyAdded       Kind = "property-added"
+	PropertyRemoved     Kind = "property-removed"
+	RequiredAdded       Kind = "required-added"
+	RequiredRemoved     Kind = "required-removed"
+	TypeNarrowed        Kind = "type-narrowed"
+	TypeWidened         Kind = "type-widened"
+	TypeChanged         Kind = "type-changed"
+	EnumValueAdded      Kind = "enum-value-added"
+	EnumValueRemoved    Kind = "enum-value-removed"
+	ConstraintTightened Kind = "constraint-tightened"
+	ConstraintLoosened  Kind = "constraint-loosened"
+	// A change whose compatibility can't be determined.
+	SchemaChanged Kind = "schema-changed"
+)
+
+// Change is a difference between two schemas.
+type Change struct {
+	Kind Kind `json:"kind"`
+	// The JSON pointer of the changed subschema, with references inlined,
+	// such as /properties/lines/items/properties/quantity.
+	Path          string        `json:"path"`
+	Message       string        `json:"message"`
+	Compatibility Compatibility `json:"compatibility"`
+}
+
+func (c Change) String() string {
+	path := c.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, c.Message, c.Compatibility)
+}
+
+// Report lists the changes between two schemas.
+type Report struct {
+	Changes []Change `json:"changes"`
+	// The compatibility of all the changes together: Full when there are
+	// none.
+	Compatibility Compatibility `json:"compatibility"`
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Compatibility: %s\n", r.Compatibility)
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "  %s\n", c)
+	}
+	return b.String()
+}
+
+// Compare compares an existing schema with a proposed one, given as decoded
+// JSON values (a map[string]interface{} or a bool). A nil schema accepts
+// any payload, like an event type without a schema.
+func Compare(existing interface{}, proposed interface{}) (*Report, error) {
+	if existing == nil {
+		existing = true
+	}
+	if proposed == nil {
+		proposed = true
+	}
+	oldDoc, err := jsonvalue.Normalize(existing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid existing schema: %w", err)
+	}
+	newDoc, err := jsonvalue.Normalize(proposed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposed schema: %w", err)
+	}
+	if _, err := jsonschema.Compile(oldDoc); err != nil {
+		return nil, fmt.Errorf("invalid existing schema: %w", err)
+	}
+	if _, err := jsonschema.Compile(newDoc); err != nil {
+		return nil, fmt.Errorf("invalid proposed schema: %w", err)
+	}
+	c := &comparer{oldRoot: oldDoc, newRoot: newDoc, visited: map[[2]string]bool{}}
+	if err := c.compare(oldDoc, newDoc, ""); err != nil {
+		return nil, err
+	}
+	report := &Report{Changes: c.changes, Compatibility: Full}
+	backward, forward := true, true
+	for _, change := range c.changes {
+		backward = backward && change.Compatibility.backward()
+		forward = forward && change.Compatibility.forward()
+	}
+	report.Compatibility = newCompatibility(backward, forward)
+	return report, nil
+}
+
+// CompareEventType compares the schema of an existing event type, in the
+// given version or in its latest version if version is empty, with a
+// proposed schema.
+func CompareEventType(existing *svix.EventTypeOut, version string, proposed map[string]interface{}) (*Report, error) {
+	if version == "" {
+		version = svix.LatestSchemaVersion(existing.Schemas)
+	}
+	var schema interface{}
+	if s, ok := existing.Schemas[version]; ok {
+		schema = s
+	}
+	var proposedSchema interface{}
+	if proposed != nil {
+		proposedSchema = proposed
+	}
+	return Compare(schema, proposedSchema)
+}
@@ -0,0 +1,213 @@
+package schemacompat_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/schemacompat"
+)
+
+func decode(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		name          string
+		existing      string
+		proposed      string
+		changes       []string
+		compatibility schemacompat.Compatibility
+	}{
+		{
+			name:          "unchanged",
+			existing:      `{"type": "object", "properties": {"id": {"type": "string"}}, "title": "Invoice"}`,
+			proposed:      `{"type": "object", "properties": {"id": {"type": "string"}}, "title": "Paid invoice"}`,
+			compatibility: schemacompat.Full,
+		},
+		{
+			name:          "optional property added",
+			existing:      `{"properties": {"id": {"type": "string"}}}`,
+			proposed:      `{"properties": {"id": {"type": "string"}, "note": {"type": "string"}}}`,
+			changes:       []string{`/properties/note: optional property "note" added (full)`},
+			compatibility: schemacompat.Full,
+		},
+		{
+			name:          "property added to a closed object",
+			existing:      `{"properties": {"id": {}}, "additionalProperties": false}`,
+			proposed:      `{"properties": {"id": {}, "note": {}}, "required": ["note"], "additionalProperties": false}`,
+			changes:       []string{`/properties/note: required property "note" added (breaking)`},
+			compatibility: schemacompat.Breaking,
+		},
+		{
+			name:     "property removed",
+			existing: `{"properties": {"id": {}, "note": {}}, "required": ["id", "note"]}`,
+			proposed: `{"properties": {"id": {}}, "required": ["id"]}`,
+			changes: []string{
+				`/properties/note: property "note" removed (it was required) (backward)`,
+			},
+			compatibility: schemacompat.Backward,
+		},
+		{
+			name:          "optional property removed",
+			existing:      `{"properties": {"id": {}, "note": {}}, "required": ["id"]}`,
+			proposed:      `{"properties": {"id": {}}, "required": ["id"]}`,
+			changes:       []string{`/properties/note: property "note" removed (full)`},
+			compatibility: schemacompat.Full,
+		},
+		{
+			name:     "optional property removed from a closed object",
+			existing: `{"properties": {"id": {}, "note": {}}}`,
+			proposed: `{"properties": {"id": {}}, "additionalProperties": false}`,
+			changes: []string{
+				`/properties/note: property "note" removed (forward)`,
+				"(root): additional properties are no longer allowed (forward)",
+			},
+			compatibility: schemacompat.Forward,
+		},
+		{
+			name:     "required",
+			existing: `{"properties": {"a": {}, "b": {}}, "required": ["a"]}`,
+			proposed: `{"properties": {"a": {}, "b": {}}, "required": ["b"]}`,
+			changes: []string{
+				`/properties/a: property "a" is no longer required (backward)`,
+				`/properties/b: property "b" is now required (forward)`,
+			},
+			compatibility: schemacompat.Breaking,
+		},
+		{
+			name:     "types",
+			existing: `{"properties": {"a": {"type": ["string", "null"]}, "b": {"type": "integer"}, "c": {"type": "string"}, "d": {"type": "integer"}}}`,
+			proposed: `{"properties": {"a": {"type": "string"}, "b": {"type": "number"}, "c": {"type": "boolean"}, "d": {"type": ["number", "integer"]}}}`,
+			changes: []string{
+				"/properties/a: type narrowed from string or null to string (forward)",
+				"/properties/b: type widened from integer to number (backward)",
+				"/properties/c: type changed from string to boolean (breaking)",
+				"/properties/d: type widened from integer to number or integer (backward)",
+			},
+			compatibility: schemacompat.Breaking,
+		},
+		{
+			name:     "enum",
+			existing: `{"properties": {"status": {"enum": ["paid", "void", 1]}}}`,
+			proposed: `{"properties": {"status": {"enum": ["paid", 1.0, "refunded"]}}}`,
+			changes: []string{
+				`/properties/status: enum value "void" removed (forward)`,
+				`/properties/status: enum value "refunded" added (backward)`,
+			},
+			compatibility: schemacompat.Breaking,
+		},
+		{
+			name:          "enum removed",
+			existing:      `{"properties": {"status": {"type": "string", "const": "paid"}}}`,
+			proposed:      `{"properties": {"status": {"type": "string"}}}`,
+			changes:       []string{`/properties/status: values no longer restricted to "paid" (backward)`},
+			compatibility: schemacompat.Backward,
+		},
+		{
+			name:     "constraints",
+			existing: `{"minLength": 1, "maxLength": 10, "pattern": "^a"}`,
+			proposed: `{"minLength": 2, "maxLength": 20, "pattern": "^b", "format": "email"}`,
+			changes: []string{
+				"(root): minLength raised from 1 to 2 (forward)",
+				"(root): maxLength raised from 10 to 20 (backward)",
+				"(root): pattern changed (breaking)",
+				"(root): format added (forward)",
+			},
+			compatibility: schemacompat.Breaking,
+		},
+		{
+			name:          "additional properties",
+			existing:      `{"properties": {"id": {}}}`,
+			proposed:      `{"properties": {"id": {}}, "additionalProperties": false}`,
+			changes:       []string{"(root): additional properties are no longer allowed (forward)"},
+			compatibility: schemacompat.Forward,
+		},
+		{
+			name:     "references",
+			existing: `{"definitions": {"line": {"properties": {"quantity": {"type": "integer"}, "next": {"$ref": "#/definitions/line"}}}}, "properties": {"lines": {"items": {"$ref": "#/definitions/line"}}}}`,
+			proposed: `{"$defs": {"item": {"properties": {"quantity": {"type": "integer", "minimum": 1}, "next": {"$ref": "#/$defs/item"}}}}, "properties": {"lines": {"items": {"$ref": "#/$defs/item"}}}}`,
+			changes: []string{
+				"/properties/lines/items/properties/quantity: minimum of 1 added (forward)",
+			},
+			compatibility: schemacompat.Forward,
+		},
+		{
+			name:          "schema added",
+			existing:      `true`,
+			proposed:      `{"type": "object"}`,
+			changes:       []string{"(root): type narrowed from any to object (forward)"},
+			compatibility: schemacompat.Forward,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var existing, proposed interface{}
+			json.Unmarshal([]byte(tc.existing), &existing)
+			json.Unmarshal([]byte(tc.proposed), &proposed)
+			report, err := schemacompat.Compare(existing, proposed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			changes := make([]string, len(report.Changes))
+			for i, c := range report.Changes {
+				changes[i] = c.String()
+			}
+			if strings.Join(changes, "\n") != strings.Join(tc.changes, "\n") {
+				t.Errorf("expected changes:\n%s\ngot:\n%s", strings.Join(tc.changes, "\n"), strings.Join(changes, "\n"))
+			}
+			if report.Compatibility != tc.compatibility {
+				t.Errorf("expected %s, got %s", tc.compatibility, report.Compatibility)
+			}
+		})
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	for _, schema := range []string{
+		`{"$ref": "https://example.com/schema.json"}`,
+		`{"properties": {"a": {"$ref": "#/definitions/missing"}}}`,
+		`{"properties": {"a": 1}}`,
+	} {
+		if _, err := schemacompat.Compare(map[string]interface{}{}, decode(t, schema)); err == nil {
+			t.Errorf("expected %s to be refused", schema)
+		}
+	}
+}
+
+func TestCompareEventType(t *testing.T) {
+	existing := &svix.EventTypeOut{
+		Name: "invoice.paid",
+		Schemas: map[string]map[string]interface{}{
+			"1":  {"type": "object"},
+			"2":  {"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "string"}}},
+			"10": {"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "string"}}, "required": []interface{}{"id"}},
+		},
+	}
+	proposed := decode(t, `{"type": "object", "properties": {"id": {"type": "string"}}}`)
+
+	report, err := schemacompat.CompareEventType(existing, "", proposed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Compatibility != schemacompat.Backward || len(report.Changes) != 1 || report.Changes[0].Kind != schemacompat.RequiredRemoved {
+		t.Errorf("unexpected report against the latest version: %s", report)
+	}
+	report, err = schemacompat.CompareEventType(existing, "2", proposed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Compatibility != schemacompat.Full || len(report.Changes) != 0 {
+		t.Errorf("unexpected report against version 2: %s", report)
+	}
+	if !schemacompat.Full.Satisfies(schemacompat.Forward) || schemacompat.Backward.Satisfies(schemacompat.Forward) || !schemacompat.Breaking.Satisfies(schemacompat.Breaking) {
+		t.Error("unexpected Satisfies results")
+	}
+}
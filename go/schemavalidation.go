@@ -0,0 +1,225 @@
+package svix
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+	"github.com/svix/svix-webhooks/go/jsonschema"
+)
+
+// SchemaValidation configures the validation of message payloads against
+// the JSON schemas of their event type before they are sent, set with
+// SvixOptions.SchemaValidation.
+//
+// The schemas are listed with EventType.List and cached. Listing runs in
+// the background, independently of the context of the messages waiting for
+// it, and expired schemas keep being used until it completes. When the
+// schemas can't be listed, the last ones listed keep being used, and they
+// aren't listed again for 30 seconds, or CacheTTL if shorter. Event types
+// without a schema, or with a schema that isn't valid JSON Schema draft 7,
+// aren't validated; the latter are reported to OnSchemaError.
+//
+// An Outbox drops the messages rejected with a *PayloadValidationError, as
+// sending them again would fail the same way.
+type SchemaValidation struct {
+	// Reject invalid payloads: Message.Create returns a
+	// *PayloadValidationError instead of sending the message. It also fails
+	// if the schemas have never been listed successfully. Otherwise invalid
+	// payloads are only reported to OnInvalid, and sent.
+	Strict bool
+	// Called with every invalid payload.
+	OnInvalid func(err *PayloadValidationError)
+	// Called when the schema of an event type can't be compiled, every
+	// time the schemas are listed. Payloads of the event type aren't
+	// validated.
+	OnSchemaError func(eventType string, version string, err error)
+	// The schema version to validate against. Defaults to the latest version
+	// of each event type.
+	SchemaVersion string
+	// How long schemas are cached before being listed again. Defaults to 5
+	// minutes.
+	CacheTTL time.Duration
+	// How long listing the schemas may take. Defaults to 30 seconds.
+	FetchTimeout time.Duration
+}
+
+// PayloadValidationError reports a message payload that doesn't match the
+// schema of its event type.
+type PayloadValidationError struct {
+	AppId         string
+	EventType     string
+	SchemaVersion string
+	// The reasons the payload is invalid, with the JSON pointer of each
+	// invalid value.
+	Errors []*jsonschema.Error
+}
+
+func (e *PayloadValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("payload doesn't match the schema of %s (version %s): %s", e.EventType, e.SchemaVersion, strings.Join(msgs, "; "))
+}
+
+type schemaValidator struct {
+	eventTypes *EventType
+	opts       SchemaValidation
+
+	mu        sync.Mutex
+	schemas   map[string]*versionedSchema
+	fetchedAt time.Time
+	// The error of the last listing, if it failed. The schemas aren't
+	// listed again until failedAt + schemaFetchBackoff.
+	fetchErr error
+	failedAt time.Time
+	// Closed when the listing in progress, if any, completes.
+	fetching chan struct{}
+}
+
+const schemaFetchBackoff = 30 * time.Second
+
+type versionedSchema struct {
+	version string
+	schema  *jsonschema.Schema
+}
+
+func newSchemaValidator(eventTypes *EventType, options SchemaValidation) *schemaValidator {
+	if options.CacheTTL <= 0 {
+		options.CacheTTL = 5 * time.Minute
+	}
+	if options.FetchTimeout <= 0 {
+		options.FetchTimeout = 30 * time.Second
+	}
+	return &schemaValidator{eventTypes: eventTypes, opts: options}
+}
+
+// validate returns an error if the message must not be sent.
+func (v *schemaValidator) validate(ctx context.Context, appId string, messageIn *MessageIn) error {
+	schema, err := v.schema(ctx, messageIn.EventType)
+	if err != nil {
+		if v.opts.Strict {
+			return fmt.Errorf("listing the event type schemas: %w", err)
+		}
+		return nil
+	}
+	if schema == nil {
+		return nil
+	}
+	err = schema.schema.Validate(messageIn.Payload)
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	invalid := &PayloadValidationError{
+		AppId:         appId,
+		EventType:     messageIn.EventType,
+		SchemaVersion: schema.version,
+		Errors:        validationErr.Errors,
+	}
+	if v.opts.OnInvalid != nil {
+		v.opts.OnInvalid(invalid)
+	}
+	if v.opts.Strict {
+		return invalid
+	}
+	return nil
+}
+
+// schema returns the schema of an event type, nil if it has none. It only
+// waits for the schemas to be listed when none have been listed yet.
+func (v *schemaValidator) schema(ctx context.Context, eventType string) (*versionedSchema, error) {
+	v.mu.Lock()
+	backoff := schemaFetchBackoff
+	if v.opts.CacheTTL < backoff {
+		backoff = v.opts.CacheTTL
+	}
+	fresh := v.schemas != nil && time.Since(v.fetchedAt) < v.opts.CacheTTL
+	failed := v.fetchErr != nil && time.Since(v.failedAt) < backoff
+	if !fresh && !failed && v.fetching == nil {
+		v.fetching = make(chan struct{})
+		go v.fetchInBackground(v.fetching)
+	}
+	if v.schemas != nil || v.fetching == nil {
+		defer v.mu.Unlock()
+		return v.lookup(eventType)
+	}
+	done := v.fetching
+	v.mu.Unlock()
+
+	select {
+	case <-done:
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		return v.lookup(eventType)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// lookup returns the schema of an event type from the cached schemas, or
+// the error of the last listing if there are none. Callers must hold v.mu.
+func (v *schemaValidator) lookup(eventType string) (*versionedSchema, error) {
+	if v.schemas == nil {
+		return nil, v.fetchErr
+	}
+	return v.schemas[eventType], nil
+}
+
+// fetchInBackground lists the schemas, independently of the messages
+// waiting for them, caches them and closes done.
+func (v *schemaValidator) fetchInBackground(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), v.opts.FetchTimeout)
+	defer cancel()
+	schemas, err := v.fetch(ctx)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err != nil {
+		v.fetchErr, v.failedAt = err, time.Now()
+	} else {
+		v.schemas, v.fetchedAt, v.fetchErr = schemas, time.Now(), nil
+	}
+	v.fetching = nil
+	close(done)
+}
+
+func (v *schemaValidator) fetch(ctx context.Context) (map[string]*versionedSchema, error) {
+	schemas := map[string]*versionedSchema{}
+	withContent := true
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := v.eventTypes.List(ctx, &EventTypeListOptions{Iterator: iterator, Limit: Int32(paginate.PageSize), WithContent: &withContent})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, et := range out.Data {
+			version := v.opts.SchemaVersion
+			if version == "" {
+				version = LatestSchemaVersion(et.Schemas)
+			}
+			raw, ok := et.Schemas[version]
+			if !ok {
+				continue
+			}
+			schema, err := jsonschema.Compile(raw)
+			if err != nil {
+				if v.opts.OnSchemaError != nil {
+					v.opts.OnSchemaError(et.Name, version, err)
+				}
+				continue
+			}
+			schemas[et.Name] = &versionedSchema{version: version, schema: schema}
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
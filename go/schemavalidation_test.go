@@ -0,0 +1,233 @@
+package svix_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/jsonschema"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func newValidatingClient(t *testing.T, validation *svix.SchemaValidation) (*svix.Svix, string) {
+	t.Helper()
+	srv := svixtest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+	setup := srv.Client()
+	app, err := setup.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, et := range []*svix.EventTypeIn{
+		{Name: "invoice.paid", Description: "An invoice was paid", Schemas: map[string]map[string]interface{}{
+			"1": {"type": "object", "required": []interface{}{"id"}},
+			"2": {
+				"type":     "object",
+				"required": []interface{}{"id", "amount"},
+				"properties": map[string]interface{}{
+					"amount": map[string]interface{}{"type": "integer", "minimum": 0},
+				},
+			},
+		}},
+		{Name: "user.signup", Description: "A user signed up"},
+	} {
+		if _, err := setup.EventType.Create(ctx, et); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return svix.New("testsk_svixtest", &svix.SvixOptions{ServerUrl: srv.URL(), SchemaValidation: validation}), app.Id
+}
+
+func TestSchemaValidationStrictOutbox(t *testing.T) {
+	client, appId := newValidatingClient(t, &svix.SchemaValidation{Strict: true})
+	var dropped []*svix.OutboxEntry
+	outbox := svix.NewOutbox(client, svix.NewMemoryOutboxStore(), &svix.OutboxOptions{
+		OnFailure: func(entry *svix.OutboxEntry, err error) { dropped = append(dropped, entry) },
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := outbox.Enqueue(ctx, appId, &svix.MessageIn{EventType: "invoice.paid", Payload: map[string]interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(dropped) != 1 || dropped[0].Attempts != 1 {
+		t.Errorf("expected the invalid payload to be dropped without retrying, got %+v", dropped)
+	}
+}
+
+func TestSchemaValidationStrict(t *testing.T) {
+	client, appId := newValidatingClient(t, &svix.SchemaValidation{Strict: true})
+	ctx := context.Background()
+
+	_, err := client.Message.Create(ctx, appId, &svix.MessageIn{
+		EventType: "invoice.paid",
+		Payload:   map[string]interface{}{"amount": -5},
+	})
+	var invalid *svix.PayloadValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	expected := []jsonschema.Error{
+		{InstancePath: "", KeywordPath: "/required", Message: `missing required property "id"`},
+		{InstancePath: "/amount", KeywordPath: "/properties/amount/minimum", Message: "must be at least 0"},
+	}
+	if invalid.AppId != appId || invalid.EventType != "invoice.paid" || invalid.SchemaVersion != "2" || len(invalid.Errors) != len(expected) {
+		t.Fatalf("unexpected error: %+v", invalid)
+	}
+	for i, e := range invalid.Errors {
+		if *e != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], *e)
+		}
+	}
+
+	if _, err := client.Message.Create(ctx, appId, &svix.MessageIn{
+		EventType: "invoice.paid",
+		Payload:   map[string]interface{}{"id": "in_1", "amount": 5},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Event types without a schema aren't validated.
+	if _, err := client.Message.Create(ctx, appId, &svix.MessageIn{
+		EventType: "user.signup",
+		Payload:   map[string]interface{}{"anything": true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchemaValidationWarn(t *testing.T) {
+	var reported []*svix.PayloadValidationError
+	client, appId := newValidatingClient(t, &svix.SchemaValidation{
+		SchemaVersion: "1",
+		OnInvalid: func(err *svix.PayloadValidationError) {
+			reported = append(reported, err)
+		},
+	})
+	ctx := context.Background()
+
+	msg, err := client.Message.Create(ctx, appId, &svix.MessageIn{
+		EventType: "invoice.paid",
+		Payload:   map[string]interface{}{"amount": -5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Id == "" {
+		t.Error("expected the message to be sent")
+	}
+	if len(reported) != 1 || reported[0].SchemaVersion != "1" || len(reported[0].Errors) != 1 {
+		t.Fatalf("unexpected reports: %v", reported)
+	}
+	if reported[0].Error() != `payload doesn't match the schema of invoice.paid (version 1): (root): missing required property "id"` {
+		t.Errorf("unexpected message: %s", reported[0])
+	}
+}
+
+func TestSchemaValidationFetchBackoff(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	app, err := srv.Client().Application.Create(context.Background(), &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/event-type") {
+			listed.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer api.Close()
+	serverUrl, _ := url.Parse(api.URL)
+	client := svix.New("testsk_svixtest", &svix.SvixOptions{ServerUrl: serverUrl, SchemaValidation: &svix.SchemaValidation{}})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Message.Create(context.Background(), app.Id, &svix.MessageIn{
+			EventType: "invoice.paid",
+			Payload:   map[string]interface{}{},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := listed.Load(); n != 1 {
+		t.Errorf("expected the schemas to be listed once after a failure, got %d", n)
+	}
+}
+
+func TestSchemaValidationServesStaleSchemas(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	ctx := context.Background()
+	app, err := srv.Client().Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, et := range []*svix.EventTypeIn{
+		{Name: "invoice.paid", Description: "paid", Schemas: map[string]map[string]interface{}{"1": {"required": []interface{}{"id"}}}},
+		{Name: "invoice.voided", Description: "voided", Schemas: map[string]map[string]interface{}{"1": {"type": "float"}}},
+	} {
+		if _, err := srv.Client().EventType.Create(ctx, et); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var failing atomic.Bool
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() && strings.HasPrefix(r.URL.Path, "/api/v1/event-type") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer api.Close()
+	serverUrl, _ := url.Parse(api.URL)
+	var mu sync.Mutex
+	var schemaErrors []string
+	client := svix.New("testsk_svixtest", &svix.SvixOptions{ServerUrl: serverUrl, SchemaValidation: &svix.SchemaValidation{
+		Strict:   true,
+		CacheTTL: time.Millisecond,
+		OnSchemaError: func(eventType string, version string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			schemaErrors = append(schemaErrors, eventType+"@"+version)
+		},
+	}})
+	invalid := &svix.MessageIn{EventType: "invoice.paid", Payload: map[string]interface{}{}}
+
+	// A cancelled message doesn't fail the ones after it.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.Message.Create(cancelled, app.Id, invalid); err == nil {
+		t.Fatal("expected the cancelled message to fail")
+	}
+	var validationErr *svix.PayloadValidationError
+	if _, err := client.Message.Create(ctx, app.Id, invalid); !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	mu.Lock()
+	if len(schemaErrors) == 0 || schemaErrors[0] != "invoice.voided@1" {
+		t.Errorf("unexpected schema errors %q", schemaErrors)
+	}
+	mu.Unlock()
+
+	// The expired schemas are still used when they can't be listed again.
+	failing.Store(true)
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := client.Message.Create(ctx, app.Id, invalid); !errors.As(err, &validationErr) {
+			t.Fatalf("expected a validation error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package smoketest checks that an endpoint accepts every event type it
+// subscribes to.
+//
+// For each event type, Run sends an example message to the endpoint with
+// Endpoint.SendExample, and waits for the result of its attempt:
+//
+//	report, err := smoketest.Run(ctx, client, appId, endpointId, nil)
+//	if err == nil && !report.Passed() {
+//		fmt.Print(report)
+//	}
+//
+// SendExample sends the example Svix has for the event type. With
+// Options.GenerateExamples, Run also generates an example from the latest
+// schema of each event type and reports it, to show the payloads the
+// endpoint is expected to handle; that example isn't sent, and failing to
+// generate it doesn't fail the event type. Example messages
+// are real deliveries: the endpoint receives them like any other message.
+package smoketest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/paginate"
+)
+
+type Options struct {
+	// The event types to test when the endpoint doesn't filter event types.
+	// Defaults to every event type that isn't archived.
+	EventTypes []string
+	// How long to wait for the attempts of the examples. Defaults to 30
+	// seconds.
+	Timeout time.Duration
+	// How often attempts are polled. Defaults to 1 second.
+	PollInterval time.Duration
+	// Generate an example from the latest schema of each event type, and
+	// report it in Result.GeneratedExample.
+	GenerateExamples bool
+}
+
+// Result is the outcome of the example of an event type.
+type Result struct {
+	EventType string
+	// The example generated from the latest schema of the event type with
+	// Options.GenerateExamples, nil when it has no schema. It isn't the
+	// payload sent to the endpoint, which is in Message.
+	GeneratedExample map[string]interface{}
+	// Why GeneratedExample couldn't be generated. It doesn't fail the
+	// event type.
+	GenerateErr error
+	// The message sent to the endpoint, nil when it couldn't be sent.
+	Message *svix.MessageOut
+	// The first attempt of the message that finished.
+	Attempt *svix.MessageAttemptOut
+	Err     error
+}
+
+// Passed reports whether the endpoint accepted the example, regardless of
+// GenerateErr.
+func (r *Result) Passed() bool {
+	return r.Err == nil && r.Attempt != nil && r.Attempt.Status == openapi.MESSAGESTATUS_Success
+}
+
+type Report struct {
+	AppId      string
+	EndpointId string
+	Results    []*Result
+}
+
+func (r *Report) Passed() bool {
+	return r.Failed() == 0
+}
+
+// Failed returns the number of event types whose example wasn't accepted.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(&b, "FAIL %s: %v\n", result.EventType, result.Err)
+		case !result.Passed():
+			fmt.Fprintf(&b, "FAIL %s: the endpoint responded with %d\n", result.EventType, result.Attempt.ResponseStatusCode)
+		default:
+			fmt.Fprintf(&b, "ok   %s\n", result.EventType)
+		}
+		if result.GenerateErr != nil {
+			fmt.Fprintf(&b, "     generating an example: %v\n", result.GenerateErr)
+		}
+	}
+	fmt.Fprintf(&b, "%d of %d event types passed on endpoint %s\n", len(r.Results)-r.Failed(), len(r.Results), r.EndpointId)
+	return b.String()
+}
+
+// Run sends an example of every event type the endpoint subscribes to, and
+// reports which ones it accepted. The returned error is only set when the
+// test couldn't run; the failures of event types are in the results.
+func Run(ctx context.Context, client *svix.Svix, appId string, endpointId string, options *Options) (*Report, error) {
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	ep, err := client.Endpoint.Get(ctx, appId, endpointId)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes := ep.FilterTypes
+	if len(eventTypes) == 0 {
+		eventTypes = opts.EventTypes
+	}
+	if len(eventTypes) == 0 {
+		if eventTypes, err = listEventTypes(ctx, client); err != nil {
+			return nil, err
+		}
+	}
+	eventTypes = append([]string(nil), eventTypes...)
+	sort.Strings(eventTypes)
+
+	report := &Report{AppId: appId, EndpointId: ep.Id}
+	for _, name := range eventTypes {
+		result := &Result{EventType: name}
+		report.Results = append(report.Results, result)
+		if err := send(ctx, client, appId, ep.Id, opts.GenerateExamples, result); err != nil {
+			return nil, err
+		}
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+	for _, result := range report.Results {
+		if result.Message == nil {
+			continue
+		}
+		for result.Attempt == nil {
+			attempts, err := client.MessageAttempt.ListByMsg(ctx, appId, result.Message.Id, &svix.MessageAttemptListOptions{EndpointId: &ep.Id})
+			if err != nil {
+				return nil, err
+			}
+			result.Attempt = finishedAttempt(attempts.Data)
+			if result.Attempt != nil {
+				break
+			}
+			if !time.Now().Before(deadline) {
+				result.Err = fmt.Errorf("no attempt of message %s finished within %s", result.Message.Id, opts.Timeout)
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(opts.PollInterval):
+			}
+		}
+	}
+	return report, nil
+}
+
+// send sends the example of the event type of result, after generating one
+// from its schema if generate is set. Failures specific to the event type
+// are recorded in result, generating the example not being one of them.
+func send(ctx context.Context, client *svix.Svix, appId string, endpointId string, generate bool, result *Result) error {
+	et, err := client.EventType.Get(ctx, result.EventType)
+	if isStatus(err, http.StatusNotFound) {
+		result.Err = errors.New("the event type doesn't exist")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if schema := et.Schemas[svix.LatestSchemaVersion(et.Schemas)]; generate && schema != nil {
+		example, err := client.EventType.GenerateExample(ctx, &svix.EventTypeSchemaIn{Schema: schema})
+		if isStatus(err, http.StatusBadRequest) || isStatus(err, http.StatusUnprocessableEntity) {
+			result.GenerateErr = err
+		} else if err != nil {
+			return err
+		} else {
+			result.GeneratedExample = example.Example
+		}
+	}
+
+	msg, err := client.Endpoint.SendExample(ctx, appId, endpointId, &svix.EventExampleIn{EventType: result.EventType})
+	if isStatus(err, http.StatusBadRequest) || isStatus(err, http.StatusUnprocessableEntity) {
+		result.Err = fmt.Errorf("sending the example: %w", err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	result.Message = msg
+	return nil
+}
+
+// finishedAttempt returns the earliest attempt that succeeded or failed.
+func finishedAttempt(attempts []openapi.MessageAttemptOut) *svix.MessageAttemptOut {
+	var ret *svix.MessageAttemptOut
+	for i := range attempts {
+		a := svix.MessageAttemptOut(attempts[i])
+		if a.Status != openapi.MESSAGESTATUS_Success && a.Status != openapi.MESSAGESTATUS_Fail {
+			continue
+		}
+		if ret == nil || a.Timestamp.Before(ret.Timestamp) {
+			ret = &a
+		}
+	}
+	return ret
+}
+
+func listEventTypes(ctx context.Context, client *svix.Svix) ([]string, error) {
+	var names []string
+	err := paginate.Each(func(iterator *string) (*string, bool, error) {
+		out, err := client.EventType.List(ctx, &svix.EventTypeListOptions{Iterator: iterator, Limit: svix.Int32(paginate.PageSize)})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, et := range out.Data {
+			names = append(names, et.Name)
+		}
+		return out.Iterator.Get(), out.Done, nil
+	})
+	return names, err
+}
+
+func isStatus(err error, status int) bool {
+	var svixErr *svix.Error
+	return errors.As(err, &svixErr) && svixErr.Status() == status
+}
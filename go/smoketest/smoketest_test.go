@@ -0,0 +1,106 @@
+package smoketest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/smoketest"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestRun(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{
+		Deliver: func(d *svixtest.Delivery) (int, string) {
+			if d.EventType == "invoice.voided" {
+				return 400, "unknown event type"
+			}
+			return 204, ""
+		},
+	})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"invoice.paid", "invoice.voided"} {
+		_, err := client.EventType.Create(ctx, &svix.EventTypeIn{
+			Name:        name,
+			Description: name,
+			Schemas: map[string]map[string]interface{}{"1": {
+				"type":     "object",
+				"required": []interface{}{"id"},
+				"properties": map[string]interface{}{
+					"id":     map[string]interface{}{"type": "string", "examples": []interface{}{"in_1"}},
+					"amount": map[string]interface{}{"type": "integer", "minimum": 1},
+					"email":  map[string]interface{}{"type": "string", "format": "email"},
+				},
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The fake can only generate examples of objects.
+	_, err = client.EventType.Create(ctx, &svix.EventTypeIn{
+		Name:        "invoice.sent",
+		Description: "invoice.sent",
+		Schemas:     map[string]map[string]interface{}{"1": {"type": "string"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{
+		Url:         "https://example.com/webhook",
+		FilterTypes: []string{"invoice.voided", "invoice.paid", "invoice.sent"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &smoketest.Options{Timeout: 5 * time.Second, PollInterval: 10 * time.Millisecond, GenerateExamples: true}
+	report, err := smoketest.Run(ctx, client, app.Id, ep.Id, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 3 || report.Failed() != 1 {
+		t.Fatalf("unexpected report:\n%s", report)
+	}
+	paid, sent, voided := report.Results[0], report.Results[1], report.Results[2]
+	if !paid.Passed() || paid.EventType != "invoice.paid" || paid.Message == nil || paid.Attempt.ResponseStatusCode != 204 {
+		t.Errorf("unexpected result %+v", paid)
+	}
+	if example := paid.GeneratedExample; example["id"] != "in_1" || example["email"] != "test@example.com" || example["amount"] == nil {
+		t.Errorf("unexpected example %v", example)
+	}
+	// Failing to generate an example doesn't keep the example from being
+	// sent.
+	if !sent.Passed() || sent.GenerateErr == nil || sent.GeneratedExample != nil || sent.Message == nil {
+		t.Errorf("unexpected result %+v", sent)
+	}
+	if voided.Passed() || !strings.Contains(report.String(), "FAIL invoice.voided: the endpoint responded with 400") {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+
+	// Endpoints without filter types are tested with the given event types.
+	all, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	options.EventTypes = []string{"invoice.paid", "invoice.missing"}
+	options.GenerateExamples = false
+	report, err = smoketest.Run(ctx, client, app.Id, all.Id, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 || !report.Results[1].Passed() || report.Results[0].Err == nil {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	if report.Results[1].GeneratedExample != nil {
+		t.Error("expected no example to be generated")
+	}
+}
@@ -0,0 +1,263 @@
+package sqloutbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/outboxutil"
+)
+
+type RelayOptions struct {
+	// Maximum number of rows claimed at once. Defaults to 100.
+	BatchSize int
+	// How long claimed rows stay leased to this relay. Rows whose lease
+	// expires (e.g. because the relay crashed) are claimed again. Defaults to
+	// 1 minute.
+	LeaseDuration time.Duration
+	// How often Run polls for new rows when idle. Defaults to 1 second.
+	PollInterval time.Duration
+	// Number of attempts before a row is marked as failed. Zero means retry forever.
+	MaxAttempts int
+	// Returns the delay before the given (1-based) retry attempt.
+	// Defaults to exponential backoff starting at 1 second and capped at 5 minutes.
+	// Run also waits that long after its n-th error in a row.
+	Backoff func(attempt int) time.Duration
+	// Called with the errors of Run, which keeps relaying.
+	OnError func(err error)
+}
+
+// Relay sends the committed rows of an Outbox to Svix.
+//
+// Several relays may run against the same table; each row is leased to a
+// single relay at a time.
+type Relay struct {
+	outbox  *Outbox
+	client  *svix.Svix
+	id      string
+	options RelayOptions
+}
+
+type row struct {
+	id       string
+	appId    string
+	eventId  string
+	message  string
+	attempts int
+}
+
+func (o *Outbox) NewRelay(client *svix.Svix, options *RelayOptions) *Relay {
+	r := &Relay{
+		outbox: o,
+		client: client,
+		id:     outboxutil.NewId(),
+	}
+	if options != nil {
+		r.options = *options
+	}
+	if r.options.BatchSize <= 0 {
+		r.options.BatchSize = 100
+	}
+	if r.options.LeaseDuration <= 0 {
+		r.options.LeaseDuration = time.Minute
+	}
+	if r.options.PollInterval <= 0 {
+		r.options.PollInterval = time.Second
+	}
+	if r.options.Backoff == nil {
+		r.options.Backoff = outboxutil.Backoff
+	}
+	return r
+}
+
+// Run relays rows until ctx is done. Database and send errors are reported
+// to OnError, and retried with backoff.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.options.PollInterval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		n, err := r.RelayOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			failures++
+			if r.options.OnError != nil {
+				r.options.OnError(err)
+			}
+			timer := time.NewTimer(r.options.Backoff(failures))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+			continue
+		}
+		failures = 0
+		if n > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce claims a single batch of due rows and sends them, returning the
+// number of rows claimed. A row that can't be sent doesn't hold back the
+// rest of the batch: the errors of all rows are joined.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	rows, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	for _, row := range rows {
+		if err := r.send(ctx, row); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return len(rows), errors.Join(errs...)
+}
+
+func (r *Relay) claim(ctx context.Context) ([]row, error) {
+	o := r.outbox
+	now := time.Now()
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	due := fmt.Sprintf(
+		"SELECT id FROM %s WHERE sent_at IS NULL AND failed_at IS NULL AND next_attempt_at <= %s AND (locked_until IS NULL OR locked_until < %s) ORDER BY created_at LIMIT %d",
+		o.table, o.p(1), o.p(2), r.options.BatchSize,
+	)
+	if o.dialect.SkipLocked {
+		due += " FOR UPDATE SKIP LOCKED"
+	}
+	ids, err := queryStrings(ctx, tx, due, now.UnixMilli(), now.UnixMilli())
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	// The lease condition is repeated so that, without row locks, a row
+	// leased by another relay in the meantime isn't stolen.
+	args := []interface{}{r.id, now.Add(r.options.LeaseDuration).UnixMilli(), now.UnixMilli()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	lease := fmt.Sprintf(
+		"UPDATE %s SET locked_by = %s, locked_until = %s WHERE (locked_until IS NULL OR locked_until < %s) AND id IN (%s)",
+		o.table, o.p(1), o.p(2), o.p(3), o.placeholders(4, len(ids)),
+	)
+	if _, err := tx.ExecContext(ctx, lease, args...); err != nil {
+		return nil, err
+	}
+
+	// Only the rows just leased: a row left leased by a failed update isn't
+	// due yet.
+	selectLeased := fmt.Sprintf(
+		"SELECT id, app_id, event_id, message, attempts FROM %s WHERE locked_by = %s AND sent_at IS NULL AND failed_at IS NULL AND id IN (%s) ORDER BY created_at",
+		o.table, o.p(1), o.placeholders(2, len(ids)),
+	)
+	res, err := tx.QueryContext(ctx, selectLeased, append([]interface{}{r.id}, args[3:]...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	var rows []row
+	for res.Next() {
+		var row row
+		if err := res.Scan(&row.id, &row.appId, &row.eventId, &row.message, &row.attempts); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	res.Close()
+	return rows, tx.Commit()
+}
+
+func (r *Relay) send(ctx context.Context, row row) error {
+	o := r.outbox
+	var msg openapi.MessageIn
+	if err := json.Unmarshal([]byte(row.message), &msg); err != nil {
+		return r.markFailed(ctx, row, err)
+	}
+	messageIn := svix.MessageIn(msg)
+	_, err := r.client.Message.CreateWithOptions(ctx, row.appId, &messageIn, &svix.PostOptions{
+		IdempotencyKey: svix.String(outboxutil.IdempotencyKey(row.appId, row.eventId)),
+	})
+	if err == nil || outboxutil.IsConflict(err) {
+		query := fmt.Sprintf(
+			"UPDATE %s SET sent_at = %s, attempts = attempts + 1, locked_by = NULL, locked_until = NULL WHERE id = %s AND locked_by = %s",
+			o.table, o.p(1), o.p(2), o.p(3),
+		)
+		_, err := o.db.ExecContext(ctx, query, time.Now().UnixMilli(), row.id, r.id)
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	attempts := row.attempts + 1
+	if !retryable(err) || (r.options.MaxAttempts > 0 && attempts >= r.options.MaxAttempts) {
+		return r.markFailed(ctx, row, err)
+	}
+	query := fmt.Sprintf(
+		"UPDATE %s SET attempts = %s, next_attempt_at = %s, last_error = %s, locked_by = NULL, locked_until = NULL WHERE id = %s AND locked_by = %s",
+		o.table, o.p(1), o.p(2), o.p(3), o.p(4), o.p(5),
+	)
+	nextAttempt := time.Now().Add(r.options.Backoff(attempts)).UnixMilli()
+	_, dbErr := o.db.ExecContext(ctx, query, attempts, nextAttempt, err.Error(), row.id, r.id)
+	return dbErr
+}
+
+func (r *Relay) markFailed(ctx context.Context, row row, cause error) error {
+	o := r.outbox
+	query := fmt.Sprintf(
+		"UPDATE %s SET failed_at = %s, attempts = attempts + 1, last_error = %s, locked_by = NULL, locked_until = NULL WHERE id = %s AND locked_by = %s",
+		o.table, o.p(1), o.p(2), o.p(3), o.p(4),
+	)
+	_, err := o.db.ExecContext(ctx, query, time.Now().UnixMilli(), cause.Error(), row.id, r.id)
+	return err
+}
+
+// retryable reports whether sending a message may succeed when retried.
+// Payloads rejected by SchemaValidation aren't retried.
+func retryable(err error) bool {
+	var invalid *svix.PayloadValidationError
+	return !errors.As(err, &invalid) && outboxutil.IsRetryable(err)
+}
+
+func queryStrings(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+	return ret, rows.Err()
+}
@@ -0,0 +1,7 @@
+// Package sqlitetest tests package sqloutbox against SQLite.
+//
+// It is a module of its own so that the SQLite driver, which requires cgo,
+// isn't a dependency of the SDK. Run the tests from this directory:
+//
+//	go test ./...
+package sqlitetest
@@ -0,0 +1,202 @@
+package sqlitetest_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/sqloutbox"
+)
+
+type testServer struct {
+	mu     sync.Mutex
+	status []int
+	keys   []string
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := http.StatusAccepted
+	if len(s.status) > 0 {
+		status, s.status = s.status[0], s.status[1:]
+	}
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	s.keys = append(s.keys, r.Header.Get("idempotency-key"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if status >= 300 {
+		w.Write([]byte(`{"code":"error","detail":"error"}`))
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        "msg_1",
+		"eventType": body["eventType"],
+		"eventId":   body["eventId"],
+		"payload":   body["payload"],
+		"timestamp": time.Now(),
+	})
+}
+
+func setup(t *testing.T, srv *testServer) (*sql.DB, *sqloutbox.Outbox, *svix.Svix) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("CREATE TABLE orders (id TEXT PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	outbox := sqloutbox.New(db, nil)
+	if err := outbox.CreateTable(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+	serverUrl, _ := url.Parse(httpSrv.URL)
+	client := svix.New("test_token", &svix.SvixOptions{ServerUrl: serverUrl})
+	return db, outbox, client
+}
+
+func enqueueOrder(t *testing.T, db *sql.DB, outbox *sqloutbox.Outbox, orderId string, commit bool) string {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO orders (id) VALUES (?)", orderId); err != nil {
+		t.Fatal(err)
+	}
+	eventId, err := outbox.Enqueue(ctx, tx, "app_1", &svix.MessageIn{
+		EventType: "order.created",
+		Payload:   map[string]interface{}{"id": orderId},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit {
+		err = tx.Commit()
+	} else {
+		err = tx.Rollback()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return eventId
+}
+
+func TestRelaySendsCommittedRows(t *testing.T) {
+	srv := &testServer{status: []int{http.StatusTooManyRequests}}
+	db, outbox, client := setup(t, srv)
+	ctx := context.Background()
+
+	eventId := enqueueOrder(t, db, outbox, "order_1", true)
+	enqueueOrder(t, db, outbox, "order_2", false)
+
+	relay := outbox.NewRelay(client, &sqloutbox.RelayOptions{
+		Backoff: func(int) time.Duration { return 0 },
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := relay.RelayOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pending, _ := outbox.Pending(ctx); pending != 0 {
+		t.Errorf("expected no pending rows, got %d", pending)
+	}
+	if len(srv.keys) != 2 {
+		t.Fatalf("expected one failed and one successful send, got %d", len(srv.keys))
+	}
+	for _, key := range srv.keys {
+		if key != "outbox:app_1:"+eventId {
+			t.Errorf("unexpected idempotency key %q", key)
+		}
+	}
+
+	var sent int
+	db.QueryRow("SELECT COUNT(*) FROM svix_outbox WHERE sent_at IS NOT NULL AND attempts = 2").Scan(&sent)
+	if sent != 1 {
+		t.Errorf("expected the committed row to be marked sent after 2 attempts")
+	}
+}
+
+func TestRelayMarksRejectedRowsFailed(t *testing.T) {
+	srv := &testServer{status: []int{http.StatusUnprocessableEntity}}
+	db, outbox, client := setup(t, srv)
+	ctx := context.Background()
+
+	enqueueOrder(t, db, outbox, "order_1", true)
+	relay := outbox.NewRelay(client, nil)
+	if n, err := relay.RelayOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("expected one row relayed, got %d (%v)", n, err)
+	}
+	if n, _ := relay.RelayOnce(ctx); n != 0 {
+		t.Errorf("failed row was claimed again")
+	}
+
+	var lastError string
+	db.QueryRow("SELECT last_error FROM svix_outbox WHERE failed_at IS NOT NULL").Scan(&lastError)
+	if lastError == "" {
+		t.Errorf("expected the row to be marked failed with an error")
+	}
+}
+
+func TestLeasedRowsAreNotClaimedTwice(t *testing.T) {
+	srv := &testServer{}
+	db, outbox, client := setup(t, srv)
+	ctx := context.Background()
+
+	enqueueOrder(t, db, outbox, "order_1", true)
+	if _, err := db.Exec("UPDATE svix_outbox SET locked_by = 'other', locked_until = ?", time.Now().Add(time.Minute).UnixMilli()); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := outbox.NewRelay(client, nil).RelayOnce(ctx); n != 0 {
+		t.Errorf("claimed a row leased by another relay")
+	}
+
+	if _, err := db.Exec("UPDATE svix_outbox SET locked_until = ?", time.Now().Add(-time.Minute).UnixMilli()); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := outbox.NewRelay(client, nil).RelayOnce(ctx); n != 1 {
+		t.Errorf("expired lease was not reclaimed")
+	}
+}
+
+func TestPurgeDeletesSentRows(t *testing.T) {
+	srv := &testServer{status: []int{http.StatusAccepted, http.StatusUnprocessableEntity}}
+	db, outbox, client := setup(t, srv)
+	ctx := context.Background()
+
+	enqueueOrder(t, db, outbox, "order_1", true)
+	enqueueOrder(t, db, outbox, "order_2", true)
+	if n, err := outbox.NewRelay(client, nil).RelayOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("expected two rows relayed, got %d (%v)", n, err)
+	}
+	enqueueOrder(t, db, outbox, "order_3", true)
+
+	if n, err := outbox.Purge(ctx, time.Hour); err != nil || n != 0 {
+		t.Errorf("expected recent rows to be kept, got %d (%v)", n, err)
+	}
+	if n, err := outbox.Purge(ctx, -time.Second); err != nil || n != 1 {
+		t.Errorf("expected the sent row to be deleted, got %d (%v)", n, err)
+	}
+	var left int
+	db.QueryRow("SELECT COUNT(*) FROM svix_outbox").Scan(&left)
+	if left != 2 {
+		t.Errorf("expected the failed and pending rows to be kept, got %d rows", left)
+	}
+}
@@ -0,0 +1,157 @@
+// Package sqloutbox implements the transactional outbox pattern on top of
+// database/sql.
+//
+// Messages are written with Enqueue inside the caller's transaction, so they
+// are committed (or rolled back) atomically with the business data. A Relay
+// then claims committed rows, sends them with Message.CreateWithOptions using
+// a stable idempotency key, and marks them sent.
+package sqloutbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+	"github.com/svix/svix-webhooks/go/internal/outboxutil"
+)
+
+// Dialect describes the SQL flavour of the database holding the outbox table.
+type Dialect struct {
+	// Placeholder returns the bind parameter for the n-th (1-based) argument.
+	Placeholder func(n int) string
+	// SkipLocked makes the relay lock candidate rows with
+	// `SELECT ... FOR UPDATE SKIP LOCKED` before leasing them, so that
+	// concurrent relays don't contend on the same rows. When false, rows are
+	// leased with a conditional UPDATE, which works on any database.
+	SkipLocked bool
+}
+
+var (
+	DialectSQLite   = Dialect{Placeholder: questionPlaceholder}
+	DialectMySQL    = Dialect{Placeholder: questionPlaceholder, SkipLocked: true}
+	DialectPostgres = Dialect{Placeholder: dollarPlaceholder, SkipLocked: true}
+)
+
+func questionPlaceholder(int) string {
+	return "?"
+}
+
+func dollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+type Options struct {
+	// Name of the outbox table. Defaults to "svix_outbox".
+	Table string
+	// Defaults to DialectSQLite.
+	Dialect *Dialect
+}
+
+// Outbox writes messages to the outbox table.
+type Outbox struct {
+	db      *sql.DB
+	table   string
+	dialect Dialect
+}
+
+func New(db *sql.DB, options *Options) *Outbox {
+	o := &Outbox{
+		db:      db,
+		table:   "svix_outbox",
+		dialect: DialectSQLite,
+	}
+	if options != nil {
+		if options.Table != "" {
+			o.table = options.Table
+		}
+		if options.Dialect != nil {
+			o.dialect = *options.Dialect
+		}
+	}
+	return o
+}
+
+// CreateTable creates the outbox table if it doesn't exist yet. Timestamps are
+// stored as unix milliseconds so the schema is portable across databases.
+func (o *Outbox) CreateTable(ctx context.Context) error {
+	_, err := o.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	app_id VARCHAR(256) NOT NULL,
+	event_id VARCHAR(256) NOT NULL,
+	message TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at BIGINT NOT NULL,
+	locked_by VARCHAR(64),
+	locked_until BIGINT,
+	sent_at BIGINT,
+	failed_at BIGINT,
+	last_error TEXT
+)`, o.table))
+	return err
+}
+
+// Enqueue inserts the message into the outbox as part of tx and returns its
+// `eventId`, generating one if the message doesn't have one. The message only
+// becomes visible to relays once tx commits.
+func (o *Outbox) Enqueue(ctx context.Context, tx *sql.Tx, appId string, messageIn *svix.MessageIn) (string, error) {
+	msg := openapi.MessageIn(*messageIn)
+	if msg.EventId.Get() == nil || *msg.EventId.Get() == "" {
+		msg.EventId = *svix.NullableString(svix.String("evt_" + outboxutil.NewId()))
+	}
+	eventId := *msg.EventId.Get()
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UnixMilli()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, app_id, event_id, message, created_at, attempts, next_attempt_at) VALUES (%s, %s, %s, %s, %s, 0, %s)",
+		o.table, o.p(1), o.p(2), o.p(3), o.p(4), o.p(5), o.p(6),
+	)
+	_, err = tx.ExecContext(ctx, query, outboxutil.NewId(), appId, eventId, string(data), now, now)
+	if err != nil {
+		return "", err
+	}
+	return eventId, nil
+}
+
+// Pending returns the number of rows that are neither sent nor failed.
+func (o *Outbox) Pending(ctx context.Context) (int, error) {
+	var n int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE sent_at IS NULL AND failed_at IS NULL", o.table)
+	err := o.db.QueryRowContext(ctx, query).Scan(&n)
+	return n, err
+}
+
+// Purge deletes the rows sent more than olderThan ago and returns how many
+// were deleted. Sent rows are otherwise kept forever: call Purge
+// periodically. Failed rows are kept for inspection.
+func (o *Outbox) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE sent_at IS NOT NULL AND sent_at < %s", o.table, o.p(1))
+	res, err := o.db.ExecContext(ctx, query, time.Now().Add(-olderThan).UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (o *Outbox) p(n int) string {
+	return o.dialect.Placeholder(n)
+}
+
+// placeholders returns a comma separated list of count placeholders starting at from.
+func (o *Outbox) placeholders(from int, count int) string {
+	ret := make([]string, count)
+	for i := range ret {
+		ret[i] = o.p(from + i)
+	}
+	return strings.Join(ret, ", ")
+}
@@ -0,0 +1,63 @@
+package svix
+
+import (
+	"context"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type (
+	AttemptStatisticsResponse openapi.AttemptStatisticsResponse
+	AttemptStatisticsData     openapi.AttemptStatisticsData
+	StatisticsPeriod          openapi.StatisticsPeriod
+)
+
+type Statistics struct {
+	api *openapi.APIClient
+}
+
+type AttemptStatisticsOptions struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// AppAttempts returns the number of successful and failed attempts of an
+// application, per period.
+func (s *Statistics) AppAttempts(ctx context.Context, appId string, options *AttemptStatisticsOptions) (*AttemptStatisticsResponse, error) {
+	req := s.api.StatisticsApi.V1StatsAppAttempts(ctx, appId)
+	if options != nil {
+		if options.StartDate != nil {
+			req = req.StartDate(*options.StartDate)
+		}
+		if options.EndDate != nil {
+			req = req.EndDate(*options.EndDate)
+		}
+	}
+	out, res, err := req.Execute()
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	ret := AttemptStatisticsResponse(out)
+	return &ret, nil
+}
+
+// EndpointAttempts returns the number of successful and failed attempts of
+// an endpoint, per period.
+func (s *Statistics) EndpointAttempts(ctx context.Context, appId string, endpointId string, options *AttemptStatisticsOptions) (*AttemptStatisticsResponse, error) {
+	req := s.api.StatisticsApi.V1StatsEndpointAttempts(ctx, appId, endpointId)
+	if options != nil {
+		if options.StartDate != nil {
+			req = req.StartDate(*options.StartDate)
+		}
+		if options.EndDate != nil {
+			req = req.EndDate(*options.EndDate)
+		}
+	}
+	out, res, err := req.Execute()
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	ret := AttemptStatisticsResponse(out)
+	return &ret, nil
+}
@@ -18,15 +18,24 @@ type (
 		// Overrides the base URL (protocol + hostname) used for all requests sent by this Svix client. (Useful for testing)
 		ServerUrl  *url.URL
 		HTTPClient *http.Client
+
+		// Validates the payloads of messages against the schemas of their
+		// event type before they are created. Disabled when nil.
+		SchemaValidation *SchemaValidation
 	}
 	Svix struct {
 		Authentication *Authentication
 		Application    *Application
+		BackgroundTask *BackgroundTask
 		Endpoint       *Endpoint
 		EventType      *EventType
 		Integration    *Integration
 		Message        *Message
 		MessageAttempt *MessageAttempt
+		Statistics     *Statistics
+
+		// The options of the client, to create others with WithToken.
+		options *SvixOptions
 	}
 )
 
@@ -46,6 +55,12 @@ func NullableInt32(num *int32) *openapi.NullableInt32 {
 func Int32(i int32) *int32 {
 	return &i
 }
+func NullableInt64(num *int64) *openapi.NullableInt64 {
+	return openapi.NewNullableInt64(num)
+}
+func Int64(i int64) *int64 {
+	return &i
+}
 
 func New(token string, options *SvixOptions) *Svix {
 	conf := openapi.NewConfiguration()
@@ -76,6 +91,15 @@ func New(token string, options *SvixOptions) *Svix {
 	conf.AddDefaultHeader("Authorization", fmt.Sprintf("Bearer %s", token))
 	conf.UserAgent = fmt.Sprintf("svix-libs/%s/go", version.Version)
 	apiClient := openapi.NewAPIClient(conf)
+	eventType := &EventType{
+		api: apiClient,
+	}
+	message := &Message{
+		api: apiClient,
+	}
+	if options != nil && options.SchemaValidation != nil {
+		message.validator = newSchemaValidator(eventType, *options.SchemaValidation)
+	}
 	return &Svix{
 		Authentication: &Authentication{
 			api: apiClient,
@@ -83,20 +107,23 @@ func New(token string, options *SvixOptions) *Svix {
 		Application: &Application{
 			api: apiClient,
 		},
-		Endpoint: &Endpoint{
-			api: apiClient,
-		},
-		EventType: &EventType{
+		BackgroundTask: &BackgroundTask{
 			api: apiClient,
 		},
-		Message: &Message{
+		Endpoint: &Endpoint{
 			api: apiClient,
 		},
+		EventType: eventType,
+		Message:   message,
 		Integration: &Integration{
 			api: apiClient,
 		},
 		MessageAttempt: &MessageAttempt{
 			api: apiClient,
 		},
+		Statistics: &Statistics{
+			api: apiClient,
+		},
+		options: options,
 	}
 }
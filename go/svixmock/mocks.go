@@ -0,0 +1,1092 @@
+// Code generated by internal/mockgen; DO NOT EDIT.
+
+package svixmock
+
+import (
+	"context"
+	"sync"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+var (
+	_ svix.AuthenticationAPI = (*AuthenticationAPI)(nil)
+	_ svix.ApplicationAPI    = (*ApplicationAPI)(nil)
+	_ svix.BackgroundTaskAPI = (*BackgroundTaskAPI)(nil)
+	_ svix.EndpointAPI       = (*EndpointAPI)(nil)
+	_ svix.EventTypeAPI      = (*EventTypeAPI)(nil)
+	_ svix.IntegrationAPI    = (*IntegrationAPI)(nil)
+	_ svix.MessageAPI        = (*MessageAPI)(nil)
+	_ svix.MessageAttemptAPI = (*MessageAttemptAPI)(nil)
+	_ svix.StatisticsAPI     = (*StatisticsAPI)(nil)
+)
+
+// AuthenticationAPI is a mock implementation of svix.AuthenticationAPI.
+// Calling a method whose Func field is nil panics.
+type AuthenticationAPI struct {
+	AppPortalAccessFunc                 func(ctx context.Context, appId string, appPortalAccessIn *svix.AppPortalAccessIn) (*svix.AppPortalAccessOut, error)
+	AppPortalAccessWithOptionsFunc      func(ctx context.Context, appId string, appPortalAccessIn *svix.AppPortalAccessIn, options *svix.PostOptions) (*svix.AppPortalAccessOut, error)
+	DashboardAccessFunc                 func(ctx context.Context, appId string) (*svix.DashboardAccessOut, error)
+	DashboardAccessWithOptionsFunc      func(ctx context.Context, appId string, options *svix.PostOptions) (*svix.DashboardAccessOut, error)
+	ExchangeOneTimeTokenFunc            func(ctx context.Context, oneTimeTokenIn *svix.OneTimeTokenIn) (*svix.OneTimeTokenOut, error)
+	ExchangeOneTimeTokenWithOptionsFunc func(ctx context.Context, oneTimeTokenIn *svix.OneTimeTokenIn, options *svix.PostOptions) (*svix.OneTimeTokenOut, error)
+	ExpireAllFunc                       func(ctx context.Context, appId string, applicationTokenExpireIn *svix.ApplicationTokenExpireIn) error
+	ExpireAllWithOptionsFunc            func(ctx context.Context, appId string, applicationTokenExpireIn *svix.ApplicationTokenExpireIn, options *svix.PostOptions) error
+	LogoutFunc                          func(ctx context.Context) error
+	LogoutWithOptionsFunc               func(ctx context.Context, options *svix.PostOptions) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *AuthenticationAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *AuthenticationAPI) AppPortalAccess(ctx context.Context, appId string, appPortalAccessIn *svix.AppPortalAccessIn) (*svix.AppPortalAccessOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "AppPortalAccess", Args: []interface{}{ctx, appId, appPortalAccessIn}})
+	m.mu.Unlock()
+	if m.AppPortalAccessFunc == nil {
+		panic("svixmock: AuthenticationAPI.AppPortalAccess called but AppPortalAccessFunc is not set")
+	}
+	return m.AppPortalAccessFunc(ctx, appId, appPortalAccessIn)
+}
+
+func (m *AuthenticationAPI) AppPortalAccessWithOptions(ctx context.Context, appId string, appPortalAccessIn *svix.AppPortalAccessIn, options *svix.PostOptions) (*svix.AppPortalAccessOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "AppPortalAccessWithOptions", Args: []interface{}{ctx, appId, appPortalAccessIn, options}})
+	m.mu.Unlock()
+	if m.AppPortalAccessWithOptionsFunc == nil {
+		panic("svixmock: AuthenticationAPI.AppPortalAccessWithOptions called but AppPortalAccessWithOptionsFunc is not set")
+	}
+	return m.AppPortalAccessWithOptionsFunc(ctx, appId, appPortalAccessIn, options)
+}
+
+func (m *AuthenticationAPI) DashboardAccess(ctx context.Context, appId string) (*svix.DashboardAccessOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "DashboardAccess", Args: []interface{}{ctx, appId}})
+	m.mu.Unlock()
+	if m.DashboardAccessFunc == nil {
+		panic("svixmock: AuthenticationAPI.DashboardAccess called but DashboardAccessFunc is not set")
+	}
+	return m.DashboardAccessFunc(ctx, appId)
+}
+
+func (m *AuthenticationAPI) DashboardAccessWithOptions(ctx context.Context, appId string, options *svix.PostOptions) (*svix.DashboardAccessOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "DashboardAccessWithOptions", Args: []interface{}{ctx, appId, options}})
+	m.mu.Unlock()
+	if m.DashboardAccessWithOptionsFunc == nil {
+		panic("svixmock: AuthenticationAPI.DashboardAccessWithOptions called but DashboardAccessWithOptionsFunc is not set")
+	}
+	return m.DashboardAccessWithOptionsFunc(ctx, appId, options)
+}
+
+func (m *AuthenticationAPI) ExchangeOneTimeToken(ctx context.Context, oneTimeTokenIn *svix.OneTimeTokenIn) (*svix.OneTimeTokenOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ExchangeOneTimeToken", Args: []interface{}{ctx, oneTimeTokenIn}})
+	m.mu.Unlock()
+	if m.ExchangeOneTimeTokenFunc == nil {
+		panic("svixmock: AuthenticationAPI.ExchangeOneTimeToken called but ExchangeOneTimeTokenFunc is not set")
+	}
+	return m.ExchangeOneTimeTokenFunc(ctx, oneTimeTokenIn)
+}
+
+func (m *AuthenticationAPI) ExchangeOneTimeTokenWithOptions(ctx context.Context, oneTimeTokenIn *svix.OneTimeTokenIn, options *svix.PostOptions) (*svix.OneTimeTokenOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ExchangeOneTimeTokenWithOptions", Args: []interface{}{ctx, oneTimeTokenIn, options}})
+	m.mu.Unlock()
+	if m.ExchangeOneTimeTokenWithOptionsFunc == nil {
+		panic("svixmock: AuthenticationAPI.ExchangeOneTimeTokenWithOptions called but ExchangeOneTimeTokenWithOptionsFunc is not set")
+	}
+	return m.ExchangeOneTimeTokenWithOptionsFunc(ctx, oneTimeTokenIn, options)
+}
+
+func (m *AuthenticationAPI) ExpireAll(ctx context.Context, appId string, applicationTokenExpireIn *svix.ApplicationTokenExpireIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ExpireAll", Args: []interface{}{ctx, appId, applicationTokenExpireIn}})
+	m.mu.Unlock()
+	if m.ExpireAllFunc == nil {
+		panic("svixmock: AuthenticationAPI.ExpireAll called but ExpireAllFunc is not set")
+	}
+	return m.ExpireAllFunc(ctx, appId, applicationTokenExpireIn)
+}
+
+func (m *AuthenticationAPI) ExpireAllWithOptions(ctx context.Context, appId string, applicationTokenExpireIn *svix.ApplicationTokenExpireIn, options *svix.PostOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ExpireAllWithOptions", Args: []interface{}{ctx, appId, applicationTokenExpireIn, options}})
+	m.mu.Unlock()
+	if m.ExpireAllWithOptionsFunc == nil {
+		panic("svixmock: AuthenticationAPI.ExpireAllWithOptions called but ExpireAllWithOptionsFunc is not set")
+	}
+	return m.ExpireAllWithOptionsFunc(ctx, appId, applicationTokenExpireIn, options)
+}
+
+func (m *AuthenticationAPI) Logout(ctx context.Context) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Logout", Args: []interface{}{ctx}})
+	m.mu.Unlock()
+	if m.LogoutFunc == nil {
+		panic("svixmock: AuthenticationAPI.Logout called but LogoutFunc is not set")
+	}
+	return m.LogoutFunc(ctx)
+}
+
+func (m *AuthenticationAPI) LogoutWithOptions(ctx context.Context, options *svix.PostOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "LogoutWithOptions", Args: []interface{}{ctx, options}})
+	m.mu.Unlock()
+	if m.LogoutWithOptionsFunc == nil {
+		panic("svixmock: AuthenticationAPI.LogoutWithOptions called but LogoutWithOptionsFunc is not set")
+	}
+	return m.LogoutWithOptionsFunc(ctx, options)
+}
+
+// ApplicationAPI is a mock implementation of svix.ApplicationAPI.
+// Calling a method whose Func field is nil panics.
+type ApplicationAPI struct {
+	ListFunc                   func(ctx context.Context, options *svix.ApplicationListOptions) (*svix.ListResponseApplicationOut, error)
+	CreateFunc                 func(ctx context.Context, applicationIn *svix.ApplicationIn) (*svix.ApplicationOut, error)
+	CreateWithOptionsFunc      func(ctx context.Context, applicationIn *svix.ApplicationIn, options *svix.PostOptions) (*svix.ApplicationOut, error)
+	GetOrCreateFunc            func(ctx context.Context, applicationIn *svix.ApplicationIn) (*svix.ApplicationOut, error)
+	GetOrCreateWithOptionsFunc func(ctx context.Context, applicationIn *svix.ApplicationIn, options *svix.PostOptions) (*svix.ApplicationOut, error)
+	GetFunc                    func(ctx context.Context, appId string) (*svix.ApplicationOut, error)
+	UpdateFunc                 func(ctx context.Context, appId string, applicationIn *svix.ApplicationIn) (*svix.ApplicationOut, error)
+	PatchFunc                  func(ctx context.Context, appId string, applicationPatch *svix.ApplicationPatch) (*svix.ApplicationOut, error)
+	DeleteFunc                 func(ctx context.Context, appId string) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *ApplicationAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *ApplicationAPI) List(ctx context.Context, options *svix.ApplicationListOptions) (*svix.ListResponseApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: ApplicationAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, options)
+}
+
+func (m *ApplicationAPI) Create(ctx context.Context, applicationIn *svix.ApplicationIn) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Create", Args: []interface{}{ctx, applicationIn}})
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		panic("svixmock: ApplicationAPI.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, applicationIn)
+}
+
+func (m *ApplicationAPI) CreateWithOptions(ctx context.Context, applicationIn *svix.ApplicationIn, options *svix.PostOptions) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "CreateWithOptions", Args: []interface{}{ctx, applicationIn, options}})
+	m.mu.Unlock()
+	if m.CreateWithOptionsFunc == nil {
+		panic("svixmock: ApplicationAPI.CreateWithOptions called but CreateWithOptionsFunc is not set")
+	}
+	return m.CreateWithOptionsFunc(ctx, applicationIn, options)
+}
+
+func (m *ApplicationAPI) GetOrCreate(ctx context.Context, applicationIn *svix.ApplicationIn) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetOrCreate", Args: []interface{}{ctx, applicationIn}})
+	m.mu.Unlock()
+	if m.GetOrCreateFunc == nil {
+		panic("svixmock: ApplicationAPI.GetOrCreate called but GetOrCreateFunc is not set")
+	}
+	return m.GetOrCreateFunc(ctx, applicationIn)
+}
+
+func (m *ApplicationAPI) GetOrCreateWithOptions(ctx context.Context, applicationIn *svix.ApplicationIn, options *svix.PostOptions) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetOrCreateWithOptions", Args: []interface{}{ctx, applicationIn, options}})
+	m.mu.Unlock()
+	if m.GetOrCreateWithOptionsFunc == nil {
+		panic("svixmock: ApplicationAPI.GetOrCreateWithOptions called but GetOrCreateWithOptionsFunc is not set")
+	}
+	return m.GetOrCreateWithOptionsFunc(ctx, applicationIn, options)
+}
+
+func (m *ApplicationAPI) Get(ctx context.Context, appId string) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, appId}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: ApplicationAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, appId)
+}
+
+func (m *ApplicationAPI) Update(ctx context.Context, appId string, applicationIn *svix.ApplicationIn) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Update", Args: []interface{}{ctx, appId, applicationIn}})
+	m.mu.Unlock()
+	if m.UpdateFunc == nil {
+		panic("svixmock: ApplicationAPI.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(ctx, appId, applicationIn)
+}
+
+func (m *ApplicationAPI) Patch(ctx context.Context, appId string, applicationPatch *svix.ApplicationPatch) (*svix.ApplicationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Patch", Args: []interface{}{ctx, appId, applicationPatch}})
+	m.mu.Unlock()
+	if m.PatchFunc == nil {
+		panic("svixmock: ApplicationAPI.Patch called but PatchFunc is not set")
+	}
+	return m.PatchFunc(ctx, appId, applicationPatch)
+}
+
+func (m *ApplicationAPI) Delete(ctx context.Context, appId string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Delete", Args: []interface{}{ctx, appId}})
+	m.mu.Unlock()
+	if m.DeleteFunc == nil {
+		panic("svixmock: ApplicationAPI.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(ctx, appId)
+}
+
+// BackgroundTaskAPI is a mock implementation of svix.BackgroundTaskAPI.
+// Calling a method whose Func field is nil panics.
+type BackgroundTaskAPI struct {
+	ListFunc func(ctx context.Context, options *svix.BackgroundTaskListOptions) (*svix.ListResponseBackgroundTaskOut, error)
+	GetFunc  func(ctx context.Context, taskId string) (*svix.BackgroundTaskOut, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *BackgroundTaskAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *BackgroundTaskAPI) List(ctx context.Context, options *svix.BackgroundTaskListOptions) (*svix.ListResponseBackgroundTaskOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: BackgroundTaskAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, options)
+}
+
+func (m *BackgroundTaskAPI) Get(ctx context.Context, taskId string) (*svix.BackgroundTaskOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, taskId}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: BackgroundTaskAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, taskId)
+}
+
+// EndpointAPI is a mock implementation of svix.EndpointAPI.
+// Calling a method whose Func field is nil panics.
+type EndpointAPI struct {
+	ListFunc                              func(ctx context.Context, appId string, options *svix.EndpointListOptions) (*svix.ListResponseEndpointOut, error)
+	CreateFunc                            func(ctx context.Context, appId string, endpointIn *svix.EndpointIn) (*svix.EndpointOut, error)
+	CreateWithOptionsFunc                 func(ctx context.Context, appId string, endpointIn *svix.EndpointIn, options *svix.PostOptions) (*svix.EndpointOut, error)
+	GetFunc                               func(ctx context.Context, appId string, endpointId string) (*svix.EndpointOut, error)
+	UpdateFunc                            func(ctx context.Context, appId string, endpointId string, endpointUpdate *svix.EndpointUpdate) (*svix.EndpointOut, error)
+	PatchFunc                             func(ctx context.Context, appId string, endpointId string, endpointPatch *svix.EndpointPatch) (*svix.EndpointOut, error)
+	DeleteFunc                            func(ctx context.Context, appId string, endpointId string) error
+	GetSecretFunc                         func(ctx context.Context, appId string, endpointId string) (*svix.EndpointSecretOut, error)
+	RotateSecretFunc                      func(ctx context.Context, appId string, endpointId string, endpointSecretRotateIn *svix.EndpointSecretRotateIn) error
+	RotateSecretWithOptionsFunc           func(ctx context.Context, appId string, endpointId string, endpointSecretRotateIn *svix.EndpointSecretRotateIn, options *svix.PostOptions) error
+	RecoverFunc                           func(ctx context.Context, appId string, endpointId string, recoverIn *svix.RecoverIn) error
+	RecoverWithOptionsFunc                func(ctx context.Context, appId string, endpointId string, recoverIn *svix.RecoverIn, options *svix.PostOptions) error
+	GetHeadersFunc                        func(ctx context.Context, appId string, endpointId string) (*svix.EndpointHeadersOut, error)
+	UpdateHeadersFunc                     func(ctx context.Context, appId string, endpointId string, endpointHeadersIn *svix.EndpointHeadersIn) error
+	PatchHeadersFunc                      func(ctx context.Context, appId string, endpointId string, endpointHeadersIn *svix.EndpointHeadersPatchIn) error
+	GetStatsFunc                          func(ctx context.Context, appId string, endpointId string) (*svix.EndpointStats, error)
+	GetStatsWithOptionsFunc               func(ctx context.Context, appId string, endpointId string, options svix.EndpointStatsOptions) (*svix.EndpointStats, error)
+	ReplayMissingFunc                     func(ctx context.Context, appId string, endpointId string, replayIn *svix.ReplayIn) error
+	ReplayMissingWithOptionsFunc          func(ctx context.Context, appId string, endpointId string, replayIn *svix.ReplayIn, options *svix.PostOptions) error
+	TransformationGetFunc                 func(ctx context.Context, appId string, endpointId string) (*svix.EndpointTransformationOut, error)
+	TransformatioPartialUpdateFunc        func(ctx context.Context, appId string, endpointId string, transformation *svix.EndpointTransformationIn) error
+	TransformationSimulateFunc            func(ctx context.Context, appId string, endpointId string, simulateIn *svix.EndpointTransformationSimulateIn) (*svix.EndpointTransformationSimulateOut, error)
+	TransformationSimulateWithOptionsFunc func(ctx context.Context, appId string, endpointId string, simulateIn *svix.EndpointTransformationSimulateIn, options *svix.PostOptions) (*svix.EndpointTransformationSimulateOut, error)
+	SendExampleFunc                       func(ctx context.Context, appId string, endpointId string, eventExampleIn *svix.EventExampleIn) (*svix.MessageOut, error)
+	SendExampleWithOptionsFunc            func(ctx context.Context, appId string, endpointId string, eventExampleIn *svix.EventExampleIn, options *svix.PostOptions) (*svix.MessageOut, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *EndpointAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *EndpointAPI) List(ctx context.Context, appId string, options *svix.EndpointListOptions) (*svix.ListResponseEndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, appId, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: EndpointAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, appId, options)
+}
+
+func (m *EndpointAPI) Create(ctx context.Context, appId string, endpointIn *svix.EndpointIn) (*svix.EndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Create", Args: []interface{}{ctx, appId, endpointIn}})
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		panic("svixmock: EndpointAPI.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, appId, endpointIn)
+}
+
+func (m *EndpointAPI) CreateWithOptions(ctx context.Context, appId string, endpointIn *svix.EndpointIn, options *svix.PostOptions) (*svix.EndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "CreateWithOptions", Args: []interface{}{ctx, appId, endpointIn, options}})
+	m.mu.Unlock()
+	if m.CreateWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.CreateWithOptions called but CreateWithOptionsFunc is not set")
+	}
+	return m.CreateWithOptionsFunc(ctx, appId, endpointIn, options)
+}
+
+func (m *EndpointAPI) Get(ctx context.Context, appId string, endpointId string) (*svix.EndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, appId, endpointId}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: EndpointAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, appId, endpointId)
+}
+
+func (m *EndpointAPI) Update(ctx context.Context, appId string, endpointId string, endpointUpdate *svix.EndpointUpdate) (*svix.EndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Update", Args: []interface{}{ctx, appId, endpointId, endpointUpdate}})
+	m.mu.Unlock()
+	if m.UpdateFunc == nil {
+		panic("svixmock: EndpointAPI.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(ctx, appId, endpointId, endpointUpdate)
+}
+
+func (m *EndpointAPI) Patch(ctx context.Context, appId string, endpointId string, endpointPatch *svix.EndpointPatch) (*svix.EndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Patch", Args: []interface{}{ctx, appId, endpointId, endpointPatch}})
+	m.mu.Unlock()
+	if m.PatchFunc == nil {
+		panic("svixmock: EndpointAPI.Patch called but PatchFunc is not set")
+	}
+	return m.PatchFunc(ctx, appId, endpointId, endpointPatch)
+}
+
+func (m *EndpointAPI) Delete(ctx context.Context, appId string, endpointId string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Delete", Args: []interface{}{ctx, appId, endpointId}})
+	m.mu.Unlock()
+	if m.DeleteFunc == nil {
+		panic("svixmock: EndpointAPI.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(ctx, appId, endpointId)
+}
+
+func (m *EndpointAPI) GetSecret(ctx context.Context, appId string, endpointId string) (*svix.EndpointSecretOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetSecret", Args: []interface{}{ctx, appId, endpointId}})
+	m.mu.Unlock()
+	if m.GetSecretFunc == nil {
+		panic("svixmock: EndpointAPI.GetSecret called but GetSecretFunc is not set")
+	}
+	return m.GetSecretFunc(ctx, appId, endpointId)
+}
+
+func (m *EndpointAPI) RotateSecret(ctx context.Context, appId string, endpointId string, endpointSecretRotateIn *svix.EndpointSecretRotateIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "RotateSecret", Args: []interface{}{ctx, appId, endpointId, endpointSecretRotateIn}})
+	m.mu.Unlock()
+	if m.RotateSecretFunc == nil {
+		panic("svixmock: EndpointAPI.RotateSecret called but RotateSecretFunc is not set")
+	}
+	return m.RotateSecretFunc(ctx, appId, endpointId, endpointSecretRotateIn)
+}
+
+func (m *EndpointAPI) RotateSecretWithOptions(ctx context.Context, appId string, endpointId string, endpointSecretRotateIn *svix.EndpointSecretRotateIn, options *svix.PostOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "RotateSecretWithOptions", Args: []interface{}{ctx, appId, endpointId, endpointSecretRotateIn, options}})
+	m.mu.Unlock()
+	if m.RotateSecretWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.RotateSecretWithOptions called but RotateSecretWithOptionsFunc is not set")
+	}
+	return m.RotateSecretWithOptionsFunc(ctx, appId, endpointId, endpointSecretRotateIn, options)
+}
+
+func (m *EndpointAPI) Recover(ctx context.Context, appId string, endpointId string, recoverIn *svix.RecoverIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Recover", Args: []interface{}{ctx, appId, endpointId, recoverIn}})
+	m.mu.Unlock()
+	if m.RecoverFunc == nil {
+		panic("svixmock: EndpointAPI.Recover called but RecoverFunc is not set")
+	}
+	return m.RecoverFunc(ctx, appId, endpointId, recoverIn)
+}
+
+func (m *EndpointAPI) RecoverWithOptions(ctx context.Context, appId string, endpointId string, recoverIn *svix.RecoverIn, options *svix.PostOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "RecoverWithOptions", Args: []interface{}{ctx, appId, endpointId, recoverIn, options}})
+	m.mu.Unlock()
+	if m.RecoverWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.RecoverWithOptions called but RecoverWithOptionsFunc is not set")
+	}
+	return m.RecoverWithOptionsFunc(ctx, appId, endpointId, recoverIn, options)
+}
+
+func (m *EndpointAPI) GetHeaders(ctx context.Context, appId string, endpointId string) (*svix.EndpointHeadersOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetHeaders", Args: []interface{}{ctx, appId, endpointId}})
+	m.mu.Unlock()
+	if m.GetHeadersFunc == nil {
+		panic("svixmock: EndpointAPI.GetHeaders called but GetHeadersFunc is not set")
+	}
+	return m.GetHeadersFunc(ctx, appId, endpointId)
+}
+
+func (m *EndpointAPI) UpdateHeaders(ctx context.Context, appId string, endpointId string, endpointHeadersIn *svix.EndpointHeadersIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "UpdateHeaders", Args: []interface{}{ctx, appId, endpointId, endpointHeadersIn}})
+	m.mu.Unlock()
+	if m.UpdateHeadersFunc == nil {
+		panic("svixmock: EndpointAPI.UpdateHeaders called but UpdateHeadersFunc is not set")
+	}
+	return m.UpdateHeadersFunc(ctx, appId, endpointId, endpointHeadersIn)
+}
+
+func (m *EndpointAPI) PatchHeaders(ctx context.Context, appId string, endpointId string, endpointHeadersIn *svix.EndpointHeadersPatchIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "PatchHeaders", Args: []interface{}{ctx, appId, endpointId, endpointHeadersIn}})
+	m.mu.Unlock()
+	if m.PatchHeadersFunc == nil {
+		panic("svixmock: EndpointAPI.PatchHeaders called but PatchHeadersFunc is not set")
+	}
+	return m.PatchHeadersFunc(ctx, appId, endpointId, endpointHeadersIn)
+}
+
+func (m *EndpointAPI) GetStats(ctx context.Context, appId string, endpointId string) (*svix.EndpointStats, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetStats", Args: []interface{}{ctx, appId, endpointId}})
+	m.mu.Unlock()
+	if m.GetStatsFunc == nil {
+		panic("svixmock: EndpointAPI.GetStats called but GetStatsFunc is not set")
+	}
+	return m.GetStatsFunc(ctx, appId, endpointId)
+}
+
+func (m *EndpointAPI) GetStatsWithOptions(ctx context.Context, appId string, endpointId string, options svix.EndpointStatsOptions) (*svix.EndpointStats, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetStatsWithOptions", Args: []interface{}{ctx, appId, endpointId, options}})
+	m.mu.Unlock()
+	if m.GetStatsWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.GetStatsWithOptions called but GetStatsWithOptionsFunc is not set")
+	}
+	return m.GetStatsWithOptionsFunc(ctx, appId, endpointId, options)
+}
+
+func (m *EndpointAPI) ReplayMissing(ctx context.Context, appId string, endpointId string, replayIn *svix.ReplayIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ReplayMissing", Args: []interface{}{ctx, appId, endpointId, replayIn}})
+	m.mu.Unlock()
+	if m.ReplayMissingFunc == nil {
+		panic("svixmock: EndpointAPI.ReplayMissing called but ReplayMissingFunc is not set")
+	}
+	return m.ReplayMissingFunc(ctx, appId, endpointId, replayIn)
+}
+
+func (m *EndpointAPI) ReplayMissingWithOptions(ctx context.Context, appId string, endpointId string, replayIn *svix.ReplayIn, options *svix.PostOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ReplayMissingWithOptions", Args: []interface{}{ctx, appId, endpointId, replayIn, options}})
+	m.mu.Unlock()
+	if m.ReplayMissingWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.ReplayMissingWithOptions called but ReplayMissingWithOptionsFunc is not set")
+	}
+	return m.ReplayMissingWithOptionsFunc(ctx, appId, endpointId, replayIn, options)
+}
+
+func (m *EndpointAPI) TransformationGet(ctx context.Context, appId string, endpointId string) (*svix.EndpointTransformationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "TransformationGet", Args: []interface{}{ctx, appId, endpointId}})
+	m.mu.Unlock()
+	if m.TransformationGetFunc == nil {
+		panic("svixmock: EndpointAPI.TransformationGet called but TransformationGetFunc is not set")
+	}
+	return m.TransformationGetFunc(ctx, appId, endpointId)
+}
+
+func (m *EndpointAPI) TransformatioPartialUpdate(ctx context.Context, appId string, endpointId string, transformation *svix.EndpointTransformationIn) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "TransformatioPartialUpdate", Args: []interface{}{ctx, appId, endpointId, transformation}})
+	m.mu.Unlock()
+	if m.TransformatioPartialUpdateFunc == nil {
+		panic("svixmock: EndpointAPI.TransformatioPartialUpdate called but TransformatioPartialUpdateFunc is not set")
+	}
+	return m.TransformatioPartialUpdateFunc(ctx, appId, endpointId, transformation)
+}
+
+func (m *EndpointAPI) TransformationSimulate(ctx context.Context, appId string, endpointId string, simulateIn *svix.EndpointTransformationSimulateIn) (*svix.EndpointTransformationSimulateOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "TransformationSimulate", Args: []interface{}{ctx, appId, endpointId, simulateIn}})
+	m.mu.Unlock()
+	if m.TransformationSimulateFunc == nil {
+		panic("svixmock: EndpointAPI.TransformationSimulate called but TransformationSimulateFunc is not set")
+	}
+	return m.TransformationSimulateFunc(ctx, appId, endpointId, simulateIn)
+}
+
+func (m *EndpointAPI) TransformationSimulateWithOptions(ctx context.Context, appId string, endpointId string, simulateIn *svix.EndpointTransformationSimulateIn, options *svix.PostOptions) (*svix.EndpointTransformationSimulateOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "TransformationSimulateWithOptions", Args: []interface{}{ctx, appId, endpointId, simulateIn, options}})
+	m.mu.Unlock()
+	if m.TransformationSimulateWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.TransformationSimulateWithOptions called but TransformationSimulateWithOptionsFunc is not set")
+	}
+	return m.TransformationSimulateWithOptionsFunc(ctx, appId, endpointId, simulateIn, options)
+}
+
+func (m *EndpointAPI) SendExample(ctx context.Context, appId string, endpointId string, eventExampleIn *svix.EventExampleIn) (*svix.MessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "SendExample", Args: []interface{}{ctx, appId, endpointId, eventExampleIn}})
+	m.mu.Unlock()
+	if m.SendExampleFunc == nil {
+		panic("svixmock: EndpointAPI.SendExample called but SendExampleFunc is not set")
+	}
+	return m.SendExampleFunc(ctx, appId, endpointId, eventExampleIn)
+}
+
+func (m *EndpointAPI) SendExampleWithOptions(ctx context.Context, appId string, endpointId string, eventExampleIn *svix.EventExampleIn, options *svix.PostOptions) (*svix.MessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "SendExampleWithOptions", Args: []interface{}{ctx, appId, endpointId, eventExampleIn, options}})
+	m.mu.Unlock()
+	if m.SendExampleWithOptionsFunc == nil {
+		panic("svixmock: EndpointAPI.SendExampleWithOptions called but SendExampleWithOptionsFunc is not set")
+	}
+	return m.SendExampleWithOptionsFunc(ctx, appId, endpointId, eventExampleIn, options)
+}
+
+// EventTypeAPI is a mock implementation of svix.EventTypeAPI.
+// Calling a method whose Func field is nil panics.
+type EventTypeAPI struct {
+	ListFunc                       func(ctx context.Context, options *svix.EventTypeListOptions) (*svix.ListResponseEventTypeOut, error)
+	CreateFunc                     func(ctx context.Context, eventTypeIn *svix.EventTypeIn) (*svix.EventTypeOut, error)
+	CreateWithOptionsFunc          func(ctx context.Context, eventTypeIn *svix.EventTypeIn, options *svix.PostOptions) (*svix.EventTypeOut, error)
+	GetFunc                        func(ctx context.Context, eventTypeName string) (*svix.EventTypeOut, error)
+	UpdateFunc                     func(ctx context.Context, eventTypeName string, eventTypeUpdate *svix.EventTypeUpdate) (*svix.EventTypeOut, error)
+	PatchFunc                      func(ctx context.Context, eventTypeName string, eventTypePatch *svix.EventTypePatch) (*svix.EventTypeOut, error)
+	DeleteFunc                     func(ctx context.Context, eventTypeName string) error
+	GenerateExampleFunc            func(ctx context.Context, eventTypeSchemaIn *svix.EventTypeSchemaIn) (*svix.EventTypeExampleOut, error)
+	GenerateExampleWithOptionsFunc func(ctx context.Context, eventTypeSchemaIn *svix.EventTypeSchemaIn, options *svix.PostOptions) (*svix.EventTypeExampleOut, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *EventTypeAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *EventTypeAPI) List(ctx context.Context, options *svix.EventTypeListOptions) (*svix.ListResponseEventTypeOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: EventTypeAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, options)
+}
+
+func (m *EventTypeAPI) Create(ctx context.Context, eventTypeIn *svix.EventTypeIn) (*svix.EventTypeOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Create", Args: []interface{}{ctx, eventTypeIn}})
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		panic("svixmock: EventTypeAPI.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, eventTypeIn)
+}
+
+func (m *EventTypeAPI) CreateWithOptions(ctx context.Context, eventTypeIn *svix.EventTypeIn, options *svix.PostOptions) (*svix.EventTypeOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "CreateWithOptions", Args: []interface{}{ctx, eventTypeIn, options}})
+	m.mu.Unlock()
+	if m.CreateWithOptionsFunc == nil {
+		panic("svixmock: EventTypeAPI.CreateWithOptions called but CreateWithOptionsFunc is not set")
+	}
+	return m.CreateWithOptionsFunc(ctx, eventTypeIn, options)
+}
+
+func (m *EventTypeAPI) Get(ctx context.Context, eventTypeName string) (*svix.EventTypeOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, eventTypeName}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: EventTypeAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, eventTypeName)
+}
+
+func (m *EventTypeAPI) Update(ctx context.Context, eventTypeName string, eventTypeUpdate *svix.EventTypeUpdate) (*svix.EventTypeOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Update", Args: []interface{}{ctx, eventTypeName, eventTypeUpdate}})
+	m.mu.Unlock()
+	if m.UpdateFunc == nil {
+		panic("svixmock: EventTypeAPI.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(ctx, eventTypeName, eventTypeUpdate)
+}
+
+func (m *EventTypeAPI) Patch(ctx context.Context, eventTypeName string, eventTypePatch *svix.EventTypePatch) (*svix.EventTypeOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Patch", Args: []interface{}{ctx, eventTypeName, eventTypePatch}})
+	m.mu.Unlock()
+	if m.PatchFunc == nil {
+		panic("svixmock: EventTypeAPI.Patch called but PatchFunc is not set")
+	}
+	return m.PatchFunc(ctx, eventTypeName, eventTypePatch)
+}
+
+func (m *EventTypeAPI) Delete(ctx context.Context, eventTypeName string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Delete", Args: []interface{}{ctx, eventTypeName}})
+	m.mu.Unlock()
+	if m.DeleteFunc == nil {
+		panic("svixmock: EventTypeAPI.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(ctx, eventTypeName)
+}
+
+func (m *EventTypeAPI) GenerateExample(ctx context.Context, eventTypeSchemaIn *svix.EventTypeSchemaIn) (*svix.EventTypeExampleOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GenerateExample", Args: []interface{}{ctx, eventTypeSchemaIn}})
+	m.mu.Unlock()
+	if m.GenerateExampleFunc == nil {
+		panic("svixmock: EventTypeAPI.GenerateExample called but GenerateExampleFunc is not set")
+	}
+	return m.GenerateExampleFunc(ctx, eventTypeSchemaIn)
+}
+
+func (m *EventTypeAPI) GenerateExampleWithOptions(ctx context.Context, eventTypeSchemaIn *svix.EventTypeSchemaIn, options *svix.PostOptions) (*svix.EventTypeExampleOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GenerateExampleWithOptions", Args: []interface{}{ctx, eventTypeSchemaIn, options}})
+	m.mu.Unlock()
+	if m.GenerateExampleWithOptionsFunc == nil {
+		panic("svixmock: EventTypeAPI.GenerateExampleWithOptions called but GenerateExampleWithOptionsFunc is not set")
+	}
+	return m.GenerateExampleWithOptionsFunc(ctx, eventTypeSchemaIn, options)
+}
+
+// IntegrationAPI is a mock implementation of svix.IntegrationAPI.
+// Calling a method whose Func field is nil panics.
+type IntegrationAPI struct {
+	ListFunc                 func(ctx context.Context, appId string, options *svix.IntegrationListOptions) (*svix.ListResponseIntegrationOut, error)
+	CreateFunc               func(ctx context.Context, appId string, integrationIn *svix.IntegrationIn) (*svix.IntegrationOut, error)
+	CreateWithOptionsFunc    func(ctx context.Context, appId string, integrationIn *svix.IntegrationIn, options *svix.PostOptions) (*svix.IntegrationOut, error)
+	GetFunc                  func(ctx context.Context, appId string, integId string) (*svix.IntegrationOut, error)
+	UpdateFunc               func(ctx context.Context, appId string, integId string, integrationUpdate *svix.IntegrationUpdate) (*svix.IntegrationOut, error)
+	DeleteFunc               func(ctx context.Context, appId string, integId string) error
+	GetKeyFunc               func(ctx context.Context, appId string, integId string) (*svix.IntegrationKeyOut, error)
+	RotateKeyFunc            func(ctx context.Context, appId string, integId string) (*svix.IntegrationKeyOut, error)
+	RotateKeyWithOptionsFunc func(ctx context.Context, appId string, integId string, options *svix.PostOptions) (*svix.IntegrationKeyOut, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *IntegrationAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *IntegrationAPI) List(ctx context.Context, appId string, options *svix.IntegrationListOptions) (*svix.ListResponseIntegrationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, appId, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: IntegrationAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, appId, options)
+}
+
+func (m *IntegrationAPI) Create(ctx context.Context, appId string, integrationIn *svix.IntegrationIn) (*svix.IntegrationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Create", Args: []interface{}{ctx, appId, integrationIn}})
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		panic("svixmock: IntegrationAPI.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, appId, integrationIn)
+}
+
+func (m *IntegrationAPI) CreateWithOptions(ctx context.Context, appId string, integrationIn *svix.IntegrationIn, options *svix.PostOptions) (*svix.IntegrationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "CreateWithOptions", Args: []interface{}{ctx, appId, integrationIn, options}})
+	m.mu.Unlock()
+	if m.CreateWithOptionsFunc == nil {
+		panic("svixmock: IntegrationAPI.CreateWithOptions called but CreateWithOptionsFunc is not set")
+	}
+	return m.CreateWithOptionsFunc(ctx, appId, integrationIn, options)
+}
+
+func (m *IntegrationAPI) Get(ctx context.Context, appId string, integId string) (*svix.IntegrationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, appId, integId}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: IntegrationAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, appId, integId)
+}
+
+func (m *IntegrationAPI) Update(ctx context.Context, appId string, integId string, integrationUpdate *svix.IntegrationUpdate) (*svix.IntegrationOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Update", Args: []interface{}{ctx, appId, integId, integrationUpdate}})
+	m.mu.Unlock()
+	if m.UpdateFunc == nil {
+		panic("svixmock: IntegrationAPI.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(ctx, appId, integId, integrationUpdate)
+}
+
+func (m *IntegrationAPI) Delete(ctx context.Context, appId string, integId string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Delete", Args: []interface{}{ctx, appId, integId}})
+	m.mu.Unlock()
+	if m.DeleteFunc == nil {
+		panic("svixmock: IntegrationAPI.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(ctx, appId, integId)
+}
+
+func (m *IntegrationAPI) GetKey(ctx context.Context, appId string, integId string) (*svix.IntegrationKeyOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetKey", Args: []interface{}{ctx, appId, integId}})
+	m.mu.Unlock()
+	if m.GetKeyFunc == nil {
+		panic("svixmock: IntegrationAPI.GetKey called but GetKeyFunc is not set")
+	}
+	return m.GetKeyFunc(ctx, appId, integId)
+}
+
+func (m *IntegrationAPI) RotateKey(ctx context.Context, appId string, integId string) (*svix.IntegrationKeyOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "RotateKey", Args: []interface{}{ctx, appId, integId}})
+	m.mu.Unlock()
+	if m.RotateKeyFunc == nil {
+		panic("svixmock: IntegrationAPI.RotateKey called but RotateKeyFunc is not set")
+	}
+	return m.RotateKeyFunc(ctx, appId, integId)
+}
+
+func (m *IntegrationAPI) RotateKeyWithOptions(ctx context.Context, appId string, integId string, options *svix.PostOptions) (*svix.IntegrationKeyOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "RotateKeyWithOptions", Args: []interface{}{ctx, appId, integId, options}})
+	m.mu.Unlock()
+	if m.RotateKeyWithOptionsFunc == nil {
+		panic("svixmock: IntegrationAPI.RotateKeyWithOptions called but RotateKeyWithOptionsFunc is not set")
+	}
+	return m.RotateKeyWithOptionsFunc(ctx, appId, integId, options)
+}
+
+// MessageAPI is a mock implementation of svix.MessageAPI.
+// Calling a method whose Func field is nil panics.
+type MessageAPI struct {
+	ListFunc              func(ctx context.Context, appId string, options *svix.MessageListOptions) (*svix.ListResponseMessageOut, error)
+	CreateFunc            func(ctx context.Context, appId string, messageIn *svix.MessageIn) (*svix.MessageOut, error)
+	CreateWithOptionsFunc func(ctx context.Context, appId string, messageIn *svix.MessageIn, options *svix.PostOptions) (*svix.MessageOut, error)
+	GetFunc               func(ctx context.Context, appId string, msgId string) (*svix.MessageOut, error)
+	ExpungeContentFunc    func(ctx context.Context, appId string, msgId string) error
+	CreateRawFunc         func(ctx context.Context, appId string, messageIn *svix.RawMessageIn, options *svix.PostOptions) (*svix.RawMessageOut, error)
+	GetRawFunc            func(ctx context.Context, appId string, msgId string) (*svix.RawMessageOut, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *MessageAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *MessageAPI) List(ctx context.Context, appId string, options *svix.MessageListOptions) (*svix.ListResponseMessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, appId, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: MessageAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, appId, options)
+}
+
+func (m *MessageAPI) Create(ctx context.Context, appId string, messageIn *svix.MessageIn) (*svix.MessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Create", Args: []interface{}{ctx, appId, messageIn}})
+	m.mu.Unlock()
+	if m.CreateFunc == nil {
+		panic("svixmock: MessageAPI.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(ctx, appId, messageIn)
+}
+
+func (m *MessageAPI) CreateWithOptions(ctx context.Context, appId string, messageIn *svix.MessageIn, options *svix.PostOptions) (*svix.MessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "CreateWithOptions", Args: []interface{}{ctx, appId, messageIn, options}})
+	m.mu.Unlock()
+	if m.CreateWithOptionsFunc == nil {
+		panic("svixmock: MessageAPI.CreateWithOptions called but CreateWithOptionsFunc is not set")
+	}
+	return m.CreateWithOptionsFunc(ctx, appId, messageIn, options)
+}
+
+func (m *MessageAPI) Get(ctx context.Context, appId string, msgId string) (*svix.MessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, appId, msgId}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: MessageAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, appId, msgId)
+}
+
+func (m *MessageAPI) ExpungeContent(ctx context.Context, appId string, msgId string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ExpungeContent", Args: []interface{}{ctx, appId, msgId}})
+	m.mu.Unlock()
+	if m.ExpungeContentFunc == nil {
+		panic("svixmock: MessageAPI.ExpungeContent called but ExpungeContentFunc is not set")
+	}
+	return m.ExpungeContentFunc(ctx, appId, msgId)
+}
+
+func (m *MessageAPI) CreateRaw(ctx context.Context, appId string, messageIn *svix.RawMessageIn, options *svix.PostOptions) (*svix.RawMessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "CreateRaw", Args: []interface{}{ctx, appId, messageIn, options}})
+	m.mu.Unlock()
+	if m.CreateRawFunc == nil {
+		panic("svixmock: MessageAPI.CreateRaw called but CreateRawFunc is not set")
+	}
+	return m.CreateRawFunc(ctx, appId, messageIn, options)
+}
+
+func (m *MessageAPI) GetRaw(ctx context.Context, appId string, msgId string) (*svix.RawMessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetRaw", Args: []interface{}{ctx, appId, msgId}})
+	m.mu.Unlock()
+	if m.GetRawFunc == nil {
+		panic("svixmock: MessageAPI.GetRaw called but GetRawFunc is not set")
+	}
+	return m.GetRawFunc(ctx, appId, msgId)
+}
+
+// MessageAttemptAPI is a mock implementation of svix.MessageAttemptAPI.
+// Calling a method whose Func field is nil panics.
+type MessageAttemptAPI struct {
+	ListFunc                      func(ctx context.Context, appId string, msgId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptOut, error)
+	ListByMsgFunc                 func(ctx context.Context, appId string, msgId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptOut, error)
+	ListByEndpointFunc            func(ctx context.Context, appId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptOut, error)
+	GetFunc                       func(ctx context.Context, appId string, msgId string, attemptID string) (*svix.MessageAttemptOut, error)
+	GetHeadersFunc                func(ctx context.Context, appId string, msgId string, attemptId string) (*svix.MessageAttemptHeadersOut, error)
+	ResendFunc                    func(ctx context.Context, appId string, msgId string, endpointId string) error
+	ResendWithOptionsFunc         func(ctx context.Context, appId string, msgId string, endpointId string, options *svix.PostOptions) error
+	ListAttemptedMessagesFunc     func(ctx context.Context, appId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseEndpointMessageOut, error)
+	ListAttemptedMessagesRawFunc  func(ctx context.Context, appId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseRawEndpointMessageOut, error)
+	ListAttemptedDestinationsFunc func(ctx context.Context, appId string, msgId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageEndpointOut, error)
+	ListAttemptsForEndpointFunc   func(ctx context.Context, appId string, msgId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptEndpointOut, error)
+	ExpungeContentFunc            func(ctx context.Context, appId string, msgId string, attemptId string) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *MessageAttemptAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *MessageAttemptAPI) List(ctx context.Context, appId string, msgId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "List", Args: []interface{}{ctx, appId, msgId, options}})
+	m.mu.Unlock()
+	if m.ListFunc == nil {
+		panic("svixmock: MessageAttemptAPI.List called but ListFunc is not set")
+	}
+	return m.ListFunc(ctx, appId, msgId, options)
+}
+
+func (m *MessageAttemptAPI) ListByMsg(ctx context.Context, appId string, msgId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListByMsg", Args: []interface{}{ctx, appId, msgId, options}})
+	m.mu.Unlock()
+	if m.ListByMsgFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ListByMsg called but ListByMsgFunc is not set")
+	}
+	return m.ListByMsgFunc(ctx, appId, msgId, options)
+}
+
+func (m *MessageAttemptAPI) ListByEndpoint(ctx context.Context, appId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListByEndpoint", Args: []interface{}{ctx, appId, endpointId, options}})
+	m.mu.Unlock()
+	if m.ListByEndpointFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ListByEndpoint called but ListByEndpointFunc is not set")
+	}
+	return m.ListByEndpointFunc(ctx, appId, endpointId, options)
+}
+
+func (m *MessageAttemptAPI) Get(ctx context.Context, appId string, msgId string, attemptID string) (*svix.MessageAttemptOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Get", Args: []interface{}{ctx, appId, msgId, attemptID}})
+	m.mu.Unlock()
+	if m.GetFunc == nil {
+		panic("svixmock: MessageAttemptAPI.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(ctx, appId, msgId, attemptID)
+}
+
+func (m *MessageAttemptAPI) GetHeaders(ctx context.Context, appId string, msgId string, attemptId string) (*svix.MessageAttemptHeadersOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "GetHeaders", Args: []interface{}{ctx, appId, msgId, attemptId}})
+	m.mu.Unlock()
+	if m.GetHeadersFunc == nil {
+		panic("svixmock: MessageAttemptAPI.GetHeaders called but GetHeadersFunc is not set")
+	}
+	return m.GetHeadersFunc(ctx, appId, msgId, attemptId)
+}
+
+func (m *MessageAttemptAPI) Resend(ctx context.Context, appId string, msgId string, endpointId string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "Resend", Args: []interface{}{ctx, appId, msgId, endpointId}})
+	m.mu.Unlock()
+	if m.ResendFunc == nil {
+		panic("svixmock: MessageAttemptAPI.Resend called but ResendFunc is not set")
+	}
+	return m.ResendFunc(ctx, appId, msgId, endpointId)
+}
+
+func (m *MessageAttemptAPI) ResendWithOptions(ctx context.Context, appId string, msgId string, endpointId string, options *svix.PostOptions) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ResendWithOptions", Args: []interface{}{ctx, appId, msgId, endpointId, options}})
+	m.mu.Unlock()
+	if m.ResendWithOptionsFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ResendWithOptions called but ResendWithOptionsFunc is not set")
+	}
+	return m.ResendWithOptionsFunc(ctx, appId, msgId, endpointId, options)
+}
+
+func (m *MessageAttemptAPI) ListAttemptedMessages(ctx context.Context, appId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseEndpointMessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListAttemptedMessages", Args: []interface{}{ctx, appId, endpointId, options}})
+	m.mu.Unlock()
+	if m.ListAttemptedMessagesFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ListAttemptedMessages called but ListAttemptedMessagesFunc is not set")
+	}
+	return m.ListAttemptedMessagesFunc(ctx, appId, endpointId, options)
+}
+
+func (m *MessageAttemptAPI) ListAttemptedMessagesRaw(ctx context.Context, appId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseRawEndpointMessageOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListAttemptedMessagesRaw", Args: []interface{}{ctx, appId, endpointId, options}})
+	m.mu.Unlock()
+	if m.ListAttemptedMessagesRawFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ListAttemptedMessagesRaw called but ListAttemptedMessagesRawFunc is not set")
+	}
+	return m.ListAttemptedMessagesRawFunc(ctx, appId, endpointId, options)
+}
+
+func (m *MessageAttemptAPI) ListAttemptedDestinations(ctx context.Context, appId string, msgId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageEndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListAttemptedDestinations", Args: []interface{}{ctx, appId, msgId, options}})
+	m.mu.Unlock()
+	if m.ListAttemptedDestinationsFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ListAttemptedDestinations called but ListAttemptedDestinationsFunc is not set")
+	}
+	return m.ListAttemptedDestinationsFunc(ctx, appId, msgId, options)
+}
+
+func (m *MessageAttemptAPI) ListAttemptsForEndpoint(ctx context.Context, appId string, msgId string, endpointId string, options *svix.MessageAttemptListOptions) (*svix.ListResponseMessageAttemptEndpointOut, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ListAttemptsForEndpoint", Args: []interface{}{ctx, appId, msgId, endpointId, options}})
+	m.mu.Unlock()
+	if m.ListAttemptsForEndpointFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ListAttemptsForEndpoint called but ListAttemptsForEndpointFunc is not set")
+	}
+	return m.ListAttemptsForEndpointFunc(ctx, appId, msgId, endpointId, options)
+}
+
+func (m *MessageAttemptAPI) ExpungeContent(ctx context.Context, appId string, msgId string, attemptId string) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "ExpungeContent", Args: []interface{}{ctx, appId, msgId, attemptId}})
+	m.mu.Unlock()
+	if m.ExpungeContentFunc == nil {
+		panic("svixmock: MessageAttemptAPI.ExpungeContent called but ExpungeContentFunc is not set")
+	}
+	return m.ExpungeContentFunc(ctx, appId, msgId, attemptId)
+}
+
+// StatisticsAPI is a mock implementation of svix.StatisticsAPI.
+// Calling a method whose Func field is nil panics.
+type StatisticsAPI struct {
+	AppAttemptsFunc      func(ctx context.Context, appId string, options *svix.AttemptStatisticsOptions) (*svix.AttemptStatisticsResponse, error)
+	EndpointAttemptsFunc func(ctx context.Context, appId string, endpointId string, options *svix.AttemptStatisticsOptions) (*svix.AttemptStatisticsResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock so far.
+func (m *StatisticsAPI) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+func (m *StatisticsAPI) AppAttempts(ctx context.Context, appId string, options *svix.AttemptStatisticsOptions) (*svix.AttemptStatisticsResponse, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "AppAttempts", Args: []interface{}{ctx, appId, options}})
+	m.mu.Unlock()
+	if m.AppAttemptsFunc == nil {
+		panic("svixmock: StatisticsAPI.AppAttempts called but AppAttemptsFunc is not set")
+	}
+	return m.AppAttemptsFunc(ctx, appId, options)
+}
+
+func (m *StatisticsAPI) EndpointAttempts(ctx context.Context, appId string, endpointId string, options *svix.AttemptStatisticsOptions) (*svix.AttemptStatisticsResponse, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: "EndpointAttempts", Args: []interface{}{ctx, appId, endpointId, options}})
+	m.mu.Unlock()
+	if m.EndpointAttemptsFunc == nil {
+		panic("svixmock: StatisticsAPI.EndpointAttempts called but EndpointAttemptsFunc is not set")
+	}
+	return m.EndpointAttemptsFunc(ctx, appId, endpointId, options)
+}
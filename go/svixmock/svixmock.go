@@ -0,0 +1,19 @@
+// Package svixmock provides mock implementations of the resource interfaces
+// of the svix package, for use with svix.SvixAPI.
+//
+//	apps := &svixmock.ApplicationAPI{
+//		GetFunc: func(ctx context.Context, appId string) (*svix.ApplicationOut, error) {
+//			return &svix.ApplicationOut{Id: appId, Name: "Test"}, nil
+//		},
+//	}
+//	api := &svix.SvixAPI{Application: apps}
+//
+// The mocks are generated from svix/interfaces.go; run `go generate` in the
+// svix package after changing the interfaces.
+package svixmock
+
+// Call is a single recorded call to a mock.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
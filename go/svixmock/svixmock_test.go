@@ -0,0 +1,34 @@
+package svixmock_test
+
+import (
+	"context"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixmock"
+)
+
+func TestMockRecordsCalls(t *testing.T) {
+	apps := &svixmock.ApplicationAPI{
+		GetFunc: func(ctx context.Context, appId string) (*svix.ApplicationOut, error) {
+			return &svix.ApplicationOut{Id: appId, Name: "Test"}, nil
+		},
+	}
+	api := &svix.SvixAPI{Application: apps}
+
+	app, err := api.Application.Get(context.Background(), "app_1")
+	if err != nil || app.Name != "Test" {
+		t.Fatalf("unexpected result %+v (%v)", app, err)
+	}
+	calls := apps.Calls()
+	if len(calls) != 1 || calls[0].Method != "Get" || calls[0].Args[1] != "app_1" {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for an unset method")
+		}
+	}()
+	_ = api.Application.Delete(context.Background(), "app_1")
+}
@@ -0,0 +1,356 @@
+package svixtest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type app struct {
+	out          openapi.ApplicationOut
+	endpoints    []*endpoint
+	messages     []*message
+	integrations []*integration
+	tokens       []*appToken
+}
+
+// appToken is a token issued for the application portal or dashboard of an
+// application.
+type appToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// expired reports whether the token was expired with expire-all. Callers
+// must hold s.mu.
+func (t *appToken) expired() bool {
+	return !t.expiresAt.IsZero() && !time.Now().Before(t.expiresAt)
+}
+
+// findApp looks an application up by id or uid. Callers must hold s.mu.
+func (s *Server) findApp(idOrUid string) *app {
+	for _, a := range s.apps {
+		if a.out.Id == idOrUid || (a.out.Uid.Get() != nil && *a.out.Uid.Get() == idOrUid) {
+			return a
+		}
+	}
+	return nil
+}
+
+// lookupApp is like findApp but writes a 404 response when there's no match.
+func (s *Server) lookupApp(w http.ResponseWriter, idOrUid string) *app {
+	a := s.findApp(idOrUid)
+	if a == nil {
+		writeNotFound(w)
+	}
+	return a
+}
+
+func validUid(uid openapi.NullableString) bool {
+	if uid.Get() == nil {
+		return true
+	}
+	value := *uid.Get()
+	if value == "" || len(value) > 256 || strings.HasPrefix(value, "app_") || strings.HasPrefix(value, "ep_") {
+		return false
+	}
+	for _, c := range value {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) listApplications(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []openapi.ApplicationOut
+	for _, a := range s.apps {
+		items = append(items, a.out)
+	}
+	resp, ok := paginate(w, r, items, func(a openapi.ApplicationOut) string { return a.Id }, false)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) createApplication(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.ApplicationIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if in.Uid.Get() != nil {
+		if existing := s.findApp(*in.Uid.Get()); existing != nil {
+			if queryBool(r, "get_if_exists", false) {
+				writeJSON(w, http.StatusOK, existing.out)
+				return
+			}
+			writeConflict(w, "An application with this uid already exists")
+			return
+		}
+	}
+	a, ok := s.newApp(w, &in)
+	if ok {
+		writeJSON(w, http.StatusCreated, a.out)
+	}
+}
+
+// newApp validates and stores a new application. Callers must hold s.mu.
+func (s *Server) newApp(w http.ResponseWriter, in *openapi.ApplicationIn) (*app, bool) {
+	if in.Name == "" {
+		writeValidationError(w, "name", "field required")
+		return nil, false
+	}
+	if !validUid(in.Uid) {
+		writeValidationError(w, "uid", "invalid uid")
+		return nil, false
+	}
+	now := time.Now().UTC()
+	a := &app{
+		out: openapi.ApplicationOut{
+			Id:        newId("app"),
+			Name:      in.Name,
+			Metadata:  map[string]string{},
+			RateLimit: in.RateLimit,
+			Uid:       in.Uid,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	if in.Metadata != nil {
+		a.out.Metadata = *in.Metadata
+	}
+	s.apps = append(s.apps, a)
+	return a, true
+}
+
+func (s *Server) getApplication(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a := s.lookupApp(w, params["app_id"]); a != nil {
+		writeJSON(w, http.StatusOK, a.out)
+	}
+}
+
+func (s *Server) updateApplication(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.ApplicationIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	if in.Name == "" {
+		writeValidationError(w, "name", "field required")
+		return
+	}
+	if !validUid(in.Uid) {
+		writeValidationError(w, "uid", "invalid uid")
+		return
+	}
+	if in.Uid.Get() != nil {
+		if other := s.findApp(*in.Uid.Get()); other != nil && other != a {
+			writeConflict(w, "An application with this uid already exists")
+			return
+		}
+	}
+	a.out.Name = in.Name
+	a.out.RateLimit = in.RateLimit
+	a.out.Uid = in.Uid
+	a.out.Metadata = map[string]string{}
+	if in.Metadata != nil {
+		a.out.Metadata = *in.Metadata
+	}
+	a.out.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, a.out)
+}
+
+func (s *Server) patchApplication(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.ApplicationPatch
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	if in.Name != nil {
+		a.out.Name = *in.Name
+	}
+	if in.Metadata != nil {
+		a.out.Metadata = *in.Metadata
+	}
+	if in.RateLimit.IsSet() {
+		a.out.RateLimit = in.RateLimit
+	}
+	if in.Uid.IsSet() {
+		if !validUid(in.Uid) {
+			writeValidationError(w, "uid", "invalid uid")
+			return
+		}
+		a.out.Uid = in.Uid
+	}
+	a.out.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, a.out)
+}
+
+func (s *Server) deleteApplication(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	for i, existing := range s.apps {
+		if existing == a {
+			s.apps = append(s.apps[:i], s.apps[i+1:]...)
+			break
+		}
+	}
+	writeEmpty(w, http.StatusNoContent)
+}
+
+func (s *Server) appPortalAccess(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.AppPortalAccessIn
+	if r.ContentLength != 0 && !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	token, oneTimeToken := s.newAppToken(a)
+	writeJSON(w, http.StatusOK, openapi.AppPortalAccessOut{
+		Token: token,
+		Url:   "https://app.svix.com/login#key=" + oneTimeToken,
+	})
+}
+
+func (s *Server) dashboardAccess(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	token, oneTimeToken := s.newAppToken(a)
+	writeJSON(w, http.StatusOK, openapi.DashboardAccessOut{
+		Token: token,
+		Url:   "https://app.svix.com/login#key=" + oneTimeToken,
+	})
+}
+
+// newAppToken issues a token for the application, along with the one-time
+// token of its magic link. Callers must hold s.mu.
+func (s *Server) newAppToken(a *app) (string, string) {
+	token := newToken("appsk_")
+	oneTimeToken := newToken("")
+	a.tokens = append(a.tokens, &appToken{token: token})
+	s.oneTimeTokens[oneTimeToken] = token
+	return token, oneTimeToken
+}
+
+func (s *Server) exchangeOneTimeToken(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.OneTimeTokenIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.oneTimeTokens[in.OneTimeToken]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication_failed", "Invalid token")
+		return
+	}
+	delete(s.oneTimeTokens, in.OneTimeToken)
+	writeJSON(w, http.StatusOK, openapi.OneTimeTokenOut{Token: token})
+}
+
+func (s *Server) expireAllTokens(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.ApplicationTokenExpireIn
+	if r.ContentLength != 0 && !decodeBody(w, r, &in) {
+		return
+	}
+	var expiry int64
+	if in.Expiry.Get() != nil {
+		expiry = *in.Expiry.Get()
+	}
+	if expiry < 0 {
+		writeValidationError(w, "expiry", "ensure this value is greater than or equal to 0")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	expiresAt := time.Now().Add(time.Duration(expiry) * time.Second)
+	for _, t := range a.tokens {
+		if t.expiresAt.IsZero() || t.expiresAt.After(expiresAt) {
+			t.expiresAt = expiresAt
+		}
+	}
+	writeEmpty(w, http.StatusNoContent)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	writeEmpty(w, http.StatusNoContent)
+}
+
+// newTask records a background task that has already finished. Callers must
+// hold s.mu.
+func (s *Server) newTask(task openapi.BackgroundTaskType, data map[string]interface{}) *openapi.BackgroundTaskOut {
+	t := &openapi.BackgroundTaskOut{
+		Id:     newId("qtask"),
+		Status: openapi.BACKGROUNDTASKSTATUS_FINISHED,
+		Task:   task,
+		Data:   data,
+	}
+	s.tasks = append(s.tasks, t)
+	return t
+}
+
+func (s *Server) listBackgroundTasks(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+	var items []openapi.BackgroundTaskOut
+	for _, t := range s.tasks {
+		if status := query.Get("status"); status != "" && string(t.Status) != status {
+			continue
+		}
+		if task := query.Get("task"); task != "" && string(t.Task) != task {
+			continue
+		}
+		items = append(items, *t)
+	}
+	resp, ok := paginate(w, r, items, func(t openapi.BackgroundTaskOut) string { return t.Id }, false)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) getBackgroundTask(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		if t.Id == params["task_id"] {
+			writeJSON(w, http.StatusOK, t)
+			return
+		}
+	}
+	writeNotFound(w)
+}
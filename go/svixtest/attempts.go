@@ -0,0 +1,307 @@
+package svixtest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+// attemptFilter holds the filters shared by the attempt listing routes.
+type attemptFilter struct {
+	messageFilter
+	status          *openapi.MessageStatus
+	statusCodeClass *int32
+	endpointId      string
+}
+
+func parseAttemptFilter(w http.ResponseWriter, r *http.Request) (*attemptFilter, bool) {
+	mf, ok := parseMessageFilter(w, r)
+	if !ok {
+		return nil, false
+	}
+	f := &attemptFilter{
+		messageFilter: *mf,
+		endpointId:    r.URL.Query().Get("endpoint_id"),
+	}
+	if raw := r.URL.Query().Get("status"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "validation", "invalid status")
+			return nil, false
+		}
+		status := openapi.MessageStatus(n)
+		f.status = &status
+	}
+	if raw := r.URL.Query().Get("status_code_class"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "validation", "invalid status_code_class")
+			return nil, false
+		}
+		class := int32(n)
+		f.statusCodeClass = &class
+	}
+	return f, true
+}
+
+func (f *attemptFilter) matchAttempt(msg *message, attempt *openapi.MessageAttemptOut) bool {
+	if !f.match(&msg.out, attempt.Timestamp) {
+		return false
+	}
+	if f.status != nil && attempt.Status != *f.status {
+		return false
+	}
+	if f.statusCodeClass != nil && attempt.ResponseStatusCode/100*100 != *f.statusCodeClass {
+		return false
+	}
+	if f.endpointId != "" && attempt.EndpointId != f.endpointId {
+		return false
+	}
+	return true
+}
+
+func attemptId(a openapi.MessageAttemptOut) string {
+	return a.Id
+}
+
+func (s *Server) listAttemptsByEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	filter, ok := parseAttemptFilter(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	var items []openapi.MessageAttemptOut
+	for _, msg := range a.messages {
+		for _, attempt := range msg.attempts {
+			if attempt.EndpointId == ep.out.Id && filter.matchAttempt(msg, attempt) {
+				items = append(items, *attempt)
+			}
+		}
+	}
+	sortAttempts(items)
+	resp, ok := paginate(w, r, items, attemptId, true)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) listAttemptsByMsg(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	filter, ok := parseAttemptFilter(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	if filter.endpointId != "" {
+		if ep := a.findEndpoint(filter.endpointId); ep != nil {
+			filter.endpointId = ep.out.Id
+		}
+	}
+	var items []openapi.MessageAttemptOut
+	for _, attempt := range msg.attempts {
+		if filter.matchAttempt(msg, attempt) {
+			items = append(items, *attempt)
+		}
+	}
+	resp, ok := paginate(w, r, items, attemptId, true)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// sortAttempts sorts attempts in creation order, which is the order of their ids.
+func sortAttempts(items []openapi.MessageAttemptOut) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+}
+
+func (msg *message) findAttempt(id string) *openapi.MessageAttemptOut {
+	for _, attempt := range msg.attempts {
+		if attempt.Id == id {
+			return attempt
+		}
+	}
+	return nil
+}
+
+func (s *Server) getAttempt(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	if attempt := msg.findAttempt(params["attempt_id"]); attempt != nil {
+		writeJSON(w, http.StatusOK, attempt)
+		return
+	}
+	writeNotFound(w)
+}
+
+func (s *Server) getAttemptHeaders(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	if attempt := msg.findAttempt(params["attempt_id"]); attempt != nil {
+		out := openapi.MessageAttemptHeadersOut{
+			SentHeaders: map[string]string{},
+			Sensitive:   []string{},
+		}
+		for k, v := range msg.sentHeaders[attempt.Id] {
+			if sensitiveHeaders[strings.ToLower(k)] {
+				out.Sensitive = append(out.Sensitive, k)
+			} else {
+				out.SentHeaders[k] = v
+			}
+		}
+		sort.Strings(out.Sensitive)
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+	writeNotFound(w)
+}
+
+func (s *Server) expungeAttemptContent(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	if attempt := msg.findAttempt(params["attempt_id"]); attempt != nil {
+		attempt.Response = ""
+		writeEmpty(w, http.StatusNoContent)
+		return
+	}
+	writeNotFound(w)
+}
+
+func (s *Server) resendAttempt(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	ep := a.findEndpoint(params["endpoint_id"])
+	if ep == nil {
+		writeNotFound(w)
+		return
+	}
+	s.dispatch(a, msg, []*endpoint{ep}, openapi.MESSAGEATTEMPTTRIGGERTYPE_Manual)
+	writeEmpty(w, http.StatusAccepted)
+}
+
+func (s *Server) listAttemptedMessages(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	filter, ok := parseAttemptFilter(w, r)
+	if !ok {
+		return
+	}
+	withContent := queryBool(r, "with_content", true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	var items []openapi.EndpointMessageOut
+	for _, msg := range a.messages {
+		attempt := msg.latestAttempt(ep.out.Id)
+		if attempt == nil || !filter.match(&msg.out, msg.out.Timestamp) {
+			continue
+		}
+		if filter.status != nil && attempt.Status != *filter.status {
+			continue
+		}
+		out := msg.content(withContent)
+		items = append(items, openapi.EndpointMessageOut{
+			Id:        out.Id,
+			EventType: out.EventType,
+			EventId:   out.EventId,
+			Channels:  out.Channels,
+			Payload:   out.Payload,
+			Timestamp: out.Timestamp,
+			Status:    attempt.Status,
+		})
+	}
+	resp, ok := paginate(w, r, items, func(m openapi.EndpointMessageOut) string { return m.Id }, true)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) listAttemptedDestinations(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	var items []openapi.MessageEndpointOut
+	for _, ep := range a.endpoints {
+		attempt := msg.latestAttempt(ep.out.Id)
+		if attempt == nil {
+			continue
+		}
+		items = append(items, openapi.MessageEndpointOut{
+			Id:          ep.out.Id,
+			Url:         ep.out.Url,
+			Description: ep.out.Description,
+			Channels:    ep.out.Channels,
+			FilterTypes: ep.out.FilterTypes,
+			Disabled:    ep.out.Disabled,
+			RateLimit:   ep.out.RateLimit,
+			Uid:         ep.out.Uid,
+			Version:     ep.out.Version,
+			CreatedAt:   ep.out.CreatedAt,
+			UpdatedAt:   ep.out.UpdatedAt,
+			Status:      attempt.Status,
+		})
+	}
+	resp, ok := paginate(w, r, items, func(ep openapi.MessageEndpointOut) string { return ep.Id }, false)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) listAttemptsForEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	filter, ok := parseAttemptFilter(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, msg := s.lookupMessage(w, params)
+	if msg == nil {
+		return
+	}
+	ep := a.findEndpoint(params["endpoint_id"])
+	if ep == nil {
+		writeNotFound(w)
+		return
+	}
+	var items []openapi.MessageAttemptEndpointOut
+	for _, attempt := range msg.attempts {
+		if attempt.EndpointId == ep.out.Id && filter.matchAttempt(msg, attempt) {
+			items = append(items, openapi.MessageAttemptEndpointOut(*attempt))
+		}
+	}
+	resp, ok := paginate(w, r, items, func(a openapi.MessageAttemptEndpointOut) string { return a.Id }, true)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
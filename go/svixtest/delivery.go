@@ -0,0 +1,183 @@
+package svixtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+// Delivery describes a single attempt at delivering a message to an endpoint.
+type Delivery struct {
+	AppId      string
+	EndpointId string
+	Url        string
+	// The endpoint's signing secret, in `whsec_` form.
+	Secret    string
+	MessageId string
+	EventType string
+	Payload   []byte
+	// Custom headers configured on the endpoint.
+	Headers map[string]string
+	// The time of the attempt, and the signature of the payload for it,
+	// sent in the svix-timestamp and svix-signature headers.
+	Timestamp time.Time
+	Signature string
+}
+
+// DeliverFunc performs a delivery and returns the response status code and
+// body. A status code of 0 means the request could not be made at all.
+type DeliverFunc func(delivery *Delivery) (statusCode int, response string)
+
+// HTTPDelivery returns a DeliverFunc that POSTs signed webhooks to the
+// endpoint URLs, the way Svix does. Receivers can verify them with
+// svix.Webhook using the endpoint's secret. The headers are those reported
+// by MessageAttempt.GetHeaders for the attempt.
+func HTTPDelivery(client *http.Client) DeliverFunc {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return func(d *Delivery) (int, string) {
+		req, err := http.NewRequest(http.MethodPost, d.Url, bytes.NewReader(d.Payload))
+		if err != nil {
+			return 0, err.Error()
+		}
+		for k, v := range d.Headers {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("svix-id", d.MessageId)
+		req.Header.Set("svix-timestamp", strconv.FormatInt(d.Timestamp.Unix(), 10))
+		req.Header.Set("svix-signature", d.Signature)
+
+		res, err := client.Do(req)
+		if err != nil {
+			return 0, err.Error()
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return res.StatusCode, string(body)
+	}
+}
+
+// matches reports whether a message should be sent to the endpoint.
+func (ep *endpoint) matches(msg *openapi.MessageOut) bool {
+	if ep.out.Disabled != nil && *ep.out.Disabled {
+		return false
+	}
+	if len(ep.out.FilterTypes) > 0 && !contains(ep.out.FilterTypes, msg.EventType) {
+		return false
+	}
+	if len(ep.out.Channels) > 0 {
+		for _, channel := range msg.Channels {
+			if contains(ep.out.Channels, channel) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch creates an attempt of msg for every given endpoint and performs
+// the deliveries. Callers must hold s.mu.
+func (s *Server) dispatch(a *app, msg *message, endpoints []*endpoint, trigger openapi.MessageAttemptTriggerType) {
+	for _, ep := range endpoints {
+		attempt := &openapi.MessageAttemptOut{
+			Id:          newId("atmpt"),
+			EndpointId:  ep.out.Id,
+			MsgId:       msg.out.Id,
+			Url:         ep.out.Url,
+			Timestamp:   time.Now().UTC(),
+			TriggerType: trigger,
+		}
+		msg.attempts = append(msg.attempts, attempt)
+		sent := msg.recordHeaders(attempt, ep)
+
+		if s.options.Deliver == nil {
+			attempt.Status = openapi.MESSAGESTATUS_Success
+			attempt.ResponseStatusCode = http.StatusOK
+			continue
+		}
+
+		attempt.Status = openapi.MESSAGESTATUS_Sending
+		headers := make(map[string]string, len(ep.headers))
+		for k, v := range ep.headers {
+			headers[k] = v
+		}
+		delivery := &Delivery{
+			AppId:      a.out.Id,
+			EndpointId: ep.out.Id,
+			Url:        ep.out.Url,
+			Secret:     ep.secret,
+			MessageId:  msg.out.Id,
+			EventType:  msg.out.EventType,
+			Payload:    msg.body(),
+			Headers:    headers,
+			Timestamp:  attempt.Timestamp,
+			Signature:  sent["svix-signature"],
+		}
+		s.deliveries.Add(1)
+		go func() {
+			defer s.deliveries.Done()
+			status, response := s.options.Deliver(delivery)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			attempt.ResponseStatusCode = int32(status)
+			attempt.Response = response
+			if status >= 200 && status < 300 {
+				attempt.Status = openapi.MESSAGESTATUS_Success
+			} else {
+				attempt.Status = openapi.MESSAGESTATUS_Fail
+			}
+		}()
+	}
+}
+
+// recordHeaders records and returns the headers sent with an attempt: the
+// custom headers of the endpoint and the webhook headers, signed once for
+// the time of the attempt.
+func (m *message) recordHeaders(attempt *openapi.MessageAttemptOut, ep *endpoint) map[string]string {
+	headers := make(map[string]string, len(ep.headers)+4)
+	for k, v := range ep.headers {
+		headers[k] = v
+	}
+	headers["content-type"] = "application/json"
+	headers["svix-id"] = m.out.Id
+	headers["svix-timestamp"] = strconv.FormatInt(attempt.Timestamp.Unix(), 10)
+	if wh, err := svix.NewWebhook(ep.secret); err == nil {
+		if signature, err := wh.Sign(m.out.Id, attempt.Timestamp, m.body()); err == nil {
+			headers["svix-signature"] = signature
+		}
+	}
+	if m.sentHeaders == nil {
+		m.sentHeaders = make(map[string]map[string]string)
+	}
+	m.sentHeaders[attempt.Id] = headers
+	return headers
+}
+
+// body returns the payload as sent to endpoints.
+func (m *message) body() []byte {
+	b, err := json.Marshal(m.out.Payload)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	return b
+}
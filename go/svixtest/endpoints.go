@@ -0,0 +1,513 @@
+package svixtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type endpoint struct {
+	out            openapi.EndpointOut
+	secret         string
+	headers        map[string]string
+	transformation openapi.EndpointTransformationOut
+}
+
+// Headers whose values are never returned by the API.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"x-api-key":           true,
+}
+
+func (a *app) findEndpoint(idOrUid string) *endpoint {
+	for _, ep := range a.endpoints {
+		if ep.out.Id == idOrUid || (ep.out.Uid.Get() != nil && *ep.out.Uid.Get() == idOrUid) {
+			return ep
+		}
+	}
+	return nil
+}
+
+// lookupEndpoint finds the application and endpoint named by the path
+// parameters, writing a 404 response when either doesn't exist. Callers must
+// hold s.mu.
+func (s *Server) lookupEndpoint(w http.ResponseWriter, params map[string]string) (*app, *endpoint) {
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return nil, nil
+	}
+	ep := a.findEndpoint(params["endpoint_id"])
+	if ep == nil {
+		writeNotFound(w)
+		return nil, nil
+	}
+	return a, ep
+}
+
+func validEndpointUrl(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validSecret(secret string) bool {
+	if !strings.HasPrefix(secret, "whsec_") {
+		return false
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	return err == nil && len(key) >= 24 && len(key) <= 64
+}
+
+// validateFilterTypes checks that all filter types exist. Callers must hold s.mu.
+func (s *Server) validateFilterTypes(w http.ResponseWriter, filterTypes []string) bool {
+	for _, name := range filterTypes {
+		if _, ok := s.eventTypes[name]; !ok {
+			writeValidationError(w, "filterTypes", "Event type "+name+" does not exist")
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) listEndpoints(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	var items []openapi.EndpointOut
+	for _, ep := range a.endpoints {
+		items = append(items, ep.out)
+	}
+	resp, ok := paginate(w, r, items, func(ep openapi.EndpointOut) string { return ep.Id }, false)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) createEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	if !validEndpointUrl(in.Url) {
+		writeValidationError(w, "url", "invalid or missing URL scheme")
+		return
+	}
+	if !validUid(in.Uid) {
+		writeValidationError(w, "uid", "invalid uid")
+		return
+	}
+	if in.Uid.Get() != nil && a.findEndpoint(*in.Uid.Get()) != nil {
+		writeConflict(w, "An endpoint with this uid already exists")
+		return
+	}
+	if !s.validateFilterTypes(w, in.FilterTypes) {
+		return
+	}
+	secret := newSecret()
+	if in.Secret.Get() != nil {
+		if !validSecret(*in.Secret.Get()) {
+			writeValidationError(w, "secret", "invalid secret")
+			return
+		}
+		secret = *in.Secret.Get()
+	}
+
+	now := time.Now().UTC()
+	ep := &endpoint{
+		out: openapi.EndpointOut{
+			Id:          newId("ep"),
+			Url:         in.Url,
+			Channels:    in.Channels,
+			FilterTypes: in.FilterTypes,
+			Disabled:    in.Disabled,
+			Metadata:    map[string]string{},
+			RateLimit:   in.RateLimit,
+			Uid:         in.Uid,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		secret:  secret,
+		headers: map[string]string{},
+	}
+	if in.Description != nil {
+		ep.out.Description = *in.Description
+	}
+	if in.Metadata != nil {
+		ep.out.Metadata = *in.Metadata
+	}
+	if in.Version.Get() != nil {
+		ep.out.Version = *in.Version.Get()
+	}
+	if ep.out.Disabled == nil {
+		disabled := false
+		ep.out.Disabled = &disabled
+	}
+	a.endpoints = append(a.endpoints, ep)
+	writeJSON(w, http.StatusCreated, ep.out)
+}
+
+func (s *Server) getEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		writeJSON(w, http.StatusOK, ep.out)
+	}
+}
+
+func (s *Server) updateEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointUpdate
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	if !validEndpointUrl(in.Url) {
+		writeValidationError(w, "url", "invalid or missing URL scheme")
+		return
+	}
+	if !validUid(in.Uid) {
+		writeValidationError(w, "uid", "invalid uid")
+		return
+	}
+	if in.Uid.Get() != nil {
+		if other := a.findEndpoint(*in.Uid.Get()); other != nil && other != ep {
+			writeConflict(w, "An endpoint with this uid already exists")
+			return
+		}
+	}
+	if !s.validateFilterTypes(w, in.FilterTypes) {
+		return
+	}
+	ep.out.Url = in.Url
+	ep.out.Channels = in.Channels
+	ep.out.FilterTypes = in.FilterTypes
+	ep.out.RateLimit = in.RateLimit
+	ep.out.Uid = in.Uid
+	ep.out.Description = ""
+	if in.Description != nil {
+		ep.out.Description = *in.Description
+	}
+	disabled := in.Disabled != nil && *in.Disabled
+	ep.out.Disabled = &disabled
+	ep.out.Metadata = map[string]string{}
+	if in.Metadata != nil {
+		ep.out.Metadata = *in.Metadata
+	}
+	if in.Version.Get() != nil {
+		ep.out.Version = *in.Version.Get()
+	}
+	ep.out.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, ep.out)
+}
+
+func (s *Server) patchEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointPatch
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	if in.Url != nil {
+		if !validEndpointUrl(*in.Url) {
+			writeValidationError(w, "url", "invalid or missing URL scheme")
+			return
+		}
+		ep.out.Url = *in.Url
+	}
+	if in.FilterTypes != nil {
+		if !s.validateFilterTypes(w, in.FilterTypes) {
+			return
+		}
+		ep.out.FilterTypes = in.FilterTypes
+	}
+	if in.Secret.Get() != nil {
+		if !validSecret(*in.Secret.Get()) {
+			writeValidationError(w, "secret", "invalid secret")
+			return
+		}
+		ep.secret = *in.Secret.Get()
+	}
+	if in.Channels != nil {
+		ep.out.Channels = in.Channels
+	}
+	if in.Description != nil {
+		ep.out.Description = *in.Description
+	}
+	if in.Disabled != nil {
+		ep.out.Disabled = in.Disabled
+	}
+	if in.Metadata != nil {
+		ep.out.Metadata = *in.Metadata
+	}
+	if in.RateLimit.IsSet() {
+		ep.out.RateLimit = in.RateLimit
+	}
+	if in.Uid.IsSet() {
+		ep.out.Uid = in.Uid
+	}
+	if in.Version != nil {
+		ep.out.Version = *in.Version
+	}
+	ep.out.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, ep.out)
+}
+
+func (s *Server) deleteEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	for i, existing := range a.endpoints {
+		if existing == ep {
+			a.endpoints = append(a.endpoints[:i], a.endpoints[i+1:]...)
+			break
+		}
+	}
+	writeEmpty(w, http.StatusNoContent)
+}
+
+func (s *Server) getEndpointSecret(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		writeJSON(w, http.StatusOK, openapi.EndpointSecretOut{Key: ep.secret})
+	}
+}
+
+func (s *Server) rotateEndpointSecret(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointSecretRotateIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	secret := newSecret()
+	if in.Key.Get() != nil {
+		if !validSecret(*in.Key.Get()) {
+			writeValidationError(w, "key", "invalid secret")
+			return
+		}
+		secret = *in.Key.Get()
+	}
+	ep.secret = secret
+	writeEmpty(w, http.StatusNoContent)
+}
+
+func (ep *endpoint) headersOut() openapi.EndpointHeadersOut {
+	out := openapi.EndpointHeadersOut{
+		Headers:   map[string]string{},
+		Sensitive: []string{},
+	}
+	for k, v := range ep.headers {
+		if sensitiveHeaders[strings.ToLower(k)] {
+			out.Sensitive = append(out.Sensitive, k)
+		} else {
+			out.Headers[k] = v
+		}
+	}
+	return out
+}
+
+func (s *Server) getEndpointHeaders(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		writeJSON(w, http.StatusOK, ep.headersOut())
+	}
+}
+
+func (s *Server) updateEndpointHeaders(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointHeadersIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		ep.headers = map[string]string{}
+		for k, v := range in.Headers {
+			ep.headers[k] = v
+		}
+		writeEmpty(w, http.StatusNoContent)
+	}
+}
+
+func (s *Server) patchEndpointHeaders(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointHeadersPatchIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		for k, v := range in.Headers {
+			ep.headers[k] = v
+		}
+		writeEmpty(w, http.StatusNoContent)
+	}
+}
+
+func (s *Server) getEndpointStats(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	since, ok := queryTime(w, r, "since")
+	if !ok {
+		return
+	}
+	until, ok := queryTime(w, r, "until")
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	var stats openapi.EndpointStats
+	for _, msg := range a.messages {
+		if since != nil && msg.out.Timestamp.Before(*since) {
+			continue
+		}
+		if until != nil && msg.out.Timestamp.After(*until) {
+			continue
+		}
+		attempt := msg.latestAttempt(ep.out.Id)
+		if attempt == nil {
+			continue
+		}
+		switch attempt.Status {
+		case openapi.MESSAGESTATUS_Success:
+			stats.Success++
+		case openapi.MESSAGESTATUS_Pending:
+			stats.Pending++
+		case openapi.MESSAGESTATUS_Fail:
+			stats.Fail++
+		case openapi.MESSAGESTATUS_Sending:
+			stats.Sending++
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) getTransformation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		writeJSON(w, http.StatusOK, ep.transformation)
+	}
+}
+
+func (s *Server) patchTransformation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointTransformationIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ep := s.lookupEndpoint(w, params); ep != nil {
+		if in.Code.IsSet() {
+			ep.transformation.Code = in.Code
+		}
+		if in.Enabled != nil {
+			ep.transformation.Enabled = in.Enabled
+		}
+		writeEmpty(w, http.StatusNoContent)
+	}
+}
+
+// Webhook is a webhook about to be sent, as given to the handler of
+// transformation scripts, which may change its method, URL and payload.
+type Webhook struct {
+	EventType string
+	Channels  []string
+	Method    string
+	Url       string
+	Payload   map[string]interface{}
+}
+
+// TransformFunc runs the code of a transformation on webhook. An error is
+// reported like a failing script.
+type TransformFunc func(code string, webhook *Webhook) error
+
+func (s *Server) simulateTransformation(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EndpointTransformationSimulateIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if in.EventType == "" {
+		writeValidationError(w, "eventType", "field required")
+		return
+	}
+	if in.Payload == nil {
+		writeValidationError(w, "payload", "field required")
+		return
+	}
+	s.mu.Lock()
+	_, ep := s.lookupEndpoint(w, params)
+	var endpointUrl string
+	if ep != nil {
+		endpointUrl = ep.out.Url
+	}
+	s.mu.Unlock()
+	if ep == nil {
+		return
+	}
+
+	webhook := &Webhook{
+		EventType: in.EventType,
+		Channels:  in.Channels,
+		Method:    string(openapi.TRANSFORMATIONHTTPMETHOD_POST),
+		Url:       endpointUrl,
+		Payload:   in.Payload,
+	}
+	if s.options.Transform != nil {
+		if err := s.options.Transform(in.Code, webhook); err != nil {
+			writeError(w, http.StatusBadRequest, "transformation_failed", err.Error())
+			return
+		}
+	}
+	method := openapi.TransformationHttpMethod(webhook.Method)
+	if !method.IsValid() {
+		writeError(w, http.StatusBadRequest, "transformation_failed", "invalid method "+webhook.Method)
+		return
+	}
+	payload, err := json.Marshal(webhook.Payload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "transformation_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, openapi.EndpointTransformationSimulateOut{
+		Method:  &method,
+		Payload: string(payload),
+		Url:     webhook.Url,
+	})
+}
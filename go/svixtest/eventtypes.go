@@ -0,0 +1,264 @@
+package svixtest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+func validEventTypeName(name string) bool {
+	if name == "" || len(name) > 256 {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) lookupEventType(w http.ResponseWriter, name string) *openapi.EventTypeOut {
+	et, ok := s.eventTypes[name]
+	if !ok {
+		writeNotFound(w)
+		return nil
+	}
+	return et
+}
+
+func (s *Server) listEventTypes(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	withContent := queryBool(r, "with_content", false)
+	includeArchived := queryBool(r, "include_archived", false)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []openapi.EventTypeOut
+	for _, et := range s.eventTypes {
+		if !includeArchived && et.Archived != nil && *et.Archived {
+			continue
+		}
+		item := *et
+		if !withContent {
+			item.Schemas = nil
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	resp, ok := paginate(w, r, items, func(et openapi.EventTypeOut) string { return et.Name }, false)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) createEventType(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EventTypeIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if !validEventTypeName(in.Name) {
+		writeValidationError(w, "name", "invalid event type name")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.eventTypes[in.Name]; ok {
+		writeConflict(w, "An event_type with this name already exists")
+		return
+	}
+	now := time.Now().UTC()
+	archived := in.Archived != nil && *in.Archived
+	et := &openapi.EventTypeOut{
+		Name:        in.Name,
+		Description: in.Description,
+		Archived:    &archived,
+		FeatureFlag: in.FeatureFlag,
+		Schemas:     in.Schemas,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.eventTypes[in.Name] = et
+	writeJSON(w, http.StatusCreated, et)
+}
+
+func (s *Server) getEventType(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if et := s.lookupEventType(w, params["event_type_name"]); et != nil {
+		writeJSON(w, http.StatusOK, et)
+	}
+}
+
+// updateEventType replaces an event type, creating it if it doesn't exist.
+func (s *Server) updateEventType(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EventTypeUpdate
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	name := params["event_type_name"]
+	if !validEventTypeName(name) {
+		writeValidationError(w, "name", "invalid event type name")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	status := http.StatusOK
+	et, ok := s.eventTypes[name]
+	if !ok {
+		et = &openapi.EventTypeOut{Name: name, CreatedAt: now}
+		s.eventTypes[name] = et
+		status = http.StatusCreated
+	}
+	archived := in.Archived != nil && *in.Archived
+	et.Archived = &archived
+	et.Description = in.Description
+	et.FeatureFlag = in.FeatureFlag
+	et.Schemas = in.Schemas
+	et.UpdatedAt = now
+	writeJSON(w, status, et)
+}
+
+func (s *Server) patchEventType(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EventTypePatch
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	et := s.lookupEventType(w, params["event_type_name"])
+	if et == nil {
+		return
+	}
+	if in.Archived != nil {
+		et.Archived = in.Archived
+	}
+	if in.Description != nil {
+		et.Description = *in.Description
+	}
+	if in.FeatureFlag.IsSet() {
+		et.FeatureFlag = in.FeatureFlag
+	}
+	if in.Schemas != nil {
+		et.Schemas = in.Schemas
+	}
+	et.UpdatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, et)
+}
+
+// deleteEventType archives the event type, or removes it for good when the
+// `expunge` query parameter is set.
+func (s *Server) deleteEventType(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	et := s.lookupEventType(w, params["event_type_name"])
+	if et == nil {
+		return
+	}
+	if queryBool(r, "expunge", false) {
+		delete(s.eventTypes, et.Name)
+	} else {
+		archived := true
+		et.Archived = &archived
+		et.UpdatedAt = time.Now().UTC()
+	}
+	writeEmpty(w, http.StatusNoContent)
+}
+
+func (s *Server) generateExample(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EventTypeSchemaIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if in.Schema == nil {
+		writeValidationError(w, "schema", "field required")
+		return
+	}
+	example, ok := exampleFromSchema(in.Schema, 0).(map[string]interface{})
+	if !ok {
+		writeValidationError(w, "schema", "The schema must describe an object")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"example": example})
+}
+
+// exampleFromSchema returns a value matching schema: its first example,
+// const, enum value or default if any, or else a placeholder of its type.
+// References and combinations other than the first branch of anyOf and
+// oneOf aren't followed.
+func exampleFromSchema(schema map[string]interface{}, depth int) interface{} {
+	if examples, ok := schema["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	for _, key := range []string{"const", "default"} {
+		if v, ok := schema[key]; ok {
+			return v
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if branches, ok := schema[key].([]interface{}); ok && len(branches) > 0 {
+			if branch, ok := branches[0].(map[string]interface{}); ok {
+				return exampleFromSchema(branch, depth)
+			}
+		}
+	}
+
+	typ, _ := schema["type"].(string)
+	if types, ok := schema["type"].([]interface{}); ok && len(types) > 0 {
+		typ, _ = types[0].(string)
+	}
+	if typ == "" {
+		if _, ok := schema["properties"]; ok {
+			typ = "object"
+		}
+	}
+	switch typ {
+	case "object":
+		obj := map[string]interface{}{}
+		properties, _ := schema["properties"].(map[string]interface{})
+		if depth >= 8 {
+			return obj
+		}
+		for name, prop := range properties {
+			if prop, ok := prop.(map[string]interface{}); ok {
+				obj[name] = exampleFromSchema(prop, depth+1)
+			}
+		}
+		return obj
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil || depth >= 8 {
+			return []interface{}{}
+		}
+		return []interface{}{exampleFromSchema(items, depth+1)}
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "test@example.com"
+		case "uri", "url":
+			return "https://example.com"
+		case "uuid":
+			return "00000000-0000-4000-8000-000000000000"
+		}
+		return "string"
+	case "integer", "number":
+		if min, ok := schema["minimum"]; ok {
+			return min
+		}
+		return 0
+	case "boolean":
+		return true
+	case "null":
+		return nil
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,57 @@
+package svixtest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var idCounter uint32
+
+// newId returns a KSUID-like id such as `app_2QJzm7mMVbDNt2Tf3uTb7KqaWxs`.
+//
+// Ids are fixed width and sort (as strings) in creation order, which is what
+// the fake server relies on for iterator based pagination.
+func newId(prefix string) string {
+	var raw [20]byte
+	binary.BigEndian.PutUint64(raw[0:8], uint64(time.Now().UnixNano()))
+	binary.BigEndian.PutUint32(raw[8:12], atomic.AddUint32(&idCounter, 1))
+	if _, err := rand.Read(raw[12:]); err != nil {
+		panic(err)
+	}
+
+	n := new(big.Int).SetBytes(raw[:])
+	base := big.NewInt(62)
+	mod := new(big.Int)
+	encoded := make([]byte, 27)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		encoded[i] = base62Alphabet[mod.Int64()]
+	}
+	return prefix + "_" + string(encoded)
+}
+
+// newSecret returns a random endpoint signing secret.
+func newSecret() string {
+	return "whsec_" + base64.StdEncoding.EncodeToString(randomBytes(24))
+}
+
+// newToken returns a random opaque token with the given prefix.
+func newToken(prefix string) string {
+	token := base64.RawURLEncoding.EncodeToString(randomBytes(24))
+	return prefix + strings.NewReplacer("-", "a", "_", "b").Replace(token)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
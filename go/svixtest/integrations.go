@@ -0,0 +1,135 @@
+package svixtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type integration struct {
+	out openapi.IntegrationOut
+	key string
+}
+
+// lookupIntegration finds the application and integration named by the path
+// parameters, writing a 404 response when either doesn't exist. Callers must
+// hold s.mu.
+func (s *Server) lookupIntegration(w http.ResponseWriter, params map[string]string) (*app, *integration) {
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return nil, nil
+	}
+	for _, integ := range a.integrations {
+		if integ.out.Id == params["integ_id"] {
+			return a, integ
+		}
+	}
+	writeNotFound(w)
+	return nil, nil
+}
+
+func (s *Server) listIntegrations(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	var items []openapi.IntegrationOut
+	for _, integ := range a.integrations {
+		items = append(items, integ.out)
+	}
+	resp, ok := paginate(w, r, items, func(i openapi.IntegrationOut) string { return i.Id }, false)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) createIntegration(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.IntegrationIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if in.Name == "" {
+		writeValidationError(w, "name", "field required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	now := time.Now().UTC()
+	integ := &integration{
+		out: openapi.IntegrationOut{
+			Id:        newId("integ"),
+			Name:      in.Name,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		key: newToken("integsk_"),
+	}
+	a.integrations = append(a.integrations, integ)
+	writeJSON(w, http.StatusCreated, integ.out)
+}
+
+func (s *Server) getIntegration(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, integ := s.lookupIntegration(w, params); integ != nil {
+		writeJSON(w, http.StatusOK, integ.out)
+	}
+}
+
+func (s *Server) updateIntegration(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.IntegrationUpdate
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if in.Name == "" {
+		writeValidationError(w, "name", "field required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, integ := s.lookupIntegration(w, params); integ != nil {
+		integ.out.Name = in.Name
+		integ.out.UpdatedAt = time.Now().UTC()
+		writeJSON(w, http.StatusOK, integ.out)
+	}
+}
+
+func (s *Server) deleteIntegration(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, integ := s.lookupIntegration(w, params)
+	if integ == nil {
+		return
+	}
+	for i, existing := range a.integrations {
+		if existing == integ {
+			a.integrations = append(a.integrations[:i], a.integrations[i+1:]...)
+			break
+		}
+	}
+	writeEmpty(w, http.StatusNoContent)
+}
+
+func (s *Server) getIntegrationKey(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, integ := s.lookupIntegration(w, params); integ != nil {
+		writeJSON(w, http.StatusOK, openapi.IntegrationKeyOut{Key: integ.key})
+	}
+}
+
+func (s *Server) rotateIntegrationKey(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, integ := s.lookupIntegration(w, params); integ != nil {
+		integ.key = newToken("integsk_")
+		writeJSON(w, http.StatusOK, openapi.IntegrationKeyOut{Key: integ.key})
+	}
+}
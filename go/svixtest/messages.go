@@ -0,0 +1,304 @@
+package svixtest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type message struct {
+	out openapi.MessageOut
+	// All attempts for this message, oldest first.
+	attempts []*openapi.MessageAttemptOut
+	// The headers sent with the attempts, by attempt id.
+	sentHeaders map[string]map[string]string
+}
+
+func (m *message) latestAttempt(endpointId string) *openapi.MessageAttemptOut {
+	for i := len(m.attempts) - 1; i >= 0; i-- {
+		if m.attempts[i].EndpointId == endpointId {
+			return m.attempts[i]
+		}
+	}
+	return nil
+}
+
+func (a *app) findMessage(idOrEventId string) *message {
+	for _, msg := range a.messages {
+		if msg.out.Id == idOrEventId || (msg.out.EventId.Get() != nil && *msg.out.EventId.Get() == idOrEventId) {
+			return msg
+		}
+	}
+	return nil
+}
+
+// lookupMessage finds the application and message named by the path
+// parameters, writing a 404 response when either doesn't exist. Like the real
+// API, messages can be looked up by their `eventId`. Callers must hold s.mu.
+func (s *Server) lookupMessage(w http.ResponseWriter, params map[string]string) (*app, *message) {
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return nil, nil
+	}
+	msg := a.findMessage(params["msg_id"])
+	if msg == nil {
+		writeNotFound(w)
+		return nil, nil
+	}
+	return a, msg
+}
+
+// messageFilter holds the message level filters shared by the listing routes.
+type messageFilter struct {
+	eventTypes []string
+	channel    string
+	before     *time.Time
+	after      *time.Time
+}
+
+func parseMessageFilter(w http.ResponseWriter, r *http.Request) (*messageFilter, bool) {
+	f := &messageFilter{
+		eventTypes: r.URL.Query()["event_types"],
+		channel:    r.URL.Query().Get("channel"),
+	}
+	var ok bool
+	if f.before, ok = queryTime(w, r, "before"); !ok {
+		return nil, false
+	}
+	if f.after, ok = queryTime(w, r, "after"); !ok {
+		return nil, false
+	}
+	return f, true
+}
+
+func (f *messageFilter) match(msg *openapi.MessageOut, timestamp time.Time) bool {
+	if len(f.eventTypes) > 0 && !contains(f.eventTypes, msg.EventType) {
+		return false
+	}
+	if f.channel != "" && !contains(msg.Channels, f.channel) {
+		return false
+	}
+	if f.before != nil && !timestamp.Before(*f.before) {
+		return false
+	}
+	if f.after != nil && !timestamp.After(*f.after) {
+		return false
+	}
+	return true
+}
+
+func (s *Server) listMessages(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	filter, ok := parseMessageFilter(w, r)
+	if !ok {
+		return
+	}
+	withContent := queryBool(r, "with_content", true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.lookupApp(w, params["app_id"])
+	if a == nil {
+		return
+	}
+	var items []openapi.MessageOut
+	for _, msg := range a.messages {
+		if filter.match(&msg.out, msg.out.Timestamp) {
+			items = append(items, msg.content(withContent))
+		}
+	}
+	resp, ok := paginate(w, r, items, func(m openapi.MessageOut) string { return m.Id }, true)
+	if ok {
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (m *message) content(withContent bool) openapi.MessageOut {
+	out := m.out
+	if !withContent {
+		out.Payload = map[string]interface{}{}
+	}
+	return out
+}
+
+func (s *Server) createMessage(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.MessageIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	if in.EventType == "" {
+		writeValidationError(w, "eventType", "field required")
+		return
+	}
+	if in.Payload == nil {
+		writeValidationError(w, "payload", "field required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findApp(params["app_id"])
+	if a == nil && in.Application != nil {
+		appIn := *in.Application
+		if appIn.Uid.Get() == nil {
+			appIn.Uid = *openapi.NewNullableString(openapi.PtrString(params["app_id"]))
+		}
+		var ok bool
+		if a, ok = s.newApp(w, &appIn); !ok {
+			return
+		}
+	}
+	if a == nil {
+		writeNotFound(w)
+		return
+	}
+	if in.EventId.Get() != nil && a.findMessage(*in.EventId.Get()) != nil {
+		writeConflict(w, "A message with this eventId already exists")
+		return
+	}
+
+	msg := &message{
+		out: openapi.MessageOut{
+			Id:        newId("msg"),
+			EventType: in.EventType,
+			EventId:   in.EventId,
+			Channels:  in.Channels,
+			Payload:   in.Payload,
+			Timestamp: time.Now().UTC(),
+		},
+	}
+	a.messages = append(a.messages, msg)
+	var targets []*endpoint
+	for _, ep := range a.endpoints {
+		if ep.matches(&msg.out) {
+			targets = append(targets, ep)
+		}
+	}
+	s.dispatch(a, msg, targets, openapi.MESSAGEATTEMPTTRIGGERTYPE_Scheduled)
+	writeJSON(w, http.StatusAccepted, msg.content(queryBool(r, "with_content", true)))
+}
+
+func (s *Server) getMessage(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, msg := s.lookupMessage(w, params); msg != nil {
+		writeJSON(w, http.StatusOK, msg.out)
+	}
+}
+
+func (s *Server) expungeMessageContent(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, msg := s.lookupMessage(w, params); msg != nil {
+		msg.out.Payload = map[string]interface{}{"expired": true}
+		writeEmpty(w, http.StatusNoContent)
+	}
+}
+
+func (s *Server) sendExample(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in openapi.EventExampleIn
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	et, ok := s.eventTypes[in.EventType]
+	if !ok {
+		writeValidationError(w, "eventType", "Event type "+in.EventType+" does not exist")
+		return
+	}
+	msg := &message{
+		out: openapi.MessageOut{
+			Id:        newId("msg"),
+			EventType: et.Name,
+			Payload:   exampleFromSchemas(et.Schemas),
+			Timestamp: time.Now().UTC(),
+		},
+	}
+	a.messages = append(a.messages, msg)
+	s.dispatch(a, msg, []*endpoint{ep}, openapi.MESSAGEATTEMPTTRIGGERTYPE_Scheduled)
+	writeJSON(w, http.StatusAccepted, msg.out)
+}
+
+// exampleFromSchemas returns the first example of the latest schema version
+// that has one, or an empty object.
+func exampleFromSchemas(schemas map[string]map[string]interface{}) map[string]interface{} {
+	var versions []int
+	for version := range schemas {
+		if n, err := strconv.Atoi(version); err == nil {
+			versions = append(versions, n)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	for _, version := range versions {
+		examples, _ := schemas[strconv.Itoa(version)]["examples"].([]interface{})
+		if len(examples) == 0 {
+			continue
+		}
+		if example, ok := examples[0].(map[string]interface{}); ok {
+			return example
+		}
+	}
+	return map[string]interface{}{}
+}
+
+type recoverRequest struct {
+	Since time.Time  `json:"since"`
+	Until *time.Time `json:"until"`
+}
+
+func (req *recoverRequest) contains(t time.Time) bool {
+	return !t.Before(req.Since) && (req.Until == nil || !t.After(*req.Until))
+}
+
+// recoverEndpoint resends all messages in the window whose latest attempt to
+// the endpoint failed.
+func (s *Server) recoverEndpoint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in recoverRequest
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	for _, msg := range a.messages {
+		if !in.contains(msg.out.Timestamp) {
+			continue
+		}
+		if attempt := msg.latestAttempt(ep.out.Id); attempt != nil && attempt.Status == openapi.MESSAGESTATUS_Fail {
+			s.dispatch(a, msg, []*endpoint{ep}, openapi.MESSAGEATTEMPTTRIGGERTYPE_Manual)
+		}
+	}
+	task := s.newTask(openapi.BACKGROUNDTASKTYPE_ENDPOINT_RECOVER, map[string]interface{}{})
+	writeJSON(w, http.StatusAccepted, openapi.RecoverOut{Id: task.Id, Status: task.Status, Task: task.Task})
+}
+
+// replayMissing sends all messages in the window that were never attempted
+// to the endpoint.
+func (s *Server) replayMissing(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var in recoverRequest
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ep := s.lookupEndpoint(w, params)
+	if ep == nil {
+		return
+	}
+	for _, msg := range a.messages {
+		if in.contains(msg.out.Timestamp) && msg.latestAttempt(ep.out.Id) == nil && ep.matches(&msg.out) {
+			s.dispatch(a, msg, []*endpoint{ep}, openapi.MESSAGEATTEMPTTRIGGERTYPE_Manual)
+		}
+	}
+	task := s.newTask(openapi.BACKGROUNDTASKTYPE_ENDPOINT_REPLAY, map[string]interface{}{})
+	writeJSON(w, http.StatusAccepted, openapi.ReplayOut{Id: task.Id, Status: task.Status, Task: task.Task})
+}
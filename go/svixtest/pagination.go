@@ -0,0 +1,131 @@
+package svixtest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 250
+)
+
+type listResponse struct {
+	Data         interface{} `json:"data"`
+	Done         bool        `json:"done"`
+	Iterator     *string     `json:"iterator"`
+	PrevIterator *string     `json:"prevIterator"`
+}
+
+// paginate returns the page of items selected by the `iterator`, `limit` and
+// `order` query parameters. Items must be sorted by ascending key.
+//
+// Like the real API, the iterator is the key of the last item returned, and
+// the previous-page iterator is the key of the first one prefixed with `-`.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T, key func(T) string, descending bool) (*listResponse, bool) {
+	query := r.URL.Query()
+	limit := defaultLimit
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxLimit {
+			writeError(w, http.StatusUnprocessableEntity, "validation", "limit must be between 1 and 250")
+			return nil, false
+		}
+		limit = n
+	}
+	switch query.Get("order") {
+	case "ascending":
+		descending = false
+	case "descending":
+		descending = true
+	}
+
+	ordered := make([]T, len(items))
+	copy(ordered, items)
+	if descending {
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+	after := func(k string, iterator string) bool {
+		if descending {
+			return k < iterator
+		}
+		return k > iterator
+	}
+
+	iterator := query.Get("iterator")
+	var page []T
+	done := true
+	if strings.HasPrefix(iterator, "-") {
+		iterator = iterator[1:]
+		var before []T
+		for _, item := range ordered {
+			if !after(key(item), iterator) && key(item) != iterator {
+				before = append(before, item)
+			}
+		}
+		start := len(before) - limit
+		if start > 0 {
+			done = false
+		} else {
+			start = 0
+		}
+		page = before[start:]
+	} else {
+		var remaining []T
+		for _, item := range ordered {
+			if iterator == "" || after(key(item), iterator) {
+				remaining = append(remaining, item)
+			}
+		}
+		if len(remaining) > limit {
+			done = false
+			remaining = remaining[:limit]
+		}
+		page = remaining
+	}
+
+	ret := &listResponse{
+		Data: page,
+		Done: done,
+	}
+	if page == nil {
+		ret.Data = []T{}
+	}
+	if len(page) > 0 {
+		last := key(page[len(page)-1])
+		first := "-" + key(page[0])
+		ret.Iterator = &last
+		ret.PrevIterator = &first
+	}
+	return ret, true
+}
+
+// queryTime parses an RFC 3339 timestamp query parameter.
+func queryTime(w http.ResponseWriter, r *http.Request, name string) (*time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "validation", "invalid "+name+" timestamp")
+		return nil, false
+	}
+	return &t, true
+}
+
+func queryBool(r *http.Request, name string, defaultValue bool) bool {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
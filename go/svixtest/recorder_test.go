@@ -0,0 +1,73 @@
+package svixtest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	srv := svixtest.NewServer(nil)
+	ctx := context.Background()
+
+	rec, err := svixtest.NewRecorder(path, svixtest.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := svix.New("testsk_recorded", &svix.SvixOptions{ServerUrl: srv.URL(), HTTPClient: rec.Client()})
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := client.Endpoint.GetSecret(ctx, app.Id, ep.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "testsk_recorded") || strings.Contains(string(data), secret.Key) {
+		t.Fatalf("cassette contains credentials:\n%s", data)
+	}
+
+	rec, err = svixtest.NewRecorder(path, svixtest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = svix.New("testsk_other", &svix.SvixOptions{ServerUrl: srv.URL(), HTTPClient: rec.Client()})
+	replayed, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil || replayed.Id != app.Id {
+		t.Fatalf("unexpected replayed app %+v (%v)", replayed, err)
+	}
+	if _, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/hook"}); err != nil {
+		t.Fatal(err)
+	}
+	replayedSecret, err := client.Endpoint.GetSecret(ctx, app.Id, ep.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svix.NewWebhook(replayedSecret.Key); err != nil {
+		t.Errorf("redacted secret isn't usable: %v", err)
+	}
+	if unused := rec.Unused(); len(unused) != 0 {
+		t.Errorf("%d interactions weren't replayed", len(unused))
+	}
+
+	_, err = client.Application.Create(ctx, &svix.ApplicationIn{Name: "other"})
+	if err == nil || !strings.Contains(err.Error(), "no recorded interaction") || !strings.Contains(err.Error(), `"name":"other"`) {
+		t.Errorf("expected an unmatched request error, got %v", err)
+	}
+}
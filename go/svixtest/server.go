@@ -0,0 +1,384 @@
+// Package svixtest provides an in-memory implementation of the Svix API for
+// testing code that uses the svix package, without a live account.
+//
+//	srv := svixtest.NewServer(nil)
+//	defer srv.Close()
+//	client := srv.Client()
+//
+// The server implements the routes used by the svix wrappers (applications,
+// endpoints, event types, messages, attempts, integrations, headers, secrets
+// and authentication) with realistic ids, iterator based pagination, `uid`
+// lookups, idempotency keys and the same error bodies as the real API.
+package svixtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+type Options struct {
+	// If set, requests must be authenticated with this token, or with the
+	// key of an integration or an unexpired app portal or dashboard token
+	// for the API of its application. Otherwise any bearer token is
+	// accepted.
+	Token string
+	// Called to deliver messages to endpoints. When nil, every attempt
+	// immediately succeeds with a 200 status and an empty response.
+	Deliver DeliverFunc
+	// Runs the code of transformations for the simulate route, since the
+	// server can't run JavaScript. When nil, transformations leave webhooks
+	// unchanged.
+	Transform TransformFunc
+}
+
+// Server is a fake Svix API server backed by memory.
+type Server struct {
+	httpServer *httptest.Server
+	options    Options
+	routes     []route
+
+	mu          sync.Mutex
+	apps        []*app
+	eventTypes  map[string]*openapi.EventTypeOut
+	tasks       []*openapi.BackgroundTaskOut
+	idempotency map[string]*recordedResponse
+	// The one-time tokens of the magic links, mapped to their tokens.
+	oneTimeTokens map[string]string
+
+	deliveries sync.WaitGroup
+}
+
+type recordedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// NewServer starts a new fake server. Callers should Close it when done.
+func NewServer(options *Options) *Server {
+	s := &Server{
+		eventTypes:  make(map[string]*openapi.EventTypeOut),
+		idempotency: make(map[string]*recordedResponse),
+
+		oneTimeTokens: make(map[string]string),
+	}
+	if options != nil {
+		s.options = *options
+	}
+	s.registerRoutes()
+	s.httpServer = httptest.NewServer(s)
+	return s
+}
+
+// Close waits for in-flight deliveries and shuts the server down.
+func (s *Server) Close() {
+	s.deliveries.Wait()
+	s.httpServer.Close()
+}
+
+// URL returns the base URL of the server, suitable for SvixOptions.ServerUrl.
+func (s *Server) URL() *url.URL {
+	u, _ := url.Parse(s.httpServer.URL)
+	return u
+}
+
+// Client returns a svix client talking to the server.
+func (s *Server) Client() *svix.Svix {
+	token := s.options.Token
+	if token == "" {
+		token = "testsk_svixtest"
+	}
+	return svix.New(token, &svix.SvixOptions{
+		ServerUrl:  s.URL(),
+		HTTPClient: s.httpServer.Client(),
+	})
+}
+
+// WaitForDeliveries blocks until all pending deliveries have completed.
+func (s *Server) WaitForDeliveries() {
+	s.deliveries.Wait()
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+func (s *Server) handle(method string, pattern string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	s.routes = append(s.routes, route{
+		method:   method,
+		segments: splitPath(pattern),
+		handler:  handler,
+	})
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func (rt *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range rt.segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			params[seg[1:len(seg)-1]] = segments[i]
+		} else if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "authentication_failed", "Invalid token")
+		return
+	}
+
+	segments := splitPath(r.URL.EscapedPath())
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	methodAllowed := true
+	for _, rt := range s.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		if rt.method != r.Method {
+			methodAllowed = false
+			continue
+		}
+
+		key := r.Header.Get("idempotency-key")
+		if r.Method != http.MethodPost || key == "" {
+			rt.handler(w, r, params)
+			return
+		}
+		s.handleIdempotent(w, r, key, func(w http.ResponseWriter) {
+			rt.handler(w, r, params)
+		})
+		return
+	}
+	if !methodAllowed {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, "not_found", "Entity not found")
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		return false
+	}
+	if s.options.Token == "" || s.options.Token == token {
+		return true
+	}
+	return s.appTokenAllowed(token, r.URL.Path)
+}
+
+// appTokenAllowed reports whether token is the key of an integration, or an
+// unexpired token, of the application of path: these only give access to
+// the API of their application.
+func (s *Server) appTokenAllowed(token string, path string) bool {
+	segments := splitPath(path)
+	if len(segments) < 4 || segments[0] != "api" || segments[1] != "v1" || segments[2] != "app" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.findApp(segments[3])
+	if a == nil {
+		return false
+	}
+	for _, integ := range a.integrations {
+		if integ.key == token {
+			return true
+		}
+	}
+	for _, t := range a.tokens {
+		if t.token == token {
+			return !t.expired()
+		}
+	}
+	return false
+}
+
+// handleIdempotent replays the recorded response of a previous successful
+// request with the same idempotency key, like the real API does.
+func (s *Server) handleIdempotent(w http.ResponseWriter, r *http.Request, key string, handler func(w http.ResponseWriter)) {
+	cacheKey := r.Header.Get("Authorization") + " " + r.URL.Path + " " + key
+	s.mu.Lock()
+	recorded, ok := s.idempotency[cacheKey]
+	s.mu.Unlock()
+	if ok {
+		for k, v := range recorded.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(recorded.status)
+		w.Write(recorded.body)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec)
+	if rec.Code < 300 {
+		s.mu.Lock()
+		s.idempotency[cacheKey] = &recordedResponse{
+			status: rec.Code,
+			header: rec.Header().Clone(),
+			body:   rec.Body.Bytes(),
+		}
+		s.mu.Unlock()
+	}
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func writeEmpty(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, detail string) {
+	writeJSON(w, status, openapi.HttpErrorOut{Code: code, Detail: detail})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "not_found", "Entity not found")
+}
+
+func writeConflict(w http.ResponseWriter, detail string) {
+	writeError(w, http.StatusConflict, "conflict", detail)
+}
+
+func writeValidationError(w http.ResponseWriter, field string, msg string) {
+	writeJSON(w, http.StatusUnprocessableEntity, openapi.HTTPValidationError{
+		Detail: []openapi.ValidationError{{
+			Loc:  []string{"body", field},
+			Msg:  msg,
+			Type: "value_error",
+		}},
+	})
+}
+
+// decodeBody decodes the JSON request body into v, writing a 422 response and
+// returning false if it is malformed.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	// Keep the numbers of payloads exact, like the API does.
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, openapi.HTTPValidationError{
+			Detail: []openapi.ValidationError{{
+				Loc:  []string{"body"},
+				Msg:  err.Error(),
+				Type: "value_error.jsondecode",
+			}},
+		})
+		return false
+	}
+	return true
+}
+
+func (s *Server) registerRoutes() {
+	s.handle("GET", "/api/v1/app", s.listApplications)
+	s.handle("POST", "/api/v1/app", s.createApplication)
+	s.handle("GET", "/api/v1/app/{app_id}", s.getApplication)
+	s.handle("PUT", "/api/v1/app/{app_id}", s.updateApplication)
+	s.handle("PATCH", "/api/v1/app/{app_id}", s.patchApplication)
+	s.handle("DELETE", "/api/v1/app/{app_id}", s.deleteApplication)
+
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint", s.listEndpoints)
+	s.handle("POST", "/api/v1/app/{app_id}/endpoint", s.createEndpoint)
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint/{endpoint_id}", s.getEndpoint)
+	s.handle("PUT", "/api/v1/app/{app_id}/endpoint/{endpoint_id}", s.updateEndpoint)
+	s.handle("PATCH", "/api/v1/app/{app_id}/endpoint/{endpoint_id}", s.patchEndpoint)
+	s.handle("DELETE", "/api/v1/app/{app_id}/endpoint/{endpoint_id}", s.deleteEndpoint)
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/secret", s.getEndpointSecret)
+	s.handle("POST", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/secret/rotate", s.rotateEndpointSecret)
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/headers", s.getEndpointHeaders)
+	s.handle("PUT", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/headers", s.updateEndpointHeaders)
+	s.handle("PATCH", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/headers", s.patchEndpointHeaders)
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/stats", s.getEndpointStats)
+	s.handle("POST", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/recover", s.recoverEndpoint)
+	s.handle("POST", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/replay-missing", s.replayMissing)
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/transformation", s.getTransformation)
+	s.handle("PATCH", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/transformation", s.patchTransformation)
+	s.handle("POST", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/transformation/simulate", s.simulateTransformation)
+	s.handle("POST", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/send-example", s.sendExample)
+
+	s.handle("GET", "/api/v1/event-type", s.listEventTypes)
+	s.handle("POST", "/api/v1/event-type", s.createEventType)
+	s.handle("GET", "/api/v1/event-type/{event_type_name}", s.getEventType)
+	s.handle("POST", "/api/v1/event-type/schema/generate-example", s.generateExample)
+	s.handle("PUT", "/api/v1/event-type/{event_type_name}", s.updateEventType)
+	s.handle("PATCH", "/api/v1/event-type/{event_type_name}", s.patchEventType)
+	s.handle("DELETE", "/api/v1/event-type/{event_type_name}", s.deleteEventType)
+
+	s.handle("GET", "/api/v1/app/{app_id}/msg", s.listMessages)
+	s.handle("POST", "/api/v1/app/{app_id}/msg", s.createMessage)
+	s.handle("GET", "/api/v1/app/{app_id}/msg/{msg_id}", s.getMessage)
+	s.handle("DELETE", "/api/v1/app/{app_id}/msg/{msg_id}/content", s.expungeMessageContent)
+
+	s.handle("GET", "/api/v1/app/{app_id}/attempt/endpoint/{endpoint_id}", s.listAttemptsByEndpoint)
+	s.handle("GET", "/api/v1/app/{app_id}/attempt/msg/{msg_id}", s.listAttemptsByMsg)
+	s.handle("GET", "/api/v1/app/{app_id}/msg/{msg_id}/attempt/{attempt_id}", s.getAttempt)
+	s.handle("GET", "/api/v1/app/{app_id}/msg/{msg_id}/attempt/{attempt_id}/headers", s.getAttemptHeaders)
+	s.handle("DELETE", "/api/v1/app/{app_id}/msg/{msg_id}/attempt/{attempt_id}/content", s.expungeAttemptContent)
+	s.handle("POST", "/api/v1/app/{app_id}/msg/{msg_id}/endpoint/{endpoint_id}/resend", s.resendAttempt)
+	s.handle("GET", "/api/v1/app/{app_id}/endpoint/{endpoint_id}/msg", s.listAttemptedMessages)
+	s.handle("GET", "/api/v1/app/{app_id}/msg/{msg_id}/endpoint", s.listAttemptedDestinations)
+	s.handle("GET", "/api/v1/app/{app_id}/msg/{msg_id}/endpoint/{endpoint_id}/attempt", s.listAttemptsForEndpoint)
+
+	s.handle("GET", "/api/v1/app/{app_id}/integration", s.listIntegrations)
+	s.handle("POST", "/api/v1/app/{app_id}/integration", s.createIntegration)
+	s.handle("GET", "/api/v1/app/{app_id}/integration/{integ_id}", s.getIntegration)
+	s.handle("PUT", "/api/v1/app/{app_id}/integration/{integ_id}", s.updateIntegration)
+	s.handle("DELETE", "/api/v1/app/{app_id}/integration/{integ_id}", s.deleteIntegration)
+	s.handle("GET", "/api/v1/app/{app_id}/integration/{integ_id}/key", s.getIntegrationKey)
+	s.handle("POST", "/api/v1/app/{app_id}/integration/{integ_id}/key/rotate", s.rotateIntegrationKey)
+
+	s.handle("POST", "/api/v1/auth/app-portal-access/{app_id}", s.appPortalAccess)
+	s.handle("POST", "/api/v1/auth/dashboard-access/{app_id}", s.dashboardAccess)
+	s.handle("POST", "/api/v1/auth/one-time-token", s.exchangeOneTimeToken)
+	s.handle("POST", "/api/v1/auth/app/{app_id}/expire-all", s.expireAllTokens)
+	s.handle("POST", "/api/v1/auth/logout", s.logout)
+
+	s.handle("GET", "/api/v1/background-task", s.listBackgroundTasks)
+	s.handle("GET", "/api/v1/background-task/{task_id}", s.getBackgroundTask)
+
+	s.handle("GET", "/api/v1/stats/app/{app_id}/attempt", s.appAttemptStatistics)
+	s.handle("GET", "/api/v1/stats/app/{app_id}/ep/{endpoint_id}/attempt", s.endpointAttemptStatistics)
+}
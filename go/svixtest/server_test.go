@@ -0,0 +1,154 @@
+package svixtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+func TestApplicationsPaginationAndUid(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	for _, uid := range []string{"a", "b", "c"} {
+		if _, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app " + uid, Uid: *svix.NullableString(svix.String(uid))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := client.Application.List(ctx, &svix.ApplicationListOptions{Limit: svix.Int32(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 2 || page.Done {
+		t.Fatalf("expected a first page of 2, got %d (done=%v)", len(page.Data), page.Done)
+	}
+	page, err = client.Application.List(ctx, &svix.ApplicationListOptions{Limit: svix.Int32(2), Iterator: page.Iterator.Get()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 1 || !page.Done || page.Data[0].Name != "app c" {
+		t.Fatalf("unexpected second page: %+v", page.Data)
+	}
+
+	app, err := client.Application.Get(ctx, "b")
+	if err != nil || app.Name != "app b" {
+		t.Fatalf("uid lookup failed: %v", err)
+	}
+
+	_, err = client.Application.Create(ctx, &svix.ApplicationIn{Name: "dup", Uid: *svix.NullableString(svix.String("a"))})
+	svixErr, ok := err.(*svix.Error)
+	if !ok || svixErr.Status() != http.StatusConflict {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(svixErr.Body(), &body); err != nil || body["code"] != "conflict" {
+		t.Errorf("unexpected error body %s", svixErr.Body())
+	}
+
+	existing, err := client.Application.GetOrCreate(ctx, &svix.ApplicationIn{Name: "ignored", Uid: *svix.NullableString(svix.String("a"))})
+	if err != nil || existing.Name != "app a" {
+		t.Errorf("GetOrCreate didn't return the existing app: %v", err)
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	opts := &svix.PostOptions{IdempotencyKey: svix.String("key")}
+	first, err := client.Application.CreateWithOptions(ctx, &svix.ApplicationIn{Name: "first"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.Application.CreateWithOptions(ctx, &svix.ApplicationIn{Name: "second"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Id != second.Id {
+		t.Errorf("idempotent request created a second application")
+	}
+}
+
+func TestMessageDelivery(t *testing.T) {
+	received := make(chan error, 1)
+	var secret string
+	var sent http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		wh, _ := svix.NewWebhook(secret)
+		sent = r.Header
+		received <- wh.Verify(payload, r.Header)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	srv := svixtest.NewServer(&svixtest.Options{Deliver: svixtest.HTTPDelivery(nil)})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+
+	app, _ := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if _, err := client.EventType.Create(ctx, &svix.EventTypeIn{Name: "user.signup", Description: "A user signed up"}); err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: receiver.URL, FilterTypes: []string{"user.signup"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec, _ := client.Endpoint.GetSecret(ctx, app.Id, ep.Id)
+	secret = sec.Key
+
+	msg, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{
+		EventType: "user.signup",
+		EventId:   *svix.NullableString(svix.String("evt_1")),
+		Payload:   map[string]interface{}{"id": "u_1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-received; err != nil {
+		t.Fatalf("delivered webhook failed verification: %v", err)
+	}
+	srv.WaitForDeliveries()
+
+	attempts, err := client.MessageAttempt.ListByMsg(ctx, app.Id, "evt_1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts.Data) != 1 || attempts.Data[0].MsgId != msg.Id || attempts.Data[0].ResponseStatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected attempts %+v", attempts.Data)
+	}
+	headers, err := client.MessageAttempt.GetHeaders(ctx, app.Id, msg.Id, attempts.Data[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"svix-id", "svix-timestamp", "svix-signature"} {
+		if headers.SentHeaders[name] != sent.Get(name) {
+			t.Errorf("expected the recorded %s header to be %q, got %q", name, sent.Get(name), headers.SentHeaders[name])
+		}
+	}
+	stats, err := client.Endpoint.GetStats(ctx, app.Id, ep.Id)
+	if err != nil || stats.Success != 1 {
+		t.Errorf("unexpected stats %+v (%v)", stats, err)
+	}
+
+	_, err = client.Message.Create(ctx, app.Id, &svix.MessageIn{
+		EventType: "user.signup",
+		EventId:   *svix.NullableString(svix.String("evt_1")),
+		Payload:   map[string]interface{}{},
+	})
+	if svixErr, ok := err.(*svix.Error); !ok || svixErr.Status() != http.StatusConflict {
+		t.Errorf("expected duplicate eventId to conflict, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package svixtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/openapi"
+)
+
+// attemptStatistics counts the successful and failed attempts of an
+// application, or of one of its endpoints when endpointId isn't empty, per
+// period. The range defaults to the last day; ranges of up to a day are split
+// in five minute periods, longer ones in days. Callers must hold s.mu.
+func attemptStatistics(w http.ResponseWriter, r *http.Request, a *app, endpointId string) {
+	start, ok := queryTime(w, r, "startDate")
+	if !ok {
+		return
+	}
+	end, ok := queryTime(w, r, "endDate")
+	if !ok {
+		return
+	}
+	if end == nil {
+		now := time.Now().UTC()
+		end = &now
+	}
+	if start == nil {
+		s := end.Add(-24 * time.Hour)
+		start = &s
+	}
+	if !start.Before(*end) {
+		writeError(w, http.StatusUnprocessableEntity, "validation", "startDate must be before endDate")
+		return
+	}
+
+	period, length := openapi.STATISTICSPERIOD_FIVE_MINUTES, 5*time.Minute
+	if end.Sub(*start) > 24*time.Hour {
+		period, length = openapi.STATISTICSPERIOD_ONE_DAY, 24*time.Hour
+	}
+	first := start.Truncate(length)
+	n := int(end.Sub(first)/length) + 1
+	data := openapi.AttemptStatisticsData{
+		SuccessCount: make([]int32, n),
+		FailureCount: make([]int32, n),
+	}
+	for _, msg := range a.messages {
+		for _, attempt := range msg.attempts {
+			if endpointId != "" && attempt.EndpointId != endpointId {
+				continue
+			}
+			if attempt.Timestamp.Before(*start) || !attempt.Timestamp.Before(*end) {
+				continue
+			}
+			i := int(attempt.Timestamp.Sub(first) / length)
+			switch attempt.Status {
+			case openapi.MESSAGESTATUS_Success:
+				data.SuccessCount[i]++
+			case openapi.MESSAGESTATUS_Fail:
+				data.FailureCount[i]++
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, openapi.AttemptStatisticsResponse{
+		Data:      data,
+		StartDate: first,
+		EndDate:   *end,
+		Period:    period,
+	})
+}
+
+func (s *Server) appAttemptStatistics(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a := s.lookupApp(w, params["app_id"]); a != nil {
+		attemptStatistics(w, r, a, "")
+	}
+}
+
+func (s *Server) endpointAttemptStatistics(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ep := s.lookupEndpoint(w, params); ep != nil {
+		attemptStatistics(w, r, a, ep.out.Id)
+	}
+}
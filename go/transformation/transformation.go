@@ -0,0 +1,313 @@
+// Package transformation tests endpoint transformations before enabling
+// them.
+//
+// A Suite runs the code of a transformation on sample webhooks with
+// Endpoint.TransformationSimulate, and compares the webhooks it produces
+// with golden files. The samples are the examples of event type schemas, or
+// recorded messages:
+//
+//	types, err := client.EventType.List(ctx, &svix.EventTypeListOptions{WithContent: &withContent})
+//	cases := transformation.FromEventTypeList(types)
+//	suite := &transformation.Suite{Client: client, AppId: appId, EndpointId: endpointId, GoldenDir: "testdata"}
+//	report, err := suite.Deploy(ctx, code, cases)
+//
+// Golden files are written by running the suite with Update set, and
+// reviewed like code. Deploy only enables the transformation when every
+// case matches its golden file.
+package transformation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+// Case is a sample webhook to run a transformation on.
+type Case struct {
+	// The name of the case, which is also the name of its golden file.
+	Name      string
+	EventType string
+	Channels  []string
+	Payload   map[string]interface{}
+}
+
+// FromEventTypes returns a case for every example of the latest schema of
+// the event types, named after the event type and the index of the
+// example. Event types must have been listed with their content.
+func FromEventTypes(eventTypes ...*svix.EventTypeOut) []*Case {
+	var cases []*Case
+	for _, et := range eventTypes {
+		schema := et.Schemas[svix.LatestSchemaVersion(et.Schemas)]
+		examples, _ := schema["examples"].([]interface{})
+		for i, example := range examples {
+			payload, ok := example.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			cases = append(cases, &Case{
+				Name:      et.Name + "." + strconv.Itoa(i+1),
+				EventType: et.Name,
+				Payload:   payload,
+			})
+		}
+	}
+	return cases
+}
+
+// FromEventTypeList is FromEventTypes for a page of EventType.List.
+func FromEventTypeList(list *svix.ListResponseEventTypeOut) []*Case {
+	eventTypes := make([]*svix.EventTypeOut, len(list.Data))
+	for i := range list.Data {
+		et := svix.EventTypeOut(list.Data[i])
+		eventTypes[i] = &et
+	}
+	return FromEventTypes(eventTypes...)
+}
+
+// FromMessages returns a case for every message, named after its event type
+// and event id, or id when it has none.
+func FromMessages(messages ...*svix.MessageOut) []*Case {
+	var cases []*Case
+	for _, msg := range messages {
+		id := msg.Id
+		if msg.EventId.Get() != nil {
+			id = *msg.EventId.Get()
+		}
+		cases = append(cases, &Case{
+			Name:      msg.EventType + "." + id,
+			EventType: msg.EventType,
+			Channels:  msg.Channels,
+			Payload:   msg.Payload,
+		})
+	}
+	return cases
+}
+
+// FromMessageList is FromMessages for a page of Message.List.
+func FromMessageList(list *svix.ListResponseMessageOut) []*Case {
+	messages := make([]*svix.MessageOut, len(list.Data))
+	for i := range list.Data {
+		msg := svix.MessageOut(list.Data[i])
+		messages[i] = &msg
+	}
+	return FromMessages(messages...)
+}
+
+// Output is a webhook produced by a transformation, as stored in golden
+// files. Payloads that are JSON are stored decoded, so that golden files
+// are readable.
+type Output struct {
+	Method  string      `json:"method"`
+	Url     string      `json:"url"`
+	Payload interface{} `json:"payload"`
+}
+
+func (o *Output) encode() ([]byte, error) {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Result is the outcome of a case.
+type Result struct {
+	Case *Case
+	// Nil when the transformation failed.
+	Output *Output
+	// The differences between the golden file and the output, empty when
+	// they match.
+	Diff string
+	// Set when the transformation failed or the golden file couldn't be
+	// read.
+	Err error
+}
+
+func (r *Result) Passed() bool {
+	return r.Err == nil && r.Diff == ""
+}
+
+type Report struct {
+	Results []*Result
+}
+
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the number of cases that didn't pass.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(&b, "FAIL %s: %v\n", result.Case.Name, result.Err)
+		case result.Diff != "":
+			fmt.Fprintf(&b, "FAIL %s: the output differs from the golden file:\n%s", result.Case.Name, result.Diff)
+		default:
+			fmt.Fprintf(&b, "ok   %s\n", result.Case.Name)
+		}
+	}
+	fmt.Fprintf(&b, "%d of %d cases passed\n", len(r.Results)-r.Failed(), len(r.Results))
+	return b.String()
+}
+
+// Suite runs transformations of an endpoint on cases.
+type Suite struct {
+	Client     *svix.Svix
+	AppId      string
+	EndpointId string
+	// The directory of the golden files, named after the cases with a
+	// `.json` extension.
+	GoldenDir string
+	// Whether the golden files are replaced with the outputs rather than
+	// compared with them.
+	Update bool
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (s *Suite) goldenPath(c *Case) string {
+	return filepath.Join(s.GoldenDir, unsafeChars.ReplaceAllString(c.Name, "_")+".json")
+}
+
+// Run simulates the transformation on every case, and compares the outputs
+// with the golden files, or writes them when Update is set. The returned
+// error is only set when the suite couldn't run; failing cases are
+// reported in the results.
+func (s *Suite) Run(ctx context.Context, code string, cases []*Case) (*Report, error) {
+	if s.Update {
+		if err := os.MkdirAll(s.GoldenDir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	report := &Report{}
+	seen := make(map[string]string)
+	for _, c := range cases {
+		path := s.goldenPath(c)
+		if other, ok := seen[path]; ok {
+			return nil, fmt.Errorf("cases %s and %s have the same golden file %s", other, c.Name, path)
+		}
+		seen[path] = c.Name
+
+		result, err := s.run(ctx, code, c, path)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+func (s *Suite) run(ctx context.Context, code string, c *Case, path string) (*Result, error) {
+	result := &Result{Case: c}
+	out, err := s.Client.Endpoint.TransformationSimulate(ctx, s.AppId, s.EndpointId, &svix.EndpointTransformationSimulateIn{
+		Channels:  c.Channels,
+		Code:      code,
+		EventType: c.EventType,
+		Payload:   c.Payload,
+	})
+	var svixErr *svix.Error
+	if errors.As(err, &svixErr) && (svixErr.Status() == 400 || svixErr.Status() == 422) {
+		// The script failed on this case.
+		result.Err = fmt.Errorf("%w: %s", err, svixErr.Body())
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	result.Output = &Output{Url: out.Url, Payload: out.Payload}
+	if out.Method != nil {
+		result.Output.Method = string(*out.Method)
+	}
+	var payload interface{}
+	dec := json.NewDecoder(strings.NewReader(out.Payload))
+	dec.UseNumber()
+	if dec.Decode(&payload) == nil && !dec.More() {
+		result.Output.Payload = payload
+	}
+	got, err := result.Output.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Update {
+		return result, os.WriteFile(path, got, 0o644)
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		result.Err = fmt.Errorf("there's no golden file %s, run the suite with Update to write it", path)
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+	want, err = normalize(want)
+	if err != nil {
+		result.Err = fmt.Errorf("invalid golden file %s: %w", path, err)
+		return result, nil
+	}
+	if !bytes.Equal(want, got) {
+		result.Diff = diffLines(string(want), string(got))
+	}
+	return result, nil
+}
+
+// normalize re-encodes a golden file, so that edits to its formatting or
+// key order don't count as differences.
+func normalize(data []byte) ([]byte, error) {
+	var o Output
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&o); err != nil {
+		return nil, err
+	}
+	return o.encode()
+}
+
+// Deploy runs the suite, and only when every case passes, saves the code as
+// the transformation of the endpoint and enables it.
+func (s *Suite) Deploy(ctx context.Context, code string, cases []*Case) (*Report, error) {
+	if s.Update {
+		return nil, errors.New("can't deploy while updating the golden files")
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("can't deploy a transformation without cases")
+	}
+	report, err := s.Run(ctx, code, cases)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Passed() {
+		return report, fmt.Errorf("%d of %d cases failed, the transformation wasn't enabled", report.Failed(), len(report.Results))
+	}
+	enabled := true
+	err = s.Client.Endpoint.TransformatioPartialUpdate(ctx, s.AppId, s.EndpointId, &svix.EndpointTransformationIn{
+		Code:    *svix.NullableString(&code),
+		Enabled: &enabled,
+	})
+	return report, err
+}
@@ -0,0 +1,153 @@
+package transformation_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+	"github.com/svix/svix-webhooks/go/transformation"
+)
+
+// transform emulates a few transformation scripts.
+func transform(code string, webhook *svixtest.Webhook) error {
+	switch code {
+	case "wrap":
+		webhook.Payload = map[string]interface{}{"type": webhook.EventType, "data": webhook.Payload}
+	case "wrap-put":
+		webhook.Payload = map[string]interface{}{"type": webhook.EventType, "data": webhook.Payload}
+		webhook.Method = "PUT"
+	case "throw":
+		return errors.New("TypeError: webhook.payload is undefined")
+	}
+	return nil
+}
+
+func TestSuite(t *testing.T) {
+	srv := svixtest.NewServer(&svixtest.Options{Transform: transform})
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/webhook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	et, err := client.EventType.Create(ctx, &svix.EventTypeIn{
+		Name:        "invoice.paid",
+		Description: "An invoice was paid",
+		Schemas: map[string]map[string]interface{}{
+			"1": {"type": "object", "examples": []interface{}{map[string]interface{}{"id": "in_old"}}},
+			"2": {"type": "object", "examples": []interface{}{
+				map[string]interface{}{"id": "in_1", "amount": 1000},
+				map[string]interface{}{"id": "in_2", "amount": 2500},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := client.Message.Create(ctx, app.Id, &svix.MessageIn{
+		EventType: "invoice.voided",
+		EventId:   *svix.NullableString(svix.String("evt/1")),
+		Payload:   map[string]interface{}{"id": "in_3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := append(transformation.FromEventTypes(et), transformation.FromMessages(msg)...)
+	withContent := true
+	types, err := client.EventType.List(ctx, &svix.EventTypeListOptions{WithContent: &withContent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := client.Message.List(ctx, app.Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := append(transformation.FromEventTypeList(types), transformation.FromMessageList(messages)...)
+	if !reflect.DeepEqual(listed, cases) {
+		t.Errorf("expected the listed cases to match, got %v", listed)
+	}
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, " ") != "invoice.paid.1 invoice.paid.2 invoice.voided.evt/1" {
+		t.Fatalf("unexpected cases %v", names)
+	}
+
+	dir := t.TempDir()
+	suite := &transformation.Suite{Client: client, AppId: app.Id, EndpointId: ep.Id, GoldenDir: dir}
+	report, err := suite.Run(ctx, "wrap", cases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() || !strings.Contains(report.String(), "there's no golden file") {
+		t.Errorf("expected missing golden files to fail:\n%s", report)
+	}
+
+	suite.Update = true
+	if _, err := suite.Run(ctx, "wrap", cases); err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(filepath.Join(dir, "invoice.paid.2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(golden), `"amount": 2500`) || !strings.Contains(string(golden), `"method": "POST"`) {
+		t.Errorf("unexpected golden file:\n%s", golden)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "invoice.voided.evt_1.json")); err != nil {
+		t.Error(err)
+	}
+	if _, err := suite.Deploy(ctx, "wrap", cases); err == nil {
+		t.Error("expected deploying while updating golden files to fail")
+	}
+	suite.Update = false
+
+	checkDisabled := func() {
+		t.Helper()
+		out, err := client.Endpoint.TransformationGet(ctx, app.Id, ep.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Enabled != nil && *out.Enabled {
+			t.Fatal("expected the transformation not to be enabled")
+		}
+	}
+	report, err = suite.Deploy(ctx, "wrap-put", cases)
+	if err == nil || report.Failed() != 3 {
+		t.Fatalf("expected every case to fail, got %v:\n%s", err, report)
+	}
+	if diff := report.Results[0].Diff; !strings.Contains(diff, `-   "method": "POST",`) || !strings.Contains(diff, `+   "method": "PUT",`) {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+	checkDisabled()
+	report, err = suite.Deploy(ctx, "throw", cases)
+	if err == nil || report.Results[0].Err == nil || !strings.Contains(report.Results[0].Err.Error(), "TypeError") {
+		t.Fatalf("expected the script to fail, got %v:\n%s", err, report)
+	}
+	checkDisabled()
+
+	report, err = suite.Deploy(ctx, "wrap", cases)
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, report)
+	}
+	out, err := client.Endpoint.TransformationGet(ctx, app.Id, ep.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Enabled == nil || !*out.Enabled || out.Code.Get() == nil || *out.Code.Get() != "wrap" {
+		t.Errorf("expected the transformation to be enabled, got %+v", out)
+	}
+}
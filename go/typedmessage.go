@@ -0,0 +1,218 @@
+package svix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TypedMessageOut is a MessageOut with a payload decoded as T.
+type TypedMessageOut[T any] struct {
+	Channels  []string  `json:"channels,omitempty"`
+	EventId   *string   `json:"eventId,omitempty"`
+	EventType string    `json:"eventType"`
+	Id        string    `json:"id"`
+	Payload   T         `json:"payload"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TypedEndpointMessageOut is an EndpointMessageOut with a payload decoded
+// as T.
+type TypedEndpointMessageOut[T any] struct {
+	Channels    []string      `json:"channels,omitempty"`
+	EventId     *string       `json:"eventId,omitempty"`
+	EventType   string        `json:"eventType"`
+	Id          string        `json:"id"`
+	NextAttempt *time.Time    `json:"nextAttempt,omitempty"`
+	Payload     T             `json:"payload"`
+	Status      MessageStatus `json:"status"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// TypedListResponseEndpointMessageOut is a ListResponseEndpointMessageOut
+// with payloads decoded as T.
+type TypedListResponseEndpointMessageOut[T any] struct {
+	Data         []TypedEndpointMessageOut[T] `json:"data"`
+	Done         bool                         `json:"done"`
+	Iterator     *string                      `json:"iterator,omitempty"`
+	PrevIterator *string                      `json:"prevIterator,omitempty"`
+}
+
+type CreateMessageOptions struct {
+	EventId                *string
+	Channels               []string
+	PayloadRetentionPeriod *int64
+	IdempotencyKey         *string
+}
+
+// CreateMessage creates a message with a payload of any type encoding to a
+// JSON object, such as a struct, without converting it to a map first.
+//
+// If eventType is empty, it is taken from the EventType method of the
+// payload, which the types generated by svix-eventgen have.
+//
+// A json.RawMessage payload is sent unchanged, like with Message.CreateRaw.
+// The payload of the returned message is decoded as T, with numbers
+// decoded exactly: as json.Number for interface{} values.
+func CreateMessage[T any](ctx context.Context, client *Svix, appId string, eventType string, payload T, options *CreateMessageOptions) (*TypedMessageOut[T], error) {
+	if eventType == "" {
+		typed, ok := interface{}(payload).(interface{ EventType() string })
+		if !ok {
+			return nil, errors.New("no event type given, and the payload has no EventType method")
+		}
+		eventType = typed.EventType()
+	}
+	if raw, ok := interface{}(payload).(json.RawMessage); ok {
+		return createRawMessage[T](ctx, client.Message, appId, eventType, raw, options)
+	}
+	fields, err := payloadFields(payload)
+	if err != nil {
+		return nil, err
+	}
+	messageIn := &MessageIn{EventType: eventType, Payload: fields}
+	var postOptions *PostOptions
+	if options != nil {
+		messageIn.Channels = options.Channels
+		if options.EventId != nil {
+			messageIn.EventId = *NullableString(options.EventId)
+		}
+		messageIn.PayloadRetentionPeriod = options.PayloadRetentionPeriod
+		postOptions = &PostOptions{IdempotencyKey: options.IdempotencyKey}
+	}
+	_, res, err := client.Message.create(ctx, appId, messageIn, postOptions)
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	var ret TypedMessageOut[T]
+	if err := decodeResponse(res, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// GetMessage gets a message, with its payload decoded as T.
+func GetMessage[T any](ctx context.Context, client *Svix, appId string, msgId string) (*TypedMessageOut[T], error) {
+	return getMessage[T](ctx, client.Message, appId, msgId)
+}
+
+func getMessage[T any](ctx context.Context, m *Message, appId string, msgId string) (*TypedMessageOut[T], error) {
+	req := m.api.MessageApi.V1MessageGet(ctx, appId, msgId)
+	_, res, err := req.Execute()
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	var ret TypedMessageOut[T]
+	if err := decodeResponse(res, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ListAttemptedMessages is MessageAttempt.ListAttemptedMessages with the
+// payloads decoded as T. Payloads are only included with
+// options.WithContent.
+func ListAttemptedMessages[T any](ctx context.Context, client *Svix, appId string, endpointId string, options *MessageAttemptListOptions) (*TypedListResponseEndpointMessageOut[T], error) {
+	return listAttemptedMessages[T](ctx, client.MessageAttempt, appId, endpointId, options)
+}
+
+func listAttemptedMessages[T any](ctx context.Context, m *MessageAttempt, appId string, endpointId string, options *MessageAttemptListOptions) (*TypedListResponseEndpointMessageOut[T], error) {
+	_, res, err := m.listAttemptedMessages(ctx, appId, endpointId, options)
+	if err != nil {
+		return nil, wrapError(err, res)
+	}
+	var ret TypedListResponseEndpointMessageOut[T]
+	if err := decodeResponse(res, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DecodeMessageOut decodes the payload of a message as T.
+//
+// Numbers in the payloads of MessageOut have been decoded as float64, so
+// integers beyond 2^53 have already lost precision: use GetMessage to
+// decode them exactly.
+func DecodeMessageOut[T any](msg *MessageOut) (*TypedMessageOut[T], error) {
+	ret := &TypedMessageOut[T]{
+		Channels:  msg.Channels,
+		EventId:   msg.EventId.Get(),
+		EventType: msg.EventType,
+		Id:        msg.Id,
+		Timestamp: msg.Timestamp,
+	}
+	if err := convertPayload(msg.Payload, &ret.Payload); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// DecodeEndpointMessageOut decodes the payload of a message as T.
+//
+// Numbers in the payloads of EndpointMessageOut have been decoded as
+// float64, so integers beyond 2^53 have already lost precision: use
+// ListAttemptedMessages to decode them exactly.
+func DecodeEndpointMessageOut[T any](msg *EndpointMessageOut) (*TypedEndpointMessageOut[T], error) {
+	ret := &TypedEndpointMessageOut[T]{
+		Channels:    msg.Channels,
+		EventId:     msg.EventId.Get(),
+		EventType:   msg.EventType,
+		Id:          msg.Id,
+		NextAttempt: msg.NextAttempt.Get(),
+		Status:      MessageStatus(msg.Status),
+		Timestamp:   msg.Timestamp,
+	}
+	if err := convertPayload(msg.Payload, &ret.Payload); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// payloadFields encodes a payload once, and keeps the encoded value of each
+// field as a json.RawMessage, which is sent as is.
+func payloadFields(payload interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding the payload: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || raw == nil {
+		return nil, fmt.Errorf("the payload must encode to a JSON object, not %.20s", data)
+	}
+	fields := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+func convertPayload(payload map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(data, v)
+}
+
+// decodeResponse decodes the body of a response again, which the API
+// client keeps once it has decoded it.
+func decodeResponse(res *http.Response, v interface{}) error {
+	dec := json.NewDecoder(res.Body)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decoding the response: %w", err)
+	}
+	return nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decoding the payload: %w", err)
+	}
+	return nil
+}
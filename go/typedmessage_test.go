@@ -0,0 +1,87 @@
+package svix_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/svixtest"
+)
+
+type invoicePaid struct {
+	Id     string      `json:"id"`
+	Amount uint64      `json:"amount"`
+	Extra  interface{} `json:"extra,omitempty"`
+}
+
+func (invoicePaid) EventType() string {
+	return "invoice.paid"
+}
+
+func TestTypedMessages(t *testing.T) {
+	srv := svixtest.NewServer(nil)
+	defer srv.Close()
+	client := srv.Client()
+	ctx := context.Background()
+	app, err := client.Application.Create(ctx, &svix.ApplicationIn{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := client.Endpoint.Create(ctx, app.Id, &svix.EndpointIn{Url: "https://example.com/webhook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := invoicePaid{Id: "in_1", Amount: 18446744073709551615, Extra: map[string]interface{}{"big": json.Number("9007199254740993")}}
+	msg, err := svix.CreateMessage(ctx, client, app.Id, "", payload, &svix.CreateMessageOptions{EventId: svix.String("evt_1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.EventType != "invoice.paid" || msg.EventId == nil || *msg.EventId != "evt_1" || msg.Payload.Amount != payload.Amount {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	if big := msg.Payload.Extra.(map[string]interface{})["big"]; big != json.Number("9007199254740993") {
+		t.Errorf("expected the number to be exact, got %#v", big)
+	}
+
+	got, err := svix.GetMessage[invoicePaid](ctx, client, app.Id, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != msg.Id || got.Payload.Amount != payload.Amount {
+		t.Errorf("unexpected message: %+v", got)
+	}
+
+	srv.WaitForDeliveries()
+	withContent := true
+	list, err := svix.ListAttemptedMessages[invoicePaid](ctx, client, app.Id, ep.Id, &svix.MessageAttemptListOptions{WithContent: &withContent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || list.Data[0].Payload.Amount != payload.Amount {
+		t.Errorf("unexpected messages: %+v", list)
+	}
+
+	// The untyped values can be decoded too, within the precision of float64.
+	untyped, err := client.Message.Get(ctx, app.Id, msg.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := svix.DecodeMessageOut[struct {
+		Id string `json:"id"`
+	}](untyped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Payload.Id != "in_1" {
+		t.Errorf("unexpected payload: %+v", decoded.Payload)
+	}
+
+	if _, err := svix.CreateMessage(ctx, client, app.Id, "", map[string]interface{}{}, nil); err == nil {
+		t.Error("expected a payload without an event type to be refused")
+	}
+	if _, err := svix.CreateMessage(ctx, client, app.Id, "list", []int{1}, nil); err == nil {
+		t.Error("expected a payload that isn't an object to be refused")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/svix/svix-webhooks/go/internal/signature"
 )
 
 var base64enc = base64.StdEncoding
@@ -97,20 +99,13 @@ func (wh *Webhook) verify(payload []byte, headers http.Header, enforceTolerance
 	}
 	expectedSignature := []byte(strings.Split(computedSignature, ",")[1])
 
-	passedSignatures := strings.Split(msgSignature, " ")
-	for _, versionedSignature := range passedSignatures {
-		sigParts := strings.Split(versionedSignature, ",")
-		if len(sigParts) < 2 {
-			continue
-		}
-		version := sigParts[0]
-		signature := []byte(sigParts[1])
-
-		if version != "v1" {
+	passedSignatures, _ := signature.Parse(msgSignature)
+	for _, sig := range passedSignatures {
+		if sig.Version != "v1" {
 			continue
 		}
 
-		if hmac.Equal(signature, expectedSignature) {
+		if hmac.Equal([]byte(sig.Value), expectedSignature) {
 			return nil
 		}
 	}